@@ -0,0 +1,70 @@
+package nano64
+
+import (
+	"strconv"
+	"time"
+)
+
+// AgeUnit is the unit chosen by AgeString for a relative time.
+type AgeUnit int
+
+const (
+	// AgeNow is used for differences under one second; the count is zero.
+	AgeNow AgeUnit = iota
+	AgeSeconds
+	AgeMinutes
+	AgeHours
+	AgeDays
+	AgeMonths // 30 days
+	AgeYears  // 365 days
+)
+
+// AgeFormatter renders a relative time. count is the whole number of units
+// (always >= 0) and future is true when the ID's timestamp is after now.
+// Supply one to AgeStringWith to localize the output.
+type AgeFormatter func(count int64, unit AgeUnit, future bool) string
+
+// EnglishAge is the default AgeFormatter: "just now", "3h ago", "in 2d".
+var EnglishAge AgeFormatter = func(count int64, unit AgeUnit, future bool) string {
+	if unit == AgeNow {
+		return "just now"
+	}
+	suffix := [...]string{"", "s", "m", "h", "d", "mo", "y"}[unit]
+	s := strconv.FormatInt(count, 10) + suffix
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+// AgeString returns a short English description of how long ago the ID was
+// created relative to now, such as "3h ago" or "2d ago".
+func (n Nano64) AgeString(now time.Time) string {
+	return n.AgeStringWith(now, EnglishAge)
+}
+
+// AgeStringWith is AgeString with a custom formatter, e.g. for translations.
+func (n Nano64) AgeStringWith(now time.Time, format AgeFormatter) string {
+	d := now.Sub(n.ToDate())
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Second:
+		return format(0, AgeNow, future)
+	case d < time.Minute:
+		return format(int64(d/time.Second), AgeSeconds, future)
+	case d < time.Hour:
+		return format(int64(d/time.Minute), AgeMinutes, future)
+	case d < day:
+		return format(int64(d/time.Hour), AgeHours, future)
+	case d < 30*day:
+		return format(int64(d/day), AgeDays, future)
+	case d < 365*day:
+		return format(int64(d/(30*day)), AgeMonths, future)
+	}
+	return format(int64(d/(365*day)), AgeYears, future)
+}
@@ -0,0 +1,101 @@
+package nano64
+
+import "fmt"
+
+// AlphabetEncoding encodes IDs as numbers in a caller-supplied alphabet, so
+// teams can match a legacy ID format without forking the encoders. Any base
+// from 2 to 94 works, power of two or not. It implements Encoding and can be
+// registered with RegisterEncoding once named with WithName.
+//
+// By default the encoding is variable-length like Base62: there is no
+// padding and decoding rejects leading zero digits, so every ID has exactly one
+// form. FixedWidth pads instead, which keeps the output sortable when the
+// alphabet is in ascending byte order.
+type AlphabetEncoding struct {
+	codec *alphabetCodec
+	name  string
+	width int // zero for variable-length
+}
+
+// NewEncoding returns an encoding over alphabet, whose first character is
+// the zero digit. The alphabet must have 2-94 distinct printable ASCII
+// characters other than space.
+func NewEncoding(alphabet string) (*AlphabetEncoding, error) {
+	if len(alphabet) < 2 || len(alphabet) > 94 {
+		return nil, fmt.Errorf("alphabet must have 2-94 characters, got %d", len(alphabet))
+	}
+	var seen [128]bool
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if c <= ' ' || c > '~' {
+			return nil, fmt.Errorf("alphabet character %q at position %d is not printable ASCII", c, i)
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("alphabet character %q appears more than once", c)
+		}
+		seen[c] = true
+	}
+	return &AlphabetEncoding{codec: newAlphabetCodec(alphabet), name: alphabet}, nil
+}
+
+// WithName returns a copy of the encoding reporting name from Name, for
+// RegisterEncoding. The default name is the alphabet itself.
+func (e *AlphabetEncoding) WithName(name string) *AlphabetEncoding {
+	c := *e
+	c.name = name
+	return &c
+}
+
+// FixedWidth returns a copy of the encoding that left-pads every ID with the
+// zero digit to Width characters and only decodes input of exactly that length.
+func (e *AlphabetEncoding) FixedWidth() *AlphabetEncoding {
+	c := *e
+	c.width = len(e.codec.encode(^uint64(0)))
+	return &c
+}
+
+// Name implements Encoding.
+func (e *AlphabetEncoding) Name() string {
+	return e.name
+}
+
+// Alphabet returns the encoding's alphabet.
+func (e *AlphabetEncoding) Alphabet() string {
+	return e.codec.alphabet
+}
+
+// Width returns the length of the longest encoded ID, which is the length of
+// every ID for a FixedWidth encoding.
+func (e *AlphabetEncoding) Width() int {
+	return len(e.codec.encode(^uint64(0)))
+}
+
+// Encode implements Encoding.
+func (e *AlphabetEncoding) Encode(id Nano64) string {
+	s := e.codec.encode(id.value)
+	if len(s) >= e.width {
+		return s
+	}
+	buf := make([]byte, e.width)
+	pad := e.width - len(s)
+	for i := 0; i < pad; i++ {
+		buf[i] = e.codec.alphabet[0]
+	}
+	copy(buf[pad:], s)
+	return string(buf)
+}
+
+// Decode implements Encoding.
+func (e *AlphabetEncoding) Decode(s string) (Nano64, error) {
+	switch {
+	case e.width > 0 && len(s) != e.width:
+		return Nano64{}, fmt.Errorf("invalid %s: must be %d chars, got %d", e.name, e.width, len(s))
+	case e.width == 0 && len(s) > 1 && s[0] == e.codec.alphabet[0]:
+		return Nano64{}, fmt.Errorf("invalid %s: non-canonical leading %q", e.name, s[0])
+	}
+	v, err := e.codec.decode(s)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("invalid %s: %w", e.name, err)
+	}
+	return Nano64{value: v}, nil
+}
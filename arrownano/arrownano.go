@@ -0,0 +1,199 @@
+// Package arrownano builds Apache Arrow arrays of Nano64 IDs and reads them back.
+//
+// IDs can be stored either as uint64 (the canonical unsigned value) or as
+// fixed_size_binary(8) holding the big-endian bytes. Both preserve time order:
+// uint64 numerically, fixed_size_binary bytewise.
+package arrownano
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"go.codycody31.dev/nano64"
+)
+
+// Layout selects the Arrow physical type used for an ID column.
+type Layout int
+
+const (
+	// Uint64 stores IDs as arrow.PrimitiveTypes.Uint64.
+	Uint64 Layout = iota
+
+	// FixedSizeBinary stores IDs as fixed_size_binary(8), big-endian.
+	FixedSizeBinary
+)
+
+// MetadataKey is the field metadata key marking a column as holding Nano64 IDs.
+const MetadataKey = "nano64"
+
+// DataType returns the Arrow data type for the given layout.
+func DataType(layout Layout) arrow.DataType {
+	if layout == FixedSizeBinary {
+		return &arrow.FixedSizeBinaryType{ByteWidth: 8}
+	}
+	return arrow.PrimitiveTypes.Uint64
+}
+
+// Field returns a schema field for an ID column, tagged with MetadataKey so
+// downstream readers can recognise it.
+func Field(name string, layout Layout, nullable bool) arrow.Field {
+	return arrow.Field{
+		Name:     name,
+		Type:     DataType(layout),
+		Nullable: nullable,
+		Metadata: arrow.NewMetadata([]string{MetadataKey}, []string{"1"}),
+	}
+}
+
+// Builder accumulates Nano64 IDs into an Arrow array.
+type Builder struct {
+	layout Layout
+	u64    *array.Uint64Builder
+	fixed  *array.FixedSizeBinaryBuilder
+}
+
+// NewBuilder creates a Builder using the given allocator and layout.
+// A nil allocator uses memory.DefaultAllocator.
+func NewBuilder(mem memory.Allocator, layout Layout) *Builder {
+	if mem == nil {
+		mem = memory.DefaultAllocator
+	}
+
+	b := &Builder{layout: layout}
+	if layout == FixedSizeBinary {
+		b.fixed = array.NewFixedSizeBinaryBuilder(mem, &arrow.FixedSizeBinaryType{ByteWidth: 8})
+	} else {
+		b.u64 = array.NewUint64Builder(mem)
+	}
+	return b
+}
+
+// Layout returns the layout the builder was created with.
+func (b *Builder) Layout() Layout {
+	return b.layout
+}
+
+// AppendID appends a single ID.
+func (b *Builder) AppendID(id nano64.Nano64) {
+	if b.fixed != nil {
+		b.fixed.Append(id.ToBytes())
+		return
+	}
+	b.u64.Append(id.Uint64Value())
+}
+
+// AppendIDs appends all IDs in order.
+func (b *Builder) AppendIDs(ids []nano64.Nano64) {
+	b.Reserve(len(ids))
+	for _, id := range ids {
+		b.AppendID(id)
+	}
+}
+
+// AppendNull appends a null entry.
+func (b *Builder) AppendNull() {
+	if b.fixed != nil {
+		b.fixed.AppendNull()
+		return
+	}
+	b.u64.AppendNull()
+}
+
+// AppendNullID appends the ID, or a null entry if it is not valid.
+func (b *Builder) AppendNullID(id nano64.NullNano64) {
+	if !id.Valid {
+		b.AppendNull()
+		return
+	}
+	b.AppendID(id.ID)
+}
+
+// Reserve ensures there is room for n more entries.
+func (b *Builder) Reserve(n int) {
+	if b.fixed != nil {
+		b.fixed.Reserve(n)
+		return
+	}
+	b.u64.Reserve(n)
+}
+
+// Len returns the number of entries appended so far.
+func (b *Builder) Len() int {
+	if b.fixed != nil {
+		return b.fixed.Len()
+	}
+	return b.u64.Len()
+}
+
+// NewArray returns the built array and resets the builder.
+// The caller must Release the returned array.
+func (b *Builder) NewArray() arrow.Array {
+	if b.fixed != nil {
+		return b.fixed.NewArray()
+	}
+	return b.u64.NewArray()
+}
+
+// Release releases the builder's memory.
+func (b *Builder) Release() {
+	if b.fixed != nil {
+		b.fixed.Release()
+		return
+	}
+	b.u64.Release()
+}
+
+// ToIDs reads an ID column back into a slice.
+// Accepts uint64 and fixed_size_binary(8) arrays; null entries are rejected.
+func ToIDs(arr arrow.Array) ([]nano64.Nano64, error) {
+	if arr.NullN() > 0 {
+		return nil, fmt.Errorf("array contains %d nulls, use ToNullIDs", arr.NullN())
+	}
+
+	nulls, err := ToNullIDs(arr)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]nano64.Nano64, len(nulls))
+	for i, n := range nulls {
+		ids[i] = n.ID
+	}
+	return ids, nil
+}
+
+// ToNullIDs reads an ID column back into a slice, mapping null entries to invalid NullNano64 values.
+func ToNullIDs(arr arrow.Array) ([]nano64.NullNano64, error) {
+	ids := make([]nano64.NullNano64, arr.Len())
+
+	switch a := arr.(type) {
+	case *array.Uint64:
+		for i := range ids {
+			if a.IsNull(i) {
+				continue
+			}
+			ids[i] = nano64.NullNano64{ID: nano64.FromUint64(a.Value(i)), Valid: true}
+		}
+	case *array.FixedSizeBinary:
+		if width := a.DataType().(*arrow.FixedSizeBinaryType).ByteWidth; width != 8 {
+			return nil, fmt.Errorf("fixed_size_binary width must be 8, got %d", width)
+		}
+		for i := range ids {
+			if a.IsNull(i) {
+				continue
+			}
+			id, err := nano64.FromBytes(a.Value(i))
+			if err != nil {
+				return nil, fmt.Errorf("invalid ID at index %d: %w", i, err)
+			}
+			ids[i] = nano64.NullNano64{ID: id, Valid: true}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Arrow type %s for Nano64", arr.DataType())
+	}
+
+	return ids, nil
+}
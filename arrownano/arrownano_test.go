@@ -0,0 +1,137 @@
+package arrownano
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"go.codycody31.dev/nano64"
+)
+
+func testIDs() []nano64.Nano64 {
+	return []nano64.Nano64{
+		nano64.New(0),
+		nano64.New(12345),
+		nano64.New(0x123456789ABCDEF0),
+		nano64.New(^uint64(0)),
+	}
+}
+
+func TestBuilder_Roundtrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		want   arrow.Type
+	}{
+		{"uint64", Uint64, arrow.UINT64},
+		{"fixed size binary", FixedSizeBinary, arrow.FIXED_SIZE_BINARY},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			b := NewBuilder(mem, tt.layout)
+			defer b.Release()
+
+			ids := testIDs()
+			b.AppendIDs(ids)
+			if b.Len() != len(ids) {
+				t.Errorf("Len() = %d, want %d", b.Len(), len(ids))
+			}
+
+			arr := b.NewArray()
+			defer arr.Release()
+
+			if arr.DataType().ID() != tt.want {
+				t.Errorf("DataType() = %s, want %s", arr.DataType(), tt.want)
+			}
+			if !arrow.TypeEqual(arr.DataType(), DataType(tt.layout)) {
+				t.Errorf("DataType() = %s, want %s", arr.DataType(), DataType(tt.layout))
+			}
+
+			got, err := ToIDs(arr)
+			if err != nil {
+				t.Fatalf("ToIDs() error = %v", err)
+			}
+			if len(got) != len(ids) {
+				t.Fatalf("ToIDs() returned %d IDs, want %d", len(got), len(ids))
+			}
+			for i := range ids {
+				if !got[i].Equals(ids[i]) {
+					t.Errorf("ToIDs()[%d] = %d, want %d", i, got[i].Uint64Value(), ids[i].Uint64Value())
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_Nulls(t *testing.T) {
+	for _, layout := range []Layout{Uint64, FixedSizeBinary} {
+		b := NewBuilder(nil, layout)
+
+		id := nano64.New(0x123456789ABCDEF0)
+		b.AppendNullID(nano64.NullNano64{ID: id, Valid: true})
+		b.AppendNullID(nano64.NullNano64{})
+		b.AppendNull()
+
+		arr := b.NewArray()
+		b.Release()
+
+		if arr.NullN() != 2 {
+			t.Errorf("layout %d: NullN() = %d, want 2", layout, arr.NullN())
+		}
+
+		if _, err := ToIDs(arr); err == nil {
+			t.Errorf("layout %d: ToIDs() with nulls error = nil, want error", layout)
+		}
+
+		got, err := ToNullIDs(arr)
+		if err != nil {
+			t.Fatalf("layout %d: ToNullIDs() error = %v", layout, err)
+		}
+		if !got[0].Valid || !got[0].ID.Equals(id) {
+			t.Errorf("layout %d: ToNullIDs()[0] = %+v, want valid %d", layout, got[0], id.Uint64Value())
+		}
+		if got[1].Valid || got[2].Valid {
+			t.Errorf("layout %d: ToNullIDs() null entries reported valid", layout)
+		}
+
+		arr.Release()
+	}
+}
+
+func TestToIDs_UnsupportedType(t *testing.T) {
+	b := array.NewInt64Builder(memory.DefaultAllocator)
+	defer b.Release()
+	b.Append(1)
+	arr := b.NewArray()
+	defer arr.Release()
+
+	if _, err := ToIDs(arr); err == nil {
+		t.Error("ToIDs(int64 array) error = nil, want error")
+	}
+
+	fb := array.NewFixedSizeBinaryBuilder(memory.DefaultAllocator, &arrow.FixedSizeBinaryType{ByteWidth: 4})
+	defer fb.Release()
+	fb.Append([]byte{1, 2, 3, 4})
+	farr := fb.NewArray()
+	defer farr.Release()
+
+	if _, err := ToIDs(farr); err == nil {
+		t.Error("ToIDs(fixed_size_binary(4)) error = nil, want error")
+	}
+}
+
+func TestField(t *testing.T) {
+	f := Field("id", FixedSizeBinary, true)
+	if f.Name != "id" || !f.Nullable {
+		t.Errorf("Field() = %+v", f)
+	}
+	if v, ok := f.Metadata.GetValue(MetadataKey); !ok || v != "1" {
+		t.Errorf("Field() metadata missing %q", MetadataKey)
+	}
+}
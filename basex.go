@@ -0,0 +1,182 @@
+package nano64
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Base62 and Base58 produce short, URL-safe IDs for ecosystems that expect them.
+// Both are variable-length with no padding, so unlike ToHex they do NOT sort
+// lexicographically by time: New(61) is "z" but New(62) is "10" in Base62.
+// Use ToHex, ToBase32 or ToBytes wherever sort order matters.
+
+const (
+	// Base32Length is the fixed length of the ToBase32 encoding.
+	Base32Length = 13
+
+	// Base64URLLength is the fixed length of the ToBase64URL encoding.
+	Base64URLLength = 11
+)
+
+// base64URL is unpadded URL-safe Base64 that rejects non-zero trailing bits,
+// so every ID has exactly one encoding.
+var base64URL = base64.RawURLEncoding.Strict()
+
+var (
+	// base32 is Crockford's alphabet, which omits I, L, O and U.
+	base32 = newAlphabetCodec("0123456789ABCDEFGHJKMNPQRSTVWXYZ")
+
+	base62 = newAlphabetCodec("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+
+	// base58 is the Bitcoin alphabet, which omits 0, O, I and l.
+	base58 = newAlphabetCodec("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+)
+
+// ToBase32 returns the fixed-width 13-char Crockford Base32 encoding of the ID.
+// It is zero-padded, so unlike Base62 and Base58 it sorts lexicographically by time.
+func (n Nano64) ToBase32() string {
+	enc := base32.encode(n.value)
+	if len(enc) == Base32Length {
+		return enc
+	}
+	var buf [Base32Length]byte
+	pad := copy(buf[:], "0000000000000"[:Base32Length-len(enc)])
+	copy(buf[pad:], enc)
+	return string(buf[:])
+}
+
+// FromBase32 parses an ID produced by ToBase32. The input must be exactly 13
+// uppercase Crockford characters.
+func FromBase32(s string) (Nano64, error) {
+	if len(s) != Base32Length {
+		return Nano64{}, fmt.Errorf("invalid base32: must be %d chars, got %d", Base32Length, len(s))
+	}
+	v, err := base32.decode(s)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("invalid base32: %w", err)
+	}
+	return Nano64{value: v}, nil
+}
+
+// ToBase62 returns the Base62 (0-9, A-Z, a-z) encoding of the ID, at most 11 chars.
+func (n Nano64) ToBase62() string {
+	return base62.encode(n.value)
+}
+
+// FromBase62 parses an ID produced by ToBase62. Like FromBase58 it is strict:
+// a leading '0' is rejected unless it is the whole input, so every ID has
+// exactly one accepted form.
+func FromBase62(s string) (Nano64, error) {
+	if len(s) > 1 && s[0] == base62.alphabet[0] {
+		return Nano64{}, fmt.Errorf("invalid base62: non-canonical leading %q", s[0])
+	}
+	v, err := base62.decode(s)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("invalid base62: %w", err)
+	}
+	return Nano64{value: v}, nil
+}
+
+// ToBase58 returns the Base58 (Bitcoin alphabet) encoding of the ID, at most 11 chars.
+func (n Nano64) ToBase58() string {
+	return base58.encode(n.value)
+}
+
+// FromBase58 parses an ID produced by ToBase58. Parsing is strict: every ID
+// has exactly one accepted form, so a leading '1' (Base58 zero) is rejected
+// unless it is the whole input. Customer-facing links then cannot be varied
+// to bypass caches or deduplication.
+func FromBase58(s string) (Nano64, error) {
+	if len(s) > 1 && s[0] == base58.alphabet[0] {
+		return Nano64{}, fmt.Errorf("invalid base58: non-canonical leading %q", s[0])
+	}
+	v, err := base58.decode(s)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("invalid base58: %w", err)
+	}
+	return Nano64{value: v}, nil
+}
+
+// ToBase64URL returns the 11-char unpadded URL-safe Base64 (RFC 4648 §5)
+// encoding of the ID's 8 big-endian bytes, for query parameters and cookies.
+// It is fixed-width but, because of the alphabet's ASCII order, not time-sortable.
+func (n Nano64) ToBase64URL() string {
+	var raw [8]byte
+	var buf [Base64URLLength]byte
+	binary.BigEndian.PutUint64(raw[:], n.value)
+	base64URL.Encode(buf[:], raw[:])
+	return string(buf[:])
+}
+
+// FromBase64URL parses an ID produced by ToBase64URL. Decoding is strict: the
+// input must be exactly 11 characters of the URL-safe alphabet, with no
+// padding, no '+' or '/', and no non-zero trailing bits.
+func FromBase64URL(s string) (Nano64, error) {
+	if len(s) != Base64URLLength {
+		return Nano64{}, fmt.Errorf("invalid base64url: must be %d chars, got %d", Base64URLLength, len(s))
+	}
+	if i := strings.IndexAny(s, "=+/\r\n"); i >= 0 {
+		return Nano64{}, fmt.Errorf("invalid base64url: invalid character %q at position %d", s[i], i)
+	}
+	var raw [8]byte
+	if _, err := base64URL.Decode(raw[:], []byte(s)); err != nil {
+		return Nano64{}, fmt.Errorf("invalid base64url: %w", err)
+	}
+	return Nano64{value: binary.BigEndian.Uint64(raw[:])}, nil
+}
+
+// alphabetCodec encodes uint64 values as big-endian positional numbers in an arbitrary alphabet.
+type alphabetCodec struct {
+	alphabet string
+	index    [256]int16
+}
+
+func newAlphabetCodec(alphabet string) *alphabetCodec {
+	c := &alphabetCodec{alphabet: alphabet}
+	for i := range c.index {
+		c.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c.index[alphabet[i]] = int16(i)
+	}
+	return c
+}
+
+func (c *alphabetCodec) encode(v uint64) string {
+	base := uint64(len(c.alphabet))
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = c.alphabet[v%base]
+		v /= base
+		if v == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
+
+func (c *alphabetCodec) decode(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty string")
+	}
+	base := uint64(len(c.alphabet))
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := c.index[s[i]]
+		if d < 0 {
+			return 0, fmt.Errorf("invalid character %q at position %d", s[i], i)
+		}
+		hi, lo := bits.Mul64(v, base)
+		lo, carry := bits.Add64(lo, uint64(d), 0)
+		if hi != 0 || carry != 0 {
+			return 0, fmt.Errorf("value overflows 64 bits")
+		}
+		v = lo
+	}
+	return v, nil
+}
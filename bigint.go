@@ -24,3 +24,18 @@ func (bigIntHelpers) ToBytesBE(value uint64) []byte {
 	binary.BigEndian.PutUint64(bytes, value)
 	return bytes
 }
+
+// FromBytesLE reads a uint64 from 8 little-endian bytes.
+func (bigIntHelpers) FromBytesLE(bytes []byte) (uint64, error) {
+	if len(bytes) != 8 {
+		return 0, fmt.Errorf("must be 8 bytes, got %d", len(bytes))
+	}
+	return binary.LittleEndian.Uint64(bytes), nil
+}
+
+// ToBytesLE writes a uint64 to 8 little-endian bytes.
+func (bigIntHelpers) ToBytesLE(value uint64) []byte {
+	bytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(bytes, value)
+	return bytes
+}
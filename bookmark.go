@@ -0,0 +1,149 @@
+package nano64
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Bookmark records the progress of a CDC or backfill job through an
+// ID-ordered table: a high-water mark and whether rows equal to it are still
+// to be processed. The zero value starts from the beginning.
+//
+// The text form is "after:" or "at:" followed by the canonical hex, e.g.
+// "after:199C01B6659-5861C" (resume after the ID) or "at:199C01B6659-5861C"
+// (resume at the ID).
+type Bookmark struct {
+	// ID is the high-water mark.
+	ID Nano64
+
+	// Inclusive reports whether the row with ID itself is still pending.
+	Inclusive bool
+}
+
+// BookmarkAfter returns a bookmark resuming after id, typically the last row processed.
+func BookmarkAfter(id Nano64) Bookmark {
+	return Bookmark{ID: id}
+}
+
+// BookmarkAt returns a bookmark resuming at id, included.
+func BookmarkAt(id Nano64) Bookmark {
+	return Bookmark{ID: id, Inclusive: true}
+}
+
+// Includes reports whether id is still pending under the bookmark.
+func (b Bookmark) Includes(id Nano64) bool {
+	if b.Inclusive {
+		return id.value >= b.ID.value
+	}
+	return id.value > b.ID.value
+}
+
+// Advance returns the bookmark after processing id. Bookmarks never move
+// backwards, so replaying an older ID leaves b unchanged.
+func (b Bookmark) Advance(id Nano64) Bookmark {
+	if !b.Includes(id) {
+		return b
+	}
+	return BookmarkAfter(id)
+}
+
+// operator returns the SQL comparison for the bookmark.
+func (b Bookmark) operator() string {
+	if b.Inclusive {
+		return ">="
+	}
+	return ">"
+}
+
+// keyword returns the text form prefix for the bookmark.
+func (b Bookmark) keyword() string {
+	if b.Inclusive {
+		return "at:"
+	}
+	return "after:"
+}
+
+// Where returns the SQL condition selecting pending rows, e.g.
+// b.Where("id", "$1") gives "id > $1". Bind b.ID as the placeholder's
+// argument. column and placeholder are inserted verbatim and must not come
+// from untrusted input.
+func (b Bookmark) Where(column, placeholder string) string {
+	return column + " " + b.operator() + " " + placeholder
+}
+
+// String returns the text form.
+func (b Bookmark) String() string {
+	return b.keyword() + b.ID.ToHex()
+}
+
+// ParseBookmark parses the text form produced by String.
+func ParseBookmark(text string) (Bookmark, error) {
+	var b Bookmark
+	rest, ok := strings.CutPrefix(text, "at:")
+	if ok {
+		b.Inclusive = true
+	} else if rest, ok = strings.CutPrefix(text, "after:"); !ok {
+		return Bookmark{}, fmt.Errorf("bookmark must start with \"after:\" or \"at:\", got %q", text)
+	}
+	id, err := FromHex(rest)
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("invalid bookmark: %w", err)
+	}
+	b.ID = id
+	return b, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b Bookmark) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *Bookmark) UnmarshalText(text []byte) error {
+	parsed, err := ParseBookmark(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b Bookmark) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *Bookmark) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("failed to unmarshal Bookmark: %w", err)
+	}
+	return b.UnmarshalText([]byte(text))
+}
+
+// Value implements the driver.Valuer interface, storing the text form.
+func (b Bookmark) Value() (driver.Value, error) {
+	return b.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *Bookmark) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("cannot scan type %T into Bookmark", value)
+	}
+	parsed, err := ParseBookmark(text)
+	if err != nil {
+		return fmt.Errorf("failed to scan Bookmark: %w", err)
+	}
+	*b = parsed
+	return nil
+}
@@ -0,0 +1,53 @@
+package nano64
+
+import "time"
+
+// DayBounds returns the smallest and largest IDs created during the calendar
+// day containing t, in t's location. Both bounds are inclusive, so they can be
+// used directly in BETWEEN queries. Days are computed with time.Date, so DST
+// transition days correctly span 23 or 25 hours. Use t.In(loc) to pick the
+// reporting time zone.
+func DayBounds(t time.Time) (lo, hi Nano64) {
+	y, m, d := t.Date()
+	loc := t.Location()
+	return boundsBetween(time.Date(y, m, d, 0, 0, 0, 0, loc), time.Date(y, m, d+1, 0, 0, 0, 0, loc))
+}
+
+// MonthBounds returns the inclusive ID bounds of the calendar month containing t, in t's location.
+func MonthBounds(t time.Time) (lo, hi Nano64) {
+	y, m, _ := t.Date()
+	loc := t.Location()
+	return boundsBetween(time.Date(y, m, 1, 0, 0, 0, 0, loc), time.Date(y, m+1, 1, 0, 0, 0, 0, loc))
+}
+
+// HourBounds returns the inclusive ID bounds of the clock hour containing t.
+// On a DST fall-back day the repeated hour is resolved by t's own offset.
+func HourBounds(t time.Time) (lo, hi Nano64) {
+	// Subtract the local minutes rather than using Truncate, which aligns to
+	// absolute hours and is wrong in zones with sub-hour offsets.
+	start := t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	return boundsBetween(start, start.Add(time.Hour))
+}
+
+// boundsBetween returns the inclusive ID bounds of [start, end), clamped to the
+// timestamp range.
+func boundsBetween(start, end time.Time) (lo, hi Nano64) {
+	epoch := DefaultEpoch()
+	return minIDAt(clampTimestamp(start.UnixMilli() - epoch)), maxIDAt(clampTimestamp(end.UnixMilli() - 1 - epoch))
+}
+
+// clampTimestamp limits ms to the representable timestamp range.
+func clampTimestamp(ms int64) int64 {
+	if ms < 0 {
+		return 0
+	}
+	if ms > maxTimestamp {
+		return maxTimestamp
+	}
+	return ms
+}
+
+// maxIDAt returns the largest ID with the given millisecond timestamp.
+func maxIDAt(ms int64) Nano64 {
+	return Nano64{value: uint64(ms)<<timestampShift | randomMask}
+}
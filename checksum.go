@@ -0,0 +1,53 @@
+package nano64
+
+import "fmt"
+
+// CheckedHexLength is the length of the ToHexChecked form.
+const CheckedHexLength = 18
+
+// ToHexChecked returns the canonical hex form followed by one Luhn mod 16 check
+// character, e.g. "199C01B6659-5861C0". Use it where people copy IDs by hand,
+// such as support tickets: FromHexChecked then rejects any single mistyped
+// digit and any swap of adjacent digits other than 0 and F, instead of
+// resolving to the wrong record.
+func (n Nano64) ToHexChecked() string {
+	var buf [CheckedHexLength]byte
+	dst := n.AppendHex(buf[:0])
+	dst = append(dst, hexDigitsUpper[luhn16(n.value)])
+	return string(dst)
+}
+
+// FromHexChecked parses an ID produced by ToHexChecked. The ID part accepts
+// the same forms as FromHex; the check character may be either case. The
+// error wraps ErrChecksumMismatch when the check character does not match.
+func FromHexChecked(s string) (Nano64, error) {
+	if len(s) < 2 {
+		return Nano64{}, &ParseError{Input: s, Offset: len(s), Expected: "16 hex digits and a check character"}
+	}
+	id, err := FromHex(s[:len(s)-1])
+	if err != nil {
+		return Nano64{}, err
+	}
+	check, ok := hexDigit(s[len(s)-1], true)
+	if !ok {
+		return Nano64{}, &ParseError{Input: s, Offset: len(s) - 1, Expected: "hex check character"}
+	}
+	if want := luhn16(id.value); check != want {
+		return Nano64{}, fmt.Errorf("%w: %q has check character %c, want %c", ErrChecksumMismatch, s, s[len(s)-1], hexDigitsUpper[want])
+	}
+	return id, nil
+}
+
+// luhn16 returns the Luhn mod 16 check digit of the 16 hex digits of v.
+func luhn16(v uint64) byte {
+	sum := 0
+	for i := 0; i < 16; i++ {
+		d := int(v >> (4 * i) & 0xF)
+		if i%2 == 0 {
+			d *= 2
+			d = d/16 + d%16
+		}
+		sum += d
+	}
+	return byte((16 - sum%16) % 16)
+}
@@ -0,0 +1,17 @@
+package nano64
+
+import "time"
+
+// AnchoredClock returns a Clock that reads the wall clock once and then
+// advances using Go's monotonic clock. Later NTP steps or manual clock changes
+// do not affect it, so timestamps never jump backwards mid-process; the cost is
+// that slow drift from the true wall time is never corrected. Pass it to
+// SetDefaultClock or NewEncryptedIDConfig, or create one per process at startup.
+func AnchoredClock() Clock {
+	anchor := time.Now()
+	wall := anchor.UnixNano()
+	return func() int64 {
+		// time.Since uses the monotonic reading captured in anchor.
+		return (wall + int64(time.Since(anchor))) / int64(time.Millisecond)
+	}
+}
@@ -0,0 +1,117 @@
+// Package cloudsql provides Nano64 column types for distributed SQL engines
+// such as Google Cloud Spanner and CockroachDB.
+//
+// Spanner has no unsigned integer type and its Go client only accepts a fixed
+// set of Go types, so a plain nano64.Nano64 cannot be written directly. Bytes
+// and Int64 implement the Spanner client's Encoder and Decoder interfaces
+// (EncodeSpanner/DecodeSpanner) without importing the client library.
+//
+// CockroachDB speaks the PostgreSQL wire protocol:
+//
+//   - BYTES columns work with nano64.Nano64 directly, or with Bytes. Bytewise
+//     ordering matches unsigned big-endian order, so ORDER BY is time order.
+//   - INT8 columns need Int64, because nano64.Nano64 values are sent as bytes.
+//     INT8 is signed, so IDs with the top bit set (timestamps after 2248)
+//     sort before all others. Prefer BYTES for new schemas.
+//
+// Neither engine benefits from monotonically increasing primary keys: both
+// range-partition by key, so a time-ordered key concentrates writes on one
+// range. Use a hash-sharded index (CockroachDB) or put the ID behind a
+// shard prefix (Spanner) for write-heavy tables.
+package cloudsql
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"go.codycody31.dev/nano64"
+)
+
+// Bytes stores a Nano64 as an 8-byte big-endian BYTES(8) column.
+type Bytes struct {
+	nano64.Nano64
+}
+
+// Int64 stores a Nano64 as an INT64 (Spanner) or INT8 (CockroachDB) column.
+// The unsigned value is reinterpreted as two's complement on the way in and out.
+type Int64 struct {
+	nano64.Nano64
+}
+
+// EncodeSpanner implements the Spanner client's Encoder interface.
+func (b Bytes) EncodeSpanner() (interface{}, error) {
+	return b.ToBytes(), nil
+}
+
+// DecodeSpanner implements the Spanner client's Decoder interface.
+// Accepts raw bytes or the base64 string used by the Spanner wire format.
+func (b *Bytes) DecodeSpanner(input interface{}) error {
+	switch v := input.(type) {
+	case []byte:
+		return b.setBytes(v)
+	case string:
+		raw, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return fmt.Errorf("invalid base64 for Nano64: %w", err)
+		}
+		return b.setBytes(raw)
+	default:
+		return fmt.Errorf("cannot decode Spanner type %T into Bytes", input)
+	}
+}
+
+func (b *Bytes) setBytes(raw []byte) error {
+	id, err := nano64.FromBytes(raw)
+	if err != nil {
+		return err
+	}
+	b.Nano64 = id
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (b Bytes) Value() (driver.Value, error) {
+	return b.ToBytes(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (b *Bytes) Scan(value interface{}) error {
+	return b.Nano64.Scan(value)
+}
+
+// EncodeSpanner implements the Spanner client's Encoder interface.
+func (i Int64) EncodeSpanner() (interface{}, error) {
+	return int64(i.Uint64Value()), nil
+}
+
+// DecodeSpanner implements the Spanner client's Decoder interface.
+// Accepts an int64 or the decimal string used by the Spanner wire format.
+func (i *Int64) DecodeSpanner(input interface{}) error {
+	switch v := input.(type) {
+	case int64:
+		i.Nano64 = nano64.FromUint64(uint64(v))
+		return nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid INT64 for Nano64: %w", err)
+		}
+		i.Nano64 = nano64.FromUint64(uint64(n))
+		return nil
+	default:
+		return fmt.Errorf("cannot decode Spanner type %T into Int64", input)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+// Returns the ID as a signed int64 for INT8 columns.
+func (i Int64) Value() (driver.Value, error) {
+	return int64(i.Uint64Value()), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (i *Int64) Scan(value interface{}) error {
+	return i.Nano64.Scan(value)
+}
@@ -0,0 +1,160 @@
+package cloudsql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"testing"
+
+	"go.codycody31.dev/nano64"
+)
+
+// Local copies of the Spanner client interfaces, so the contract is checked
+// without depending on cloud.google.com/go/spanner.
+type spannerEncoder interface {
+	EncodeSpanner() (interface{}, error)
+}
+
+type spannerDecoder interface {
+	DecodeSpanner(input interface{}) error
+}
+
+var (
+	_ spannerEncoder = Bytes{}
+	_ spannerDecoder = (*Bytes)(nil)
+	_ spannerEncoder = Int64{}
+	_ spannerDecoder = (*Int64)(nil)
+)
+
+func TestBytes_Spanner(t *testing.T) {
+	id := nano64.New(0x123456789ABCDEF0)
+	b := Bytes{id}
+
+	enc, err := b.EncodeSpanner()
+	if err != nil {
+		t.Fatalf("EncodeSpanner() error = %v", err)
+	}
+	raw, ok := enc.([]byte)
+	if !ok {
+		t.Fatalf("EncodeSpanner() returned %T, want []byte", enc)
+	}
+	if !bytes.Equal(raw, id.ToBytes()) {
+		t.Errorf("EncodeSpanner() = %X, want %X", raw, id.ToBytes())
+	}
+
+	tests := []struct {
+		name    string
+		input   interface{}
+		wantErr bool
+	}{
+		{"bytes", raw, false},
+		{"base64 string", base64.StdEncoding.EncodeToString(raw), false},
+		{"short bytes", []byte{1, 2, 3}, true},
+		{"invalid base64", "!!!", true},
+		{"unsupported type", int64(1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Bytes
+			err := got.DecodeSpanner(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeSpanner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equals(id) {
+				t.Errorf("DecodeSpanner() = %d, want %d", got.Uint64Value(), id.Uint64Value())
+			}
+		})
+	}
+}
+
+func TestInt64_Spanner(t *testing.T) {
+	tests := []struct {
+		name  string
+		value uint64
+	}{
+		{"zero", 0},
+		{"example", 0x123456789ABCDEF0},
+		{"top bit set", 0xFEDCBA9876543210},
+		{"max", ^uint64(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Int64{nano64.New(tt.value)}
+			enc, err := i.EncodeSpanner()
+			if err != nil {
+				t.Fatalf("EncodeSpanner() error = %v", err)
+			}
+			n, ok := enc.(int64)
+			if !ok {
+				t.Fatalf("EncodeSpanner() returned %T, want int64", enc)
+			}
+
+			var got Int64
+			if err := got.DecodeSpanner(n); err != nil {
+				t.Fatalf("DecodeSpanner(int64) error = %v", err)
+			}
+			if got.Uint64Value() != tt.value {
+				t.Errorf("DecodeSpanner(int64) = %d, want %d", got.Uint64Value(), tt.value)
+			}
+
+			var fromString Int64
+			if err := fromString.DecodeSpanner(formatInt(n)); err != nil {
+				t.Fatalf("DecodeSpanner(string) error = %v", err)
+			}
+			if fromString.Uint64Value() != tt.value {
+				t.Errorf("DecodeSpanner(string) = %d, want %d", fromString.Uint64Value(), tt.value)
+			}
+		})
+	}
+
+	var i Int64
+	if err := i.DecodeSpanner("not a number"); err == nil {
+		t.Error("DecodeSpanner(invalid string) error = nil, want error")
+	}
+	if err := i.DecodeSpanner(3.14); err == nil {
+		t.Error("DecodeSpanner(float64) error = nil, want error")
+	}
+}
+
+func TestInt64_ValueScan(t *testing.T) {
+	original := Int64{nano64.New(0xFEDCBA9876543210)}
+
+	v, err := original.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if _, ok := v.(int64); !ok {
+		t.Fatalf("Value() returned %T, want int64", v)
+	}
+
+	var scanned Int64
+	if err := scanned.Scan(v); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !scanned.Equals(original.Nano64) {
+		t.Errorf("roundtrip failed: %d != %d", scanned.Uint64Value(), original.Uint64Value())
+	}
+}
+
+func TestBytes_ValueScan(t *testing.T) {
+	original := Bytes{nano64.New(0x123456789ABCDEF0)}
+
+	v, err := original.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var scanned Bytes
+	if err := scanned.Scan(v); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !scanned.Equals(original.Nano64) {
+		t.Errorf("roundtrip failed: %d != %d", scanned.Uint64Value(), original.Uint64Value())
+	}
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
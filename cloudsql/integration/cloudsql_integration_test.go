@@ -0,0 +1,92 @@
+//go:build integration
+
+package integration
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"go.codycody31.dev/nano64"
+	"go.codycody31.dev/nano64/cloudsql"
+)
+
+// TestCockroachDB_Roundtrip runs against a live CockroachDB (or PostgreSQL) instance.
+// Run from this directory with:
+// NANO64_CRDB_DSN=postgresql://root@localhost:26257/defaultdb?sslmode=disable go test -tags integration .
+func TestCockroachDB_Roundtrip(t *testing.T) {
+	dsn := os.Getenv("NANO64_CRDB_DSN")
+	if dsn == "" {
+		t.Skip("NANO64_CRDB_DSN not set")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TEMP TABLE nano64_cloudsql_items (
+			id_bytes BYTES NOT NULL,
+			id_int INT8 NOT NULL
+		)
+	`)
+	if err != nil {
+		// CockroachDB spells it BYTES, PostgreSQL BYTEA.
+		_, err = db.Exec(`
+			CREATE TEMP TABLE nano64_cloudsql_items (
+				id_bytes BYTEA NOT NULL,
+				id_int INT8 NOT NULL
+			)
+		`)
+	}
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	ids := make([]nano64.Nano64, 0, 10)
+	for i := 0; i < 10; i++ {
+		id, err := nano64.GenerateMonotonicDefault()
+		if err != nil {
+			t.Fatalf("failed to generate ID: %v", err)
+		}
+		ids = append(ids, id)
+
+		_, err = db.Exec(
+			"INSERT INTO nano64_cloudsql_items (id_bytes, id_int) VALUES ($1, $2)",
+			cloudsql.Bytes{Nano64: id},
+			cloudsql.Int64{Nano64: id},
+		)
+		if err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	rows, err := db.Query("SELECT id_bytes, id_int FROM nano64_cloudsql_items ORDER BY id_bytes ASC")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	defer rows.Close()
+
+	i := 0
+	for rows.Next() {
+		var b cloudsql.Bytes
+		var n cloudsql.Int64
+		if err := rows.Scan(&b, &n); err != nil {
+			t.Fatalf("failed to scan row: %v", err)
+		}
+		if !b.Equals(ids[i]) || !n.Equals(ids[i]) {
+			t.Errorf("row %d: got %s / %s, want %s", i, b.ToHex(), n.ToHex(), ids[i].ToHex())
+		}
+		i++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows error: %v", err)
+	}
+	if i != len(ids) {
+		t.Errorf("expected %d rows, got %d", len(ids), i)
+	}
+}
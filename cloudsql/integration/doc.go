@@ -0,0 +1,4 @@
+// Package integration holds the cloudsql tests that need a live database.
+// It is a separate module so the PostgreSQL driver they use is not a
+// dependency of nano64 itself.
+package integration
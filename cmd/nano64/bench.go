@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// benchMode is one generation path measured by the bench command.
+type benchMode struct {
+	name string
+	// monotonic marks modes that cannot collide within one process.
+	monotonic bool
+	// newGen returns a generation function for one worker goroutine.
+	newGen func() (func() error, error)
+}
+
+func runBench(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	duration := fs.Duration("duration", 2*time.Second, "measurement time per mode")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "concurrent generating goroutines")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: nano64 bench [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Measures plain, monotonic and encrypted generation rates on this host.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *duration <= 0 || *workers <= 0 {
+		fmt.Fprintln(stderr, "nano64 bench: -duration and -workers must be positive")
+		return 2
+	}
+
+	modes := []benchMode{
+		{"plain", false, func() (func() error, error) {
+			return func() error { _, err := nano64.GenerateDefault(); return err }, nil
+		}},
+		{"monotonic", true, func() (func() error, error) {
+			return func() error { _, err := nano64.GenerateMonotonicDefault(); return err }, nil
+		}},
+		{"encrypted", false, func() (func() error, error) {
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			cfg, err := nano64.NewEncryptedIDConfig(key, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			return func() error { _, err := cfg.GenerateEncryptedNow(); return err }, nil
+		}},
+	}
+
+	fmt.Fprintf(stdout, "nano64 bench: %d workers, %v per mode, GOMAXPROCS=%d\n\n", *workers, *duration, runtime.GOMAXPROCS(0))
+	fmt.Fprintf(stdout, "%-10s %15s  %s\n", "mode", "IDs/s", "collision guidance (independent generators at this rate)")
+	for _, m := range modes {
+		rate, err := measure(m, *workers, *duration)
+		if err != nil {
+			fmt.Fprintf(stderr, "nano64 bench: %s: %v\n", m.name, err)
+			return 1
+		}
+		advice := guidance(rate)
+		if m.monotonic {
+			advice = "none within this process; across processes see plain"
+		}
+		fmt.Fprintf(stdout, "%-10s %15.0f  %s\n", m.name, rate, advice)
+	}
+	fmt.Fprintf(stdout, "\nMonotonic generation runs ahead of the clock above %.0f IDs/s per process.\n", nano64.TheoreticalMaxRate())
+	return 0
+}
+
+// measure runs m on workers goroutines for d and returns the aggregate rate in IDs per second.
+func measure(m benchMode, workers int, d time.Duration) (float64, error) {
+	var (
+		count    atomic.Int64
+		stop     atomic.Bool
+		wg       sync.WaitGroup
+		firstErr error
+		errOnce  sync.Once
+	)
+	gens := make([]func() error, workers)
+	for i := range gens {
+		gen, err := m.newGen()
+		if err != nil {
+			return 0, err
+		}
+		gens[i] = gen
+	}
+
+	start := time.Now()
+	for _, gen := range gens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int64
+			for !stop.Load() {
+				// Check the stop flag every batch to keep the hot loop cheap.
+				for range 256 {
+					if err := gen(); err != nil {
+						errOnce.Do(func() { firstErr = err })
+						stop.Store(true)
+						break
+					}
+					n++
+				}
+			}
+			count.Add(n)
+		}()
+	}
+	time.Sleep(d)
+	stop.Store(true)
+	wg.Wait()
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return float64(count.Load()) / time.Since(start).Seconds(), nil
+}
+
+// guidance describes the expected collision frequency for independent random
+// generation at rate IDs per second.
+func guidance(rate float64) string {
+	perSecond := nano64.EstimateCollisionsAtRate(rate)
+	if perSecond <= 0 {
+		return "no collisions expected"
+	}
+	every := time.Duration(float64(time.Second) / perSecond)
+	switch {
+	case every < time.Second:
+		return fmt.Sprintf("~%.0f collisions/s: use monotonic generation or a unique constraint with retries", perSecond)
+	case every < 24*time.Hour:
+		return fmt.Sprintf("one collision every ~%v: keep a unique constraint with retries", every.Round(time.Second))
+	default:
+		return fmt.Sprintf("one collision every ~%.0f days", every.Hours()/24)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// genRecord is one line of "gen -format=json" output.
+type genRecord struct {
+	ID        nano64.Nano64 `json:"id"`
+	Timestamp int64         `json:"timestamp"`
+	Time      string        `json:"time"`
+}
+
+func runGen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	count := fs.Int("n", 1, "number of IDs to print; with -follow, 0 streams until interrupted")
+	follow := fs.Bool("follow", false, "stream IDs at -rate instead of printing them at once")
+	rateFlag := fs.String("rate", "10/s", "streaming rate as count/unit, unit one of ms, s, m, h")
+	format := fs.String("format", "hex", "output format: decimal, json or an encoding ("+strings.Join(nano64.EncodingNames(), ", ")+")")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: nano64 gen [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Prints monotonic IDs, one per line. IDs from one run are strictly increasing.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	write, err := genWriter(*format)
+	if err != nil {
+		fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+		return 2
+	}
+	if *count < 0 || (*count == 0 && !*follow) {
+		fmt.Fprintln(stderr, "nano64 gen: -n must be positive (or 0 with -follow)")
+		return 2
+	}
+	var interval time.Duration
+	if *follow {
+		if interval, err = parseRate(*rateFlag); err != nil {
+			fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+			return 2
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	start := time.Now()
+	for i := 0; *count == 0 || i < *count; i++ {
+		if *follow {
+			// Schedule against the start time so slow writes don't lower the rate.
+			select {
+			case <-ctx.Done():
+				return 0
+			case <-time.After(time.Until(start.Add(time.Duration(i) * interval))):
+			}
+		}
+		id, err := nano64.GenerateMonotonicDefault()
+		if err != nil {
+			fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+			return 1
+		}
+		if err := write(out, id); err != nil {
+			fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+			return 1
+		}
+		if *follow {
+			if err := out.Flush(); err != nil {
+				fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+				return 1
+			}
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "nano64 gen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// genWriter returns the line writer for format: decimal, json or the name of
+// a registered encoding.
+func genWriter(format string) (func(w io.Writer, id nano64.Nano64) error, error) {
+	switch format {
+	case "decimal":
+		return func(w io.Writer, id nano64.Nano64) error {
+			_, err := fmt.Fprintln(w, id.Uint64Value())
+			return err
+		}, nil
+	case "json":
+		return func(w io.Writer, id nano64.Nano64) error {
+			return json.NewEncoder(w).Encode(genRecord{
+				ID:        id,
+				Timestamp: id.GetTimestamp(),
+				Time:      id.ToDate().UTC().Format(time.RFC3339Nano),
+			})
+		}, nil
+	}
+	if enc, ok := nano64.LookupEncoding(format); ok {
+		return func(w io.Writer, id nano64.Nano64) error {
+			_, err := fmt.Fprintln(w, enc.Encode(id))
+			return err
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (want decimal, json or one of %s)", format, strings.Join(nano64.EncodingNames(), ", "))
+}
+
+// parseRate parses a rate such as "100/s" or "5/ms" and returns the interval between IDs.
+func parseRate(s string) (time.Duration, error) {
+	num, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q: want count/unit, e.g. 100/s", s)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate %q: count must be a positive number", s)
+	}
+	var per time.Duration
+	switch unit {
+	case "ms":
+		per = time.Millisecond
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return 0, fmt.Errorf("invalid rate %q: unit must be ms, s, m or h", s)
+	}
+	interval := float64(per) / n
+	if interval < 1 || interval >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid rate %q: interval must be between 1ns and %v", s, time.Duration(math.MaxInt64))
+	}
+	return time.Duration(interval), nil
+}
@@ -0,0 +1,70 @@
+// Command nano64 is a command-line companion to the nano64 package.
+//
+// Usage:
+//
+//	nano64 <command> [flags]
+//
+// Commands:
+//
+//	gen           print or stream monotonic IDs
+//	parse         decode IDs in hex or any registered encoding
+//	bench         measure generation throughput on this host and print collision guidance
+//	verify-order  check that a database column sorts in ID order
+//
+// Run "nano64 <command> -h" for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// command is a subcommand. run returns the process exit code.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands lists the subcommands in the order shown by usage.
+var commands = []command{
+	{"gen", "print or stream monotonic IDs", runGen},
+	{"parse", "decode IDs in hex or any registered encoding", runParse},
+	{"bench", "measure generation throughput on this host and print collision guidance", runBench},
+	{"verify-order", "check that a database column sorts in ID order", runVerifyOrder},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches args to a subcommand and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "nano64: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: nano64 <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-13s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "nano64 <command> -h" for the flags of a command.`)
+}
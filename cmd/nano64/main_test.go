@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("run() = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "bench") {
+		t.Errorf("usage does not list bench:\n%s", stderr.String())
+	}
+	if code := run([]string{"frobnicate"}, &stdout, &stderr); code != 2 {
+		t.Errorf("run(unknown) = %d, want 2", code)
+	}
+}
+
+func TestRun_Bench(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"bench", "-duration=20ms", "-workers=2"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("bench exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	for _, mode := range []string{"plain", "monotonic", "encrypted"} {
+		if !strings.Contains(stdout.String(), mode) {
+			t.Errorf("bench output missing %s:\n%s", mode, stdout.String())
+		}
+	}
+	if code := run([]string{"bench", "-workers=0"}, &stdout, &stderr); code != 2 {
+		t.Errorf("bench -workers=0 exit = %d, want 2", code)
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	if got := guidance(0); got != "no collisions expected" {
+		t.Errorf("guidance(0) = %q", got)
+	}
+	if got := guidance(10_000_000); !strings.Contains(got, "collisions/s") {
+		t.Errorf("guidance(10M) = %q, want per-second warning", got)
+	}
+	if got := guidance(1010); !strings.Contains(got, "days") {
+		t.Errorf("guidance(1010) = %q, want days", got)
+	}
+}
+
+func TestRun_Gen(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"gen", "-n=3"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("gen exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	lines := strings.Fields(stdout.String())
+	if len(lines) != 3 {
+		t.Fatalf("gen -n=3 printed %d lines", len(lines))
+	}
+	for i := 1; i < len(lines); i++ {
+		a, _ := nano64.Parse(lines[i-1])
+		b, err := nano64.Parse(lines[i])
+		if err != nil || nano64.Compare(a, b) >= 0 {
+			t.Errorf("gen output not increasing: %s, %s", lines[i-1], lines[i])
+		}
+	}
+
+	stdout.Reset()
+	start := time.Now()
+	code := run([]string{"gen", "--follow", "--rate=200/s", "--format=json", "-n=5"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("gen --follow exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("gen --follow -n=5 at 200/s took %v, want about 20ms", elapsed)
+	}
+	dec := json.NewDecoder(&stdout)
+	for i := 0; i < 5; i++ {
+		var rec genRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("decode record %d: %v", i, err)
+		}
+		if rec.ID.GetTimestamp() != rec.Timestamp || rec.Time != rec.ID.ToDate().UTC().Format(time.RFC3339Nano) {
+			t.Errorf("record %d inconsistent: %+v", i, rec)
+		}
+	}
+
+	for _, args := range [][]string{{"gen", "-format=xml"}, {"gen", "-follow", "-rate=fast"}, {"gen", "-n=0"}} {
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("run(%v) = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"100/s", 10 * time.Millisecond},
+		{"2/ms", 500 * time.Microsecond},
+		{"60/m", time.Second},
+		{"0.5/s", 2 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := parseRate(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseRate(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"100", "0/s", "-1/s", "10/d", "x/s", "NaN/s", "Inf/s", "-Inf/s", "1e-300/s", "1e20/ms"} {
+		if _, err := parseRate(bad); err == nil {
+			t.Errorf("parseRate(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestRun_Parse(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	id := nano64.New(0x199C01B66595861C)
+	if code := run([]string{"parse", id.ToHex()}, &stdout, &stderr); code != 0 {
+		t.Fatalf("parse exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	want := "199C01B6659-5861C 1759864645209 2025-10-07T19:17:25.209Z\n"
+	if stdout.String() != want {
+		t.Errorf("parse output = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"parse", "-to=base32", "199c01b66595861c"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("parse -to exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != id.ToBase32() {
+		t.Errorf("parse -to=base32 = %q, want %q", got, id.ToBase32())
+	}
+
+	if code := run([]string{"parse", "not-an-id"}, &stdout, &stderr); code != 1 {
+		t.Errorf("parse invalid exit = %d, want 1", code)
+	}
+	if code := run([]string{"parse", "-to=rot13", id.ToHex()}, &stdout, &stderr); code != 2 {
+		t.Errorf("parse -to=rot13 exit = %d, want 2", code)
+	}
+	if code := run([]string{"parse"}, &stdout, &stderr); code != 2 {
+		t.Errorf("parse without IDs exit = %d, want 2", code)
+	}
+}
+
+func TestRun_GenEncoding(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"gen", "-n=2", "-format=base62"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("gen -format=base62 exit = %d, stderr:\n%s", code, stderr.String())
+	}
+	for _, line := range strings.Fields(stdout.String()) {
+		if _, err := nano64.FromBase62(line); err != nil {
+			t.Errorf("gen -format=base62 printed %q: %v", line, err)
+		}
+	}
+	if code := run([]string{"gen", "-format=rot13"}, &stdout, &stderr); code != 2 {
+		t.Errorf("gen -format=rot13 exit = %d, want 2", code)
+	}
+}
+
+func TestRun_VerifyOrder(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "order.db")
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE ids (id BLOB, n INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []uint64{1, 1 << 63} {
+		if _, err := db.Exec(`INSERT INTO ids VALUES (?, ?)`, nano64.New(v), int64(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"verify-order", "-driver=sqlite", "-dsn=" + dsn, "-table=ids"}, &stdout, &stderr); code != 0 {
+		t.Errorf("verify-order exit = %d, stdout:\n%s\nstderr:\n%s", code, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	if code := run([]string{"verify-order", "-driver=sqlite", "-dsn=" + dsn, "-table=ids", "-column=n"}, &stdout, &stderr); code != 1 {
+		t.Errorf("verify-order -column=n exit = %d, want 1", code)
+	}
+	if !strings.HasPrefix(stdout.String(), "FAIL ids.n") {
+		t.Errorf("verify-order -column=n output = %q", stdout.String())
+	}
+	if code := run([]string{"verify-order", "-driver=sqlite", "-dsn=" + dsn}, &stdout, &stderr); code != 2 {
+		t.Errorf("verify-order without -table exit = %d, want 2", code)
+	}
+}
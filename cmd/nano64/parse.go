@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func runParse(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	to := fs.String("to", "", "re-encode each ID with this encoding ("+strings.Join(nano64.EncodingNames(), ", ")+") instead of describing it")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: nano64 parse [flags] ID...")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Parses IDs in hex or any registered encoding and prints their hex form, timestamp and time.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var enc nano64.Encoding
+	if *to != "" {
+		var ok bool
+		if enc, ok = nano64.LookupEncoding(*to); !ok {
+			fmt.Fprintf(stderr, "nano64 parse: unknown encoding %q (want one of %s)\n", *to, strings.Join(nano64.EncodingNames(), ", "))
+			return 2
+		}
+	}
+
+	code := 0
+	for _, arg := range fs.Args() {
+		id, err := nano64.Parse(arg)
+		if err != nil {
+			fmt.Fprintf(stderr, "nano64 parse: %v\n", err)
+			code = 1
+			continue
+		}
+		if enc != nil {
+			fmt.Fprintln(stdout, enc.Encode(id))
+			continue
+		}
+		fmt.Fprintf(stdout, "%s %d %s\n", id.ToHex(), id.GetTimestamp(), id.ToDate().UTC().Format(time.RFC3339Nano))
+	}
+	return code
+}
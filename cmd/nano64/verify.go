@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	"go.codycody31.dev/nano64"
+)
+
+func runVerifyOrder(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify-order", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	driver := fs.String("driver", "pgx", "database/sql driver ("+strings.Join(sql.Drivers(), ", ")+")")
+	dsn := fs.String("dsn", "", "data source name passed to the driver")
+	table := fs.String("table", "", "table holding the IDs")
+	column := fs.String("column", "id", "column holding the IDs")
+	sample := fs.Int("sample", 1000, "rows read from each end of the ordering")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall time limit")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: nano64 verify-order -dsn DSN -table TABLE [flags]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Checks that the database's ORDER BY over the column matches Nano64's time order.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dsn == "" || *table == "" {
+		fs.Usage()
+		return 2
+	}
+
+	db, err := sql.Open(*driver, *dsn)
+	if err != nil {
+		fmt.Fprintf(stderr, "nano64 verify-order: %v\n", err)
+		return 2
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	err = nano64.VerifyStorageOrder(ctx, db, *table, *column, *sample)
+	switch {
+	case errors.Is(err, nano64.ErrOrderMismatch):
+		fmt.Fprintf(stdout, "FAIL %s.%s: %v\n", *table, *column, err)
+		return 1
+	case err != nil:
+		fmt.Fprintf(stderr, "nano64 verify-order: %v\n", err)
+		return 2
+	}
+	fmt.Fprintf(stdout, "ok %s.%s\n", *table, *column)
+	return 0
+}
@@ -0,0 +1,149 @@
+package nano64
+
+import (
+	"slices"
+	"sort"
+)
+
+// CompactSlice is an immutable, sorted, de-duplicated set of IDs packed into a
+// []uint64. It holds no pointers, so the garbage collector never scans it, and
+// costs 8 bytes per ID, against 24 or more per ID for IDs boxed in interfaces
+// or stored as map keys. Lookups are O(log n).
+type CompactSlice struct {
+	values []uint64
+}
+
+// Compact packs ids into a CompactSlice. The input is not modified.
+func Compact(ids []Nano64) CompactSlice {
+	values := make([]uint64, len(ids))
+	for i, id := range ids {
+		values[i] = id.value
+	}
+	slices.Sort(values)
+	return CompactSlice{values: slices.Clip(slices.Compact(values))}
+}
+
+// Len returns the number of IDs.
+func (s CompactSlice) Len() int {
+	return len(s.values)
+}
+
+// At returns the i-th smallest ID. It panics if i is out of range.
+func (s CompactSlice) At(i int) Nano64 {
+	return Nano64{value: s.values[i]}
+}
+
+// Index returns the position of id and whether it is present.
+func (s CompactSlice) Index(id Nano64) (int, bool) {
+	return slices.BinarySearch(s.values, id.value)
+}
+
+// Contains reports whether id is present.
+func (s CompactSlice) Contains(id Nano64) bool {
+	_, found := s.Index(id)
+	return found
+}
+
+// Ascend calls fn for every ID, oldest first, until fn returns false.
+func (s CompactSlice) Ascend(fn func(id Nano64) bool) {
+	for _, v := range s.values {
+		if !fn(Nano64{value: v}) {
+			return
+		}
+	}
+}
+
+// IDs unpacks the set into a new slice, oldest first.
+func (s CompactSlice) IDs() []Nano64 {
+	ids := make([]Nano64, len(s.values))
+	for i, v := range s.values {
+		ids[i] = Nano64{value: v}
+	}
+	return ids
+}
+
+// SizeBytes returns the approximate memory used by the packed data.
+func (s CompactSlice) SizeBytes() int {
+	return cap(s.values) * 8
+}
+
+// Compress stores the set's upper 32 bits once per time bucket, roughly
+// halving its size when many IDs share a bucket. See CompressedSlice.
+func (s CompactSlice) Compress() CompressedSlice {
+	var c CompressedSlice
+	c.lows = make([]uint32, len(s.values))
+	for i, v := range s.values {
+		high := uint32(v >> 32)
+		if len(c.highs) == 0 || c.highs[len(c.highs)-1] != high {
+			c.highs = append(c.highs, high)
+			c.starts = append(c.starts, i)
+		}
+		c.lows[i] = uint32(v)
+	}
+	c.starts = append(c.starts, len(s.values))
+	c.highs = slices.Clip(c.highs)
+	c.starts = slices.Clip(c.starts)
+	return c
+}
+
+// CompressedSlice is a CompactSlice split into time buckets. IDs are grouped
+// by their upper 32 bits, which covers 4096 ms of timestamp; each bucket
+// stores those bits once and each ID only its lower 32 bits. This is not delta
+// encoding between neighbouring IDs, which would shrink dense sets further but
+// lose random access. Dense sets cost a little over 4 bytes per ID. Lookups
+// stay O(log n) but cost two binary searches.
+type CompressedSlice struct {
+	highs  []uint32 // distinct upper 32 bits, ascending
+	starts []int    // starts[i] is the first index in lows for highs[i]; len(highs)+1 entries
+	lows   []uint32 // lower 32 bits of every ID, ascending within each bucket
+}
+
+// Len returns the number of IDs.
+func (c CompressedSlice) Len() int {
+	return len(c.lows)
+}
+
+// At returns the i-th smallest ID. It panics if i is out of range.
+func (c CompressedSlice) At(i int) Nano64 {
+	if i < 0 || i >= len(c.lows) {
+		panic("nano64: CompressedSlice index out of range")
+	}
+	b := sort.Search(len(c.highs), func(j int) bool { return c.starts[j+1] > i })
+	return Nano64{value: uint64(c.highs[b])<<32 | uint64(c.lows[i])}
+}
+
+// Contains reports whether id is present.
+func (c CompressedSlice) Contains(id Nano64) bool {
+	b, found := slices.BinarySearch(c.highs, uint32(id.value>>32))
+	if !found {
+		return false
+	}
+	_, found = slices.BinarySearch(c.lows[c.starts[b]:c.starts[b+1]], uint32(id.value))
+	return found
+}
+
+// Ascend calls fn for every ID, oldest first, until fn returns false.
+func (c CompressedSlice) Ascend(fn func(id Nano64) bool) {
+	for b, high := range c.highs {
+		for _, low := range c.lows[c.starts[b]:c.starts[b+1]] {
+			if !fn(Nano64{value: uint64(high)<<32 | uint64(low)}) {
+				return
+			}
+		}
+	}
+}
+
+// Decompress restores the CompactSlice.
+func (c CompressedSlice) Decompress() CompactSlice {
+	values := make([]uint64, 0, len(c.lows))
+	c.Ascend(func(id Nano64) bool {
+		values = append(values, id.value)
+		return true
+	})
+	return CompactSlice{values: values}
+}
+
+// SizeBytes returns the approximate memory used by the packed data.
+func (c CompressedSlice) SizeBytes() int {
+	return cap(c.lows)*4 + cap(c.highs)*4 + cap(c.starts)*8
+}
@@ -0,0 +1,101 @@
+package nano64
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ConflictClassifier reports whether err means an insert hit an existing ID.
+type ConflictClassifier func(err error) bool
+
+// conflictClassifier overrides IsUniqueViolation for RetryOnConflict when set.
+var conflictClassifier atomic.Pointer[ConflictClassifier]
+
+// SetConflictClassifier replaces the classifier RetryOnConflict uses to spot
+// unique violations, and returns a function restoring the previous one.
+// Passing nil restores IsUniqueViolation. Install one when a driver reports
+// conflicts in a way IsUniqueViolation does not recognize.
+func SetConflictClassifier(classify ConflictClassifier) (restore func()) {
+	var p *ConflictClassifier
+	if classify != nil {
+		p = &classify
+	}
+	prev := conflictClassifier.Swap(p)
+	return func() {
+		conflictClassifier.Store(prev)
+	}
+}
+
+// isConflict classifies err with the installed classifier.
+func isConflict(err error) bool {
+	if p := conflictClassifier.Load(); p != nil {
+		return (*p)(err)
+	}
+	return IsUniqueViolation(err)
+}
+
+// uniqueViolationMessages are fragments of unique-violation errors from
+// drivers that expose no structured code.
+var uniqueViolationMessages = []string{
+	"UNIQUE constraint failed",     // SQLite
+	"duplicate key value violates", // PostgreSQL
+	"Duplicate entry",              // MySQL, MariaDB
+	"Cannot insert duplicate key",  // SQL Server
+	"unique constraint",            // Oracle (ORA-00001) and others
+	"ALREADY_EXISTS",               // Spanner
+}
+
+// IsUniqueViolation reports whether err looks like a unique-constraint
+// violation from a common SQL driver. It matches any error in the chain with
+// an SQLState() method returning "23505", as pgx and lib/pq errors have, and
+// otherwise falls back to well-known driver messages.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var coded interface{ SQLState() string }
+	if errors.As(err, &coded) && coded.SQLState() == "23505" {
+		return true
+	}
+	msg := err.Error()
+	for _, m := range uniqueViolationMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryOnConflict calls fn with a fresh ID from gen, generating a new ID and
+// retrying while fn's error is a unique violation, up to attempts calls in
+// total. It packages the collision-retry loop usually written around
+// INSERTs. A nil gen uses the package default clock and RNG. Errors that are
+// not conflicts are returned immediately. Returns the ID fn accepted.
+// See SetConflictClassifier for drivers IsUniqueViolation does not recognize.
+func RetryOnConflict(fn func(Nano64) error, gen *Generator, attempts int) (Nano64, error) {
+	if attempts < 1 {
+		return Nil, fmt.Errorf("attempts must be positive, got %d", attempts)
+	}
+	if gen == nil {
+		gen = NewGenerator()
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		var id Nano64
+		id, err = gen.GenerateNow()
+		if err != nil {
+			return Nil, err
+		}
+		err = fn(id)
+		if err == nil {
+			return id, nil
+		}
+		if !isConflict(err) {
+			return Nil, err
+		}
+	}
+	return Nil, fmt.Errorf("still conflicting after %d attempts: %w", attempts, err)
+}
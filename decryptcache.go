@@ -0,0 +1,90 @@
+package nano64
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// EncryptedOption configures an EncryptedIDConfig.
+type EncryptedOption func(*EncryptedIDConfig)
+
+// WithDecryptCache keeps the IDs of the size most recently decrypted payloads
+// in an LRU cache, so services that decrypt the same tokens repeatedly
+// (pagination cursors, retries) skip AES-GCM on hits. Only payloads that
+// authenticated successfully are cached, and a hit requires the exact
+// payload bytes. A size of zero or less disables the cache.
+// Hits and misses are reported through Hooks.OnDecrypt and DecryptCacheStats.
+func WithDecryptCache(size int) EncryptedOption {
+	return func(c *EncryptedIDConfig) {
+		if size <= 0 {
+			c.cache = nil
+			return
+		}
+		c.cache = newDecryptCache(size)
+	}
+}
+
+// decryptCache is a mutex-guarded LRU from payload to decrypted ID.
+type decryptCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is most recently used; values are *decryptEntry
+	items map[[PayloadLength]byte]*list.Element
+
+	hits, misses atomic.Uint64
+}
+
+type decryptEntry struct {
+	key [PayloadLength]byte
+	id  Nano64
+}
+
+func newDecryptCache(size int) *decryptCache {
+	return &decryptCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[[PayloadLength]byte]*list.Element, size),
+	}
+}
+
+// get returns the cached ID for payload and records a hit or miss.
+func (c *decryptCache) get(key *[PayloadLength]byte) (Nano64, bool) {
+	c.mu.Lock()
+	el, ok := c.items[*key]
+	if ok {
+		c.order.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		c.misses.Add(1)
+		return Nano64{}, false
+	}
+	c.hits.Add(1)
+	return el.Value.(*decryptEntry).id, true
+}
+
+// put caches id for payload, evicting the least recently used entry when full.
+func (c *decryptCache) put(key *[PayloadLength]byte, id Nano64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[*key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*decryptEntry).key)
+	}
+	c.items[*key] = c.order.PushFront(&decryptEntry{key: *key, id: id})
+}
+
+// DecryptCacheStats returns the decrypt cache's hit and miss counts since the
+// config was created. Both are zero when the cache is disabled.
+func (c *EncryptedIDConfig) DecryptCacheStats() (hits, misses uint64) {
+	if c.cache == nil {
+		return 0, 0
+	}
+	return c.cache.hits.Load(), c.cache.misses.Load()
+}
@@ -0,0 +1,91 @@
+package nano64
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	// defaultClock overrides DefaultClock for the package-level functions when set.
+	defaultClock atomic.Pointer[Clock]
+
+	// defaultRNG overrides DefaultRNG for the package-level functions when set.
+	defaultRNG atomic.Pointer[RNG]
+
+	// defaultEpoch is the Unix ms that timestamp zero stands for; see SetDefaultEpoch.
+	defaultEpoch atomic.Int64
+)
+
+// SetDefaultClock replaces the clock used by GenerateNow, GenerateDefault,
+// GenerateMonotonicNow and GenerateMonotonicDefault, and returns a function
+// restoring the previous one. Passing nil restores DefaultClock.
+// Intended for making legacy callers deterministic in tests.
+func SetDefaultClock(clock Clock) (restore func()) {
+	var p *Clock
+	if clock != nil {
+		p = &clock
+	}
+	prev := defaultClock.Swap(p)
+	return func() {
+		defaultClock.Store(prev)
+	}
+}
+
+// SetDefaultRNG replaces the RNG used by GenerateDefault, GenerateMonotonicDefault
+// and any generation call given a nil RNG, and returns a function restoring the
+// previous one. Passing nil restores DefaultRNG.
+func SetDefaultRNG(rng RNG) (restore func()) {
+	var p *RNG
+	if rng != nil {
+		p = &rng
+	}
+	prev := defaultRNG.Swap(p)
+	return func() {
+		defaultRNG.Store(prev)
+	}
+}
+
+// SetDefaultEpoch makes the package count timestamps from epoch (Unix ms)
+// instead of the Unix epoch, and returns a function restoring the previous
+// one. Generate and GenerateMonotonic subtract it, GetTimestamp and ToDate add
+// it back, and NewGenerator uses it unless WithEpoch says otherwise, so IDs
+// from a custom-epoch scheme decode to real dates without a Generator at hand.
+// The setting changes how every existing ID decodes: call it once at startup,
+// before generating or decoding, with the same value in every service that
+// shares the IDs. Negative epochs are treated as zero.
+func SetDefaultEpoch(epoch int64) (restore func()) {
+	prev := defaultEpoch.Swap(max(epoch, 0))
+	return func() {
+		defaultEpoch.Store(prev)
+	}
+}
+
+// DefaultEpoch returns the epoch set with SetDefaultEpoch, zero by default.
+func DefaultEpoch() int64 {
+	return defaultEpoch.Load()
+}
+
+// fromUnix converts a Unix ms timestamp to an offset from the default epoch.
+func fromUnix(timestamp int64) (int64, error) {
+	epoch := DefaultEpoch()
+	if epoch > 0 && timestamp < epoch {
+		return 0, fmt.Errorf("timestamp %d is before the default epoch %d", timestamp, epoch)
+	}
+	return timestamp - epoch, nil
+}
+
+// now returns the current time from the package default clock.
+func now() int64 {
+	if p := defaultClock.Load(); p != nil {
+		return (*p)()
+	}
+	return DefaultClock()
+}
+
+// packageRNG returns the package default RNG.
+func packageRNG() RNG {
+	if p := defaultRNG.Load(); p != nil {
+		return *p
+	}
+	return DefaultRNG
+}
@@ -0,0 +1,92 @@
+package nano64
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Diff compares two ascending ID slices (see SortIDs) in a single O(n) pass and
+// returns the IDs present only in a and only in b, both ascending. Duplicates
+// are matched one-for-one, so an ID appearing twice in a and once in b is
+// reported once in onlyA. Results are meaningless for unsorted input.
+func Diff(a, b []Nano64) (onlyA, onlyB []Nano64) {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].value < b[j].value:
+			onlyA = append(onlyA, a[i])
+			i++
+		case a[i].value > b[j].value:
+			onlyB = append(onlyB, b[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	onlyA = append(onlyA, a[i:]...)
+	onlyB = append(onlyB, b[j:]...)
+	return onlyA, onlyB
+}
+
+// DiffBucket counts the differences whose timestamps fall in one time bucket.
+type DiffBucket struct {
+	// Start is the beginning of the bucket, in UTC.
+	Start time.Time
+
+	// OnlyA and OnlyB count the IDs missing from the other side.
+	OnlyA, OnlyB int
+}
+
+// DiffReport summarizes the output of Diff by time bucket, so operators can
+// see when two replicas diverged rather than scrolling through raw IDs.
+type DiffReport struct {
+	// OnlyA and OnlyB are the total counts.
+	OnlyA, OnlyB int
+
+	// Buckets lists the non-empty buckets, oldest first.
+	Buckets []DiffBucket
+}
+
+// SummarizeDiff groups onlyA and onlyB, as returned by Diff, into buckets of
+// the given width aligned to the Unix epoch. Widths under a millisecond are
+// treated as one millisecond.
+func SummarizeDiff(onlyA, onlyB []Nano64, bucket time.Duration) DiffReport {
+	step := max(bucket.Milliseconds(), 1)
+	report := DiffReport{OnlyA: len(onlyA), OnlyB: len(onlyB)}
+
+	// Both inputs are ascending, so merge them bucket by bucket.
+	i, j := 0, 0
+	for i < len(onlyA) || j < len(onlyB) {
+		var start int64
+		switch {
+		case j == len(onlyB) || (i < len(onlyA) && onlyA[i].value < onlyB[j].value):
+			start = onlyA[i].GetTimestamp()
+		default:
+			start = onlyB[j].GetTimestamp()
+		}
+		start -= start % step
+		end := start + step
+
+		b := DiffBucket{Start: time.UnixMilli(start).UTC()}
+		for ; i < len(onlyA) && onlyA[i].GetTimestamp() < end; i++ {
+			b.OnlyA++
+		}
+		for ; j < len(onlyB) && onlyB[j].GetTimestamp() < end; j++ {
+			b.OnlyB++
+		}
+		report.Buckets = append(report.Buckets, b)
+	}
+	return report
+}
+
+// String renders the report as a small table, one line per bucket.
+func (r DiffReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "only in A: %d, only in B: %d\n", r.OnlyA, r.OnlyB)
+	for _, bucket := range r.Buckets {
+		fmt.Fprintf(&b, "%s  A:%d  B:%d\n", bucket.Start.Format(time.RFC3339Nano), bucket.OnlyA, bucket.OnlyB)
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package nano64
+
+import (
+	"math"
+	"time"
+)
+
+// DistanceIDs returns the number of ID values between a and b, i.e. |b - a|,
+// in either argument order. Unlike b.Uint64Value() - a.Uint64Value(), it never
+// wraps around when b is older than a. Use Compare for the direction.
+func DistanceIDs(a, b Nano64) uint64 {
+	if b.value >= a.value {
+		return b.value - a.value
+	}
+	return a.value - b.value
+}
+
+// TimeDistance returns the time from a's timestamp to b's (b - a). The result
+// is negative when b is older than a. The 44-bit timestamp spans about 557
+// years but a Duration only about 292, so distances beyond that saturate at
+// math.MaxInt64 or math.MinInt64 instead of wrapping.
+func TimeDistance(a, b Nano64) time.Duration {
+	ms := b.GetTimestamp() - a.GetTimestamp()
+	switch {
+	case ms > math.MaxInt64/int64(time.Millisecond):
+		return math.MaxInt64
+	case ms < math.MinInt64/int64(time.Millisecond):
+		return math.MinInt64
+	}
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,116 @@
+package nano64
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrClockDrift is returned by a generator whose DriftPolicy chose DriftError.
+var ErrClockDrift = errors.New("clock drift")
+
+// Drift describes a clock jump a generator observed between two generation calls.
+type Drift struct {
+	// Last is the timestamp (Unix ms) of the previous generation call.
+	Last int64
+
+	// Now is the timestamp (Unix ms) of the current call.
+	Now int64
+
+	// Jump is Now - Last: negative when the clock moved backwards.
+	Jump time.Duration
+}
+
+// DriftAction is how a generator reacts to a drift.
+type DriftAction int
+
+const (
+	// DriftIgnore generates with the jumped timestamp. Monotonic generation
+	// still never goes backwards.
+	DriftIgnore DriftAction = iota
+
+	// DriftClamp generates with the timestamp limited to the thresholds
+	// around the last one, so the generator's timestamps slew towards the
+	// jumped clock instead of stepping.
+	DriftClamp
+
+	// DriftError fails the call with an error wrapping ErrClockDrift. The
+	// jumped timestamp becomes the new baseline, so only the call that
+	// observed the jump fails and later calls proceed.
+	DriftError
+
+	// DriftWait sleeps until the clock is back at the last timestamp after a
+	// backwards jump. Forward jumps have nothing to wait for and are ignored.
+	DriftWait
+)
+
+// DriftPolicy is called when a generator's clock jumps beyond the thresholds
+// set with WithDriftPolicy, and decides how to react. Use it to log or count
+// drifts as well. It runs synchronously on the generating goroutine, so it
+// must be fast and safe for concurrent use.
+type DriftPolicy func(Drift) DriftAction
+
+// IgnoreDrift is a DriftPolicy that always chooses DriftIgnore.
+func IgnoreDrift(Drift) DriftAction { return DriftIgnore }
+
+// ClampDrift is a DriftPolicy that always chooses DriftClamp.
+func ClampDrift(Drift) DriftAction { return DriftClamp }
+
+// ErrorDrift is a DriftPolicy that always chooses DriftError.
+func ErrorDrift(Drift) DriftAction { return DriftError }
+
+// WaitDrift is a DriftPolicy that always chooses DriftWait. The generator's
+// clock must advance in real time.
+func WaitDrift(Drift) DriftAction { return DriftWait }
+
+// noTimestamp marks a generator that has not generated anything yet.
+const noTimestamp = math.MinInt64
+
+// WithDriftPolicy makes the generator call policy whenever the timestamp of a
+// generation call is more than backward behind or forward ahead of the
+// previous call's, e.g. after an NTP step or a VM resume. A non-positive
+// threshold disables checking in that direction. Timestamps passed explicitly
+// to Generate and GenerateMonotonic are checked too.
+func WithDriftPolicy(backward, forward time.Duration, policy DriftPolicy) GeneratorOption {
+	return func(g *Generator) {
+		g.drift = policy
+		g.driftBackward = backward.Milliseconds()
+		g.driftForward = forward.Milliseconds()
+	}
+}
+
+// checkDrift applies the drift policy to timestamp and returns the timestamp
+// to generate with.
+func (g *Generator) checkDrift(timestamp int64) (int64, error) {
+	if g.drift == nil {
+		return timestamp, nil
+	}
+	last := g.lastSeen.Load()
+	if last != noTimestamp {
+		backward := g.driftBackward > 0 && timestamp < last-g.driftBackward
+		forward := g.driftForward > 0 && timestamp > last+g.driftForward
+		if backward || forward {
+			d := Drift{Last: last, Now: timestamp, Jump: time.Duration(timestamp-last) * time.Millisecond}
+			switch g.drift(d) {
+			case DriftClamp:
+				if backward {
+					timestamp = last - g.driftBackward
+				} else {
+					timestamp = last + g.driftForward
+				}
+			case DriftError:
+				// Re-baseline so only the call that saw the jump fails.
+				g.lastSeen.Store(timestamp)
+				return 0, fmt.Errorf("%w: clock jumped %v from %d to %d", ErrClockDrift, d.Jump, last, timestamp)
+			case DriftWait:
+				for backward && timestamp < last {
+					time.Sleep(time.Millisecond)
+					timestamp = g.now()
+				}
+			}
+		}
+	}
+	g.lastSeen.Store(timestamp)
+	return timestamp, nil
+}
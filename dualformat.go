@@ -0,0 +1,113 @@
+package nano64
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// DefaultDualHexField is the JSON field DualFormat uses for the hex form.
+	DefaultDualHexField = "id"
+
+	// DefaultDualIntField is the JSON field DualFormat uses for the integer form.
+	DefaultDualIntField = "id_int"
+)
+
+// DualFormat wraps an ID for APIs migrating clients from integer IDs to hex
+// strings. It marshals as an object carrying both forms,
+//
+//	{"id":"199C01B6659-5861C","id_int":1845351830215034396}
+//
+// and unmarshals from either field alone, checking they agree when both are
+// present. Set HexField and IntField to change the field names; empty names
+// use the defaults. Integers above 2^53 lose precision in JavaScript clients,
+// which is why the hex form is the migration target.
+type DualFormat struct {
+	ID Nano64
+
+	// HexField and IntField name the JSON fields; empty means "id" and "id_int".
+	HexField, IntField string
+}
+
+// fields returns the configured field names with defaults applied.
+func (d DualFormat) fields() (hexField, intField string) {
+	hexField, intField = d.HexField, d.IntField
+	if hexField == "" {
+		hexField = DefaultDualHexField
+	}
+	if intField == "" {
+		intField = DefaultDualIntField
+	}
+	return hexField, intField
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DualFormat) MarshalJSON() ([]byte, error) {
+	hexField, intField := d.fields()
+	if hexField == intField {
+		return nil, fmt.Errorf("DualFormat field names must differ, both are %q", hexField)
+	}
+	var buf bytes.Buffer
+	key, _ := json.Marshal(hexField)
+	buf.WriteByte('{')
+	buf.Write(key)
+	buf.WriteString(`:"`)
+	buf.WriteString(d.ID.ToHex())
+	buf.WriteString(`",`)
+	key, _ = json.Marshal(intField)
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.FormatUint(d.ID.value, 10))
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts the
+// hex field, the integer field (as a number or a decimal string), or both.
+// Field names are taken from the receiver, so set them before decoding.
+func (d *DualFormat) UnmarshalJSON(data []byte) error {
+	hexField, intField := d.fields()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to unmarshal DualFormat: %w", err)
+	}
+
+	var (
+		id             Nano64
+		hasHex, hasInt bool
+		hexRaw, intRaw json.RawMessage
+	)
+	hexRaw, hasHex = fields[hexField]
+	intRaw, hasInt = fields[intField]
+	if !hasHex && !hasInt {
+		return fmt.Errorf("DualFormat needs %q or %q", hexField, intField)
+	}
+
+	if hasHex {
+		var text string
+		if err := json.Unmarshal(hexRaw, &text); err != nil {
+			return fmt.Errorf("invalid DualFormat %s: %w", hexField, err)
+		}
+		parsed, err := Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid DualFormat %s: %w", hexField, err)
+		}
+		id = parsed
+	}
+	if hasInt {
+		text := string(bytes.Trim(intRaw, `"`))
+		value, err := parseDecimalID(text)
+		if err != nil {
+			return fmt.Errorf("invalid DualFormat %s: %w", intField, err)
+		}
+		if hasHex && value != id.value {
+			return fmt.Errorf("DualFormat %s %s does not match %s %s", intField, text, hexField, id.ToHex())
+		}
+		id = Nano64{value: value}
+	}
+
+	d.ID = id
+	return nil
+}
@@ -0,0 +1,110 @@
+package nano64
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Encoding is a textual ID encoding. Register one with RegisterEncoding to
+// plug in an organization-specific scheme, such as a legacy check-digit
+// format, and have Parse and the nano64 command pick it up.
+// Implementations must be safe for concurrent use.
+type Encoding interface {
+	// Name identifies the encoding, e.g. "base32". Names are case-sensitive.
+	Name() string
+
+	// Encode returns the text form of id.
+	Encode(id Nano64) string
+
+	// Decode parses text produced by Encode.
+	Decode(s string) (Nano64, error)
+}
+
+// builtinEncoding adapts the package's own encoders to Encoding.
+type builtinEncoding struct {
+	name   string
+	encode func(Nano64) string
+	decode func(string) (Nano64, error)
+}
+
+func (e builtinEncoding) Name() string                    { return e.name }
+func (e builtinEncoding) Encode(id Nano64) string         { return e.encode(id) }
+func (e builtinEncoding) Decode(s string) (Nano64, error) { return e.decode(s) }
+
+var (
+	// encodingsMu guards encodings and custom.
+	encodingsMu sync.RWMutex
+
+	// encodings holds every encoding by name, built-in and registered.
+	encodings = map[string]Encoding{}
+
+	// custom lists the registered encodings in registration order; Parse
+	// falls back to them.
+	custom []Encoding
+)
+
+func init() {
+	for _, e := range []builtinEncoding{
+		{"hex", Nano64.ToHex, FromHex},
+		base32Encoding,
+		{"base58", Nano64.ToBase58, FromBase58},
+		{"base62", Nano64.ToBase62, FromBase62},
+		{"base64url", Nano64.ToBase64URL, FromBase64URL},
+	} {
+		encodings[e.name] = e
+	}
+}
+
+// RegisterEncoding adds e to the registry, making it available to
+// LookupEncoding and to Parse, which tries registered encodings in
+// registration order when its input is not hex. Typically called from an
+// init function. Fails if the name is empty or already taken, including by
+// the built-in "hex", "base32", "base58", "base62" and "base64url" encodings.
+func RegisterEncoding(e Encoding) error {
+	name := e.Name()
+	if name == "" {
+		return fmt.Errorf("encoding name cannot be empty")
+	}
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	if _, ok := encodings[name]; ok {
+		return fmt.Errorf("encoding %q already registered", name)
+	}
+	encodings[name] = e
+	custom = append(custom, e)
+	return nil
+}
+
+// LookupEncoding returns the built-in or registered encoding with the given name.
+func LookupEncoding(name string) (Encoding, bool) {
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+	e, ok := encodings[name]
+	return e, ok
+}
+
+// EncodingNames returns the names of all built-in and registered encodings,
+// sorted.
+func EncodingNames() []string {
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// decodeRegistered tries the registered encodings in registration order.
+func decodeRegistered(s string) (Nano64, bool) {
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+	for _, e := range custom {
+		if id, err := e.Decode(s); err == nil {
+			return id, true
+		}
+	}
+	return Nano64{}, false
+}
@@ -1,10 +1,14 @@
 package nano64
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
+	"encoding/binary"
 	"fmt"
+	"sync"
+	"time"
+	"unsafe"
 )
 
 const (
@@ -40,16 +44,34 @@ func (e EncryptedNano64) ToEncryptedBytes() []byte {
 	return result
 }
 
+// PayloadString returns the raw 36-byte payload as a string without copying.
+// The payload is never modified after creation, so the view is safe to keep,
+// unless e is reused with EncryptInto or ReleaseEncrypted. Useful for writing
+// the payload out (e.g. io.WriteString) on hot paths.
+func (e EncryptedNano64) PayloadString() string {
+	if len(e.payload) == 0 {
+		return ""
+	}
+	return unsafe.String(&e.payload[0], len(e.payload))
+}
+
+// AppendPayload appends the raw payload bytes to dst and returns the extended slice.
+func (e EncryptedNano64) AppendPayload(dst []byte) []byte {
+	return append(dst, e.payload...)
+}
+
 // EncryptedIDConfig holds configuration for encrypted Nano64 operations.
 type EncryptedIDConfig struct {
 	gcm   cipher.AEAD
 	clock Clock
 	rng   RNG
+	cache *decryptCache
+	gen   *Generator
 }
 
 // NewEncryptedIDConfig creates a new configuration for encrypted Nano64 operations.
 // The aesKey must be 16, 24, or 32 bytes for AES-128, AES-192, or AES-256 respectively.
-func NewEncryptedIDConfig(aesKey []byte, clock Clock, rng RNG) (*EncryptedIDConfig, error) {
+func NewEncryptedIDConfig(aesKey []byte, clock Clock, rng RNG, opts ...EncryptedOption) (*EncryptedIDConfig, error) {
 	if clock == nil {
 		clock = DefaultClock
 	}
@@ -67,55 +89,184 @@ func NewEncryptedIDConfig(aesKey []byte, clock Clock, rng RNG) (*EncryptedIDConf
 		return nil, fmt.Errorf("failed to create GCM: %w", err)
 	}
 
-	return &EncryptedIDConfig{
+	c := &EncryptedIDConfig{
 		gcm:   gcm,
 		clock: clock,
 		rng:   rng,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// WithGenerator makes GenerateEncrypted draw IDs monotonically from gen
+// instead of generating them independently. When a process mints both plain
+// and encrypted IDs, sharing one Generator puts every ID on the same
+// monotonic sequence, so they interleave in the order they were created.
+// GenerateEncryptedNow then reads gen's clock, and the clock and RNG passed
+// to NewEncryptedIDConfig are not used for generation. A nil gen restores
+// the default.
+func WithGenerator(gen *Generator) EncryptedOption {
+	return func(c *EncryptedIDConfig) {
+		c.gen = gen
+	}
+}
+
+// scratchPool holds 8-byte buffers for plaintext IDs, which would otherwise
+// escape to the heap through the cipher.AEAD interface on every call.
+var scratchPool = sync.Pool{
+	New: func() any { return new([8]byte) },
+}
+
+// encryptedBox co-allocates an EncryptedNano64 with its payload bytes so that
+// producing a result costs a single allocation.
+type encryptedBox struct {
+	enc EncryptedNano64
+	buf [PayloadLength]byte
 }
 
-// generateIV generates a fresh 96-bit random IV.
-func (c *EncryptedIDConfig) generateIV() ([]byte, error) {
-	iv := make([]byte, IVLength)
-	if _, err := rand.Read(iv); err != nil {
-		return nil, fmt.Errorf("failed to generate IV: %w", err)
+// newEncrypted returns an EncryptedNano64 whose payload is a zeroed PayloadLength slice.
+func (c *EncryptedIDConfig) newEncrypted(id Nano64) *EncryptedNano64 {
+	box := new(encryptedBox)
+	box.enc = EncryptedNano64{ID: id, payload: box.buf[:], gcm: c.gcm}
+	return &box.enc
+}
+
+// fillIV writes a fresh 96-bit random IV into iv.
+func (c *EncryptedIDConfig) fillIV(iv []byte) error {
+	if err := readEntropy(iv[:IVLength]); err != nil {
+		return fmt.Errorf("failed to generate IV: %w", err)
 	}
-	return iv, nil
+	return nil
 }
 
 // Encrypt encrypts an existing Nano64 into an authenticated payload.
 func (c *EncryptedIDConfig) Encrypt(id Nano64) (*EncryptedNano64, error) {
-	iv, err := c.generateIV()
-	if err != nil {
+	enc := c.newEncrypted(id)
+	if err := c.traceEncrypt(func(trace *EncryptTrace) error {
+		return c.encryptInto(enc, id, trace)
+	}); err != nil {
 		return nil, err
 	}
+	return enc, nil
+}
+
+// EncryptInto encrypts id into dst, reusing dst's payload buffer when it has
+// room, so services minting an encrypted ID per request can recycle results
+// (see AcquireEncrypted) instead of allocating one each time. Any earlier
+// payload of dst, including views from PayloadString, is overwritten. On
+// error dst is left unusable.
+func (c *EncryptedIDConfig) EncryptInto(dst *EncryptedNano64, id Nano64) error {
+	if cap(dst.payload) < PayloadLength {
+		dst.payload = make([]byte, PayloadLength)
+	}
+	dst.payload = dst.payload[:PayloadLength]
+	dst.ID, dst.gcm = id, c.gcm
+	return c.traceEncrypt(func(trace *EncryptTrace) error {
+		return c.encryptInto(dst, id, trace)
+	})
+}
+
+// EncryptValue is Encrypt returning the result by value, for embedding in
+// structs without a separate heap object. Only the payload is allocated.
+func (c *EncryptedIDConfig) EncryptValue(id Nano64) (EncryptedNano64, error) {
+	var enc EncryptedNano64
+	if err := c.EncryptInto(&enc, id); err != nil {
+		return EncryptedNano64{}, err
+	}
+	return enc, nil
+}
+
+// traceEncrypt runs fn, reporting to the OnEncrypt hook when one is installed.
+func (c *EncryptedIDConfig) traceEncrypt(fn func(trace *EncryptTrace) error) error {
+	h := hooks.Load()
+	if h == nil || h.OnEncrypt == nil {
+		return fn(nil)
+	}
+
+	var trace EncryptTrace
+	start := time.Now()
+	err := fn(&trace)
+	trace.Total = time.Since(start)
+	trace.Err = err
+	h.OnEncrypt(trace)
+	return err
+}
+
+// encryptInto seals id into enc's PayloadLength payload, filling trace
+// timings when trace is non-nil. The IV, ciphertext and tag are written
+// straight into the payload, so nothing is allocated.
+func (c *EncryptedIDConfig) encryptInto(enc *EncryptedNano64, id Nano64, trace *EncryptTrace) error {
+	payload := enc.payload
+
+	ivStart := traceStart(trace != nil)
+	err := c.fillIV(payload[:IVLength])
+	if trace != nil {
+		trace.IVWait = traceSince(ivStart)
+	}
+	if err != nil {
+		return err
+	}
 
-	plaintext := BigIntHelpers.ToBytesBE(id.value)
-	ciphertext := c.gcm.Seal(nil, iv, plaintext, nil)
+	plaintext := scratchPool.Get().(*[8]byte)
+	binary.BigEndian.PutUint64(plaintext[:], id.value)
+	sealStart := traceStart(trace != nil)
+	ciphertext := c.gcm.Seal(payload[IVLength:IVLength], payload[:IVLength], plaintext[:], nil)
+	if trace != nil {
+		trace.AEAD = traceSince(sealStart)
+	}
+	scratchPool.Put(plaintext)
 
 	if len(ciphertext) != 8+16 {
-		return nil, fmt.Errorf("unexpected AES-GCM output length: %d", len(ciphertext))
+		return fmt.Errorf("unexpected AES-GCM output length: %d", len(ciphertext))
 	}
+	return nil
+}
+
+// encryptedPool recycles EncryptedNano64 values for AcquireEncrypted.
+var encryptedPool = sync.Pool{
+	New: func() any {
+		box := new(encryptedBox)
+		box.enc.payload = box.buf[:]
+		return &box.enc
+	},
+}
 
-	// Construct payload: IV + ciphertext (includes tag)
-	payload := make([]byte, PayloadLength)
-	copy(payload[0:], iv)
-	copy(payload[IVLength:], ciphertext)
+// AcquireEncrypted returns an EncryptedNano64 from a shared pool, ready for
+// EncryptInto. Return it with ReleaseEncrypted once its payload has been
+// written out.
+func AcquireEncrypted() *EncryptedNano64 {
+	return encryptedPool.Get().(*EncryptedNano64)
+}
 
-	return &EncryptedNano64{
-		ID:      id,
-		payload: payload,
-		gcm:     c.gcm,
-	}, nil
+// ReleaseEncrypted clears e and returns it to the pool used by
+// AcquireEncrypted. Neither e nor any PayloadString view of it may be used
+// afterwards.
+func ReleaseEncrypted(e *EncryptedNano64) {
+	if e == nil || cap(e.payload) < PayloadLength {
+		return
+	}
+	e.ID, e.gcm = Nil, nil
+	e.payload = e.payload[:PayloadLength]
+	clear(e.payload)
+	encryptedPool.Put(e)
 }
 
-// GenerateEncrypted generates a new Nano64, then encrypts it.
+// GenerateEncrypted generates a new Nano64, then encrypts it. With
+// WithGenerator the ID comes from the shared generator's monotonic sequence.
 func (c *EncryptedIDConfig) GenerateEncrypted(timestamp int64) (*EncryptedNano64, error) {
 	if timestamp == 0 {
-		timestamp = c.clock()
+		timestamp = c.now()
 	}
 
-	id, err := Generate(timestamp, c.rng)
+	var id Nano64
+	var err error
+	if c.gen != nil {
+		id, err = c.gen.GenerateMonotonic(timestamp)
+	} else {
+		id, err = Generate(timestamp, c.rng)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate ID: %w", err)
 	}
@@ -125,55 +276,152 @@ func (c *EncryptedIDConfig) GenerateEncrypted(timestamp int64) (*EncryptedNano64
 
 // GenerateEncryptedNow generates a new Nano64 with current timestamp, then encrypts it.
 func (c *EncryptedIDConfig) GenerateEncryptedNow() (*EncryptedNano64, error) {
-	return c.GenerateEncrypted(c.clock())
+	return c.GenerateEncrypted(c.now())
 }
 
-// FromEncryptedBytes decrypts from raw 36-byte payload.
+// now returns the current time from the shared generator's clock, if any,
+// or the config's own.
+func (c *EncryptedIDConfig) now() int64 {
+	if c.gen != nil {
+		return c.gen.now()
+	}
+	return c.clock()
+}
+
+// FromEncryptedBytes decrypts from raw 36-byte payload. Errors match
+// ErrMalformedPayload or ErrAuthFailed with errors.Is.
 func (c *EncryptedIDConfig) FromEncryptedBytes(bytes []byte) (*EncryptedNano64, error) {
+	return c.FromEncryptedBytesContext(context.Background(), bytes)
+}
+
+// FromEncryptedBytesContext is FromEncryptedBytes bounded by a request's
+// context. A decrypt cache hit (see WithDecryptCache) is returned even when ctx
+// is done, since it costs no more than the check; on a miss, a done ctx fails
+// with its error instead of spending AES-GCM work on a request that has already
+// been abandoned or timed out.
+func (c *EncryptedIDConfig) FromEncryptedBytesContext(ctx context.Context, bytes []byte) (*EncryptedNano64, error) {
+	h := hooks.Load()
+	if h == nil || h.OnDecrypt == nil {
+		return c.fromEncryptedBytes(ctx, bytes, nil)
+	}
+
+	var trace DecryptTrace
+	start := time.Now()
+	enc, err := c.fromEncryptedBytes(ctx, bytes, &trace)
+	trace.Total = time.Since(start)
+	trace.Err = err
+	h.OnDecrypt(trace)
+	return enc, err
+}
+
+// fromEncryptedBytes implements FromEncryptedBytesContext, filling trace when it is non-nil.
+func (c *EncryptedIDConfig) fromEncryptedBytes(ctx context.Context, bytes []byte, trace *DecryptTrace) (*EncryptedNano64, error) {
 	if len(bytes) != PayloadLength {
-		return nil, fmt.Errorf("encrypted payload must be %d bytes, got %d", PayloadLength, len(bytes))
+		return nil, fmt.Errorf("%w: must be %d bytes, got %d", ErrMalformedPayload, PayloadLength, len(bytes))
+	}
+
+	var key *[PayloadLength]byte
+	if c.cache != nil {
+		key = (*[PayloadLength]byte)(bytes)
+		if id, ok := c.cache.get(key); ok {
+			if trace != nil {
+				trace.CacheHit = true
+			}
+			enc := c.newEncrypted(id)
+			copy(enc.payload, bytes)
+			return enc, nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	id, err := c.openPayload(bytes)
+	if err != nil {
+		return nil, err
 	}
 
+	// Copy the payload defensively into the result's own buffer
+	enc := c.newEncrypted(id)
+	copy(enc.payload, bytes)
+	if key != nil {
+		c.cache.put(key, enc.ID)
+	}
+	return enc, nil
+}
+
+// openPayload authenticates and decrypts a payload of PayloadLength bytes.
+func (c *EncryptedIDConfig) openPayload(bytes []byte) (Nano64, error) {
 	iv := bytes[:IVLength]
 	ciphertext := bytes[IVLength:]
 
-	plaintext, err := c.gcm.Open(nil, iv, ciphertext, nil)
+	scratch := scratchPool.Get().(*[8]byte)
+	defer scratchPool.Put(scratch)
+	plaintext, err := c.gcm.Open(scratch[:0], iv, ciphertext, nil)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
+		return Nano64{}, fmt.Errorf("decryption failed: %w", ErrAuthFailed)
 	}
 
 	if len(plaintext) != 8 {
-		return nil, fmt.Errorf("decryption yielded invalid length: %d", len(plaintext))
+		return Nano64{}, fmt.Errorf("%w: decryption yielded invalid length: %d", ErrMalformedPayload, len(plaintext))
 	}
+	return Nano64{value: binary.BigEndian.Uint64(plaintext)}, nil
+}
 
-	value, err := BigIntHelpers.FromBytesBE(plaintext)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse decrypted bytes: %w", err)
+// Verify reports whether payload is a valid encrypted ID under this config's
+// key without returning the ID, for audit tooling that must classify tokens
+// but not learn their contents. The error matches ErrMalformedPayload or
+// ErrAuthFailed with errors.Is. It bypasses the decrypt cache and does not
+// call Hooks.OnDecrypt, so verified IDs are not retained or observed.
+func (c *EncryptedIDConfig) Verify(payload []byte) error {
+	if len(payload) != PayloadLength {
+		return fmt.Errorf("%w: must be %d bytes, got %d", ErrMalformedPayload, PayloadLength, len(payload))
 	}
+	_, err := c.openPayload(payload)
+	return err
+}
 
-	id := Nano64{value: value}
-
-	// Make a defensive copy of the payload
-	payload := make([]byte, len(bytes))
-	copy(payload, bytes)
+// Seal encrypts and authenticates arbitrary plaintext with the config's key,
+// appending IV || ciphertext || tag to dst. Subpackages use it to protect
+// structures that embed IDs. Pass a non-empty additionalData unique to the
+// structure so sealed blobs cannot be confused with ID payloads or each other.
+func (c *EncryptedIDConfig) Seal(dst, plaintext, additionalData []byte) ([]byte, error) {
+	start := len(dst)
+	dst = append(dst, make([]byte, IVLength)...)
+	if err := c.fillIV(dst[start:]); err != nil {
+		return nil, err
+	}
+	return c.gcm.Seal(dst, dst[start:start+IVLength], plaintext, additionalData), nil
+}
 
-	return &EncryptedNano64{
-		ID:      id,
-		payload: payload,
-		gcm:     c.gcm,
-	}, nil
+// Open verifies and decrypts a blob produced by Seal with the same additionalData.
+func (c *EncryptedIDConfig) Open(sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < IVLength+c.gcm.Overhead() {
+		return nil, fmt.Errorf("%w: sealed data too short: %d bytes", ErrMalformedPayload, len(sealed))
+	}
+	plaintext, err := c.gcm.Open(nil, sealed[:IVLength], sealed[IVLength:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", ErrAuthFailed)
+	}
+	return plaintext, nil
 }
 
 // FromEncryptedHex decrypts from 72-char hex payload.
 func (c *EncryptedIDConfig) FromEncryptedHex(encHex string) (*EncryptedNano64, error) {
+	return c.FromEncryptedHexContext(context.Background(), encHex)
+}
+
+// FromEncryptedHexContext is FromEncryptedHex bounded by ctx, as for
+// FromEncryptedBytesContext.
+func (c *EncryptedIDConfig) FromEncryptedHexContext(ctx context.Context, encHex string) (*EncryptedNano64, error) {
 	bytes, err := Hex.ToBytes(encHex)
 	if err != nil {
-		return nil, fmt.Errorf("invalid hex: %w", err)
+		return nil, fmt.Errorf("%w: invalid hex: %w", ErrMalformedPayload, err)
 	}
 
 	if len(bytes) != PayloadLength {
-		return nil, fmt.Errorf("encrypted payload must be %d bytes, got %d", PayloadLength, len(bytes))
+		return nil, fmt.Errorf("%w: must be %d bytes, got %d", ErrMalformedPayload, PayloadLength, len(bytes))
 	}
 
-	return c.FromEncryptedBytes(bytes)
+	return c.FromEncryptedBytesContext(ctx, bytes)
 }
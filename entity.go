@@ -0,0 +1,163 @@
+package nano64
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EntityType describes one kind of entity, such as users or orders, whose IDs
+// share validation rules. Register entity types with RegisterEntity and
+// validate IDs at API boundaries with ValidateEntity or ParseEntity.
+type EntityType struct {
+	// Name identifies the entity type, e.g. "order". Names are case-sensitive.
+	Name string
+
+	// Since is the entity's business epoch, typically the launch date of the
+	// service that creates it. IDs with an earlier timestamp cannot belong to
+	// this entity and are rejected, which catches mix-ups such as an old user
+	// ID passed where an order ID is expected. The zero time means no bound.
+	Since time.Time
+
+	// Prefix is the optional type prefix of the entity's IDs in
+	// FormatPrefixed form, e.g. "ord" for "ord_1K701PSJSB1GW". Once any
+	// registered entity has a prefix, ParsePrefixed only accepts registered
+	// prefixes and validates IDs against their entity.
+	Prefix string
+}
+
+// Validate checks that id could belong to the entity type. The error wraps
+// ErrPredatesEntity when the ID is older than t.Since.
+func (t EntityType) Validate(id Nano64) error {
+	if t.Since.IsZero() {
+		return nil
+	}
+	if min := t.Since.UnixMilli(); id.GetTimestamp() < min {
+		return fmt.Errorf("%w: %s ID %s is from %s, before %s",
+			ErrPredatesEntity, t.Name, id.ToHex(),
+			id.ToDate().UTC().Format(time.RFC3339), t.Since.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Parse parses s with Parse and then validates the ID against the entity type.
+func (t EntityType) Parse(s string) (Nano64, error) {
+	id, err := Parse(s)
+	if err != nil {
+		return Nil, err
+	}
+	if err := t.Validate(id); err != nil {
+		return Nil, err
+	}
+	return id, nil
+}
+
+var (
+	// entitiesMu guards entities.
+	entitiesMu sync.RWMutex
+
+	// entities holds the registered entity types by name.
+	entities = map[string]EntityType{}
+)
+
+// RegisterEntity adds t to the entity registry. Typically called from an init
+// function. Fails if the name is empty or already registered, or if the prefix
+// is invalid or already taken by another entity.
+func RegisterEntity(t EntityType) error {
+	if t.Name == "" {
+		return fmt.Errorf("entity name cannot be empty")
+	}
+	if t.Prefix != "" && !isValidPrefix(t.Prefix) {
+		return fmt.Errorf("entity %q: invalid prefix %q", t.Name, t.Prefix)
+	}
+	entitiesMu.Lock()
+	defer entitiesMu.Unlock()
+	if _, ok := entities[t.Name]; ok {
+		return fmt.Errorf("entity %q already registered", t.Name)
+	}
+	if t.Prefix != "" {
+		if other, ok := lookupPrefix(t.Prefix); ok {
+			return fmt.Errorf("prefix %q already registered by entity %q", t.Prefix, other.Name)
+		}
+	}
+	entities[t.Name] = t
+	return nil
+}
+
+// LookupEntity returns the registered entity type with the given name.
+func LookupEntity(name string) (EntityType, bool) {
+	entitiesMu.RLock()
+	defer entitiesMu.RUnlock()
+	t, ok := entities[name]
+	return t, ok
+}
+
+// LookupPrefix returns the registered entity type with the given prefix.
+func LookupPrefix(prefix string) (EntityType, bool) {
+	if prefix == "" {
+		return EntityType{}, false
+	}
+	entitiesMu.RLock()
+	defer entitiesMu.RUnlock()
+	return lookupPrefix(prefix)
+}
+
+// lookupPrefix finds the entity type with the given prefix; the caller holds
+// entitiesMu. Registries are small, so a scan is enough.
+func lookupPrefix(prefix string) (EntityType, bool) {
+	for _, t := range entities {
+		if t.Prefix == prefix {
+			return t, true
+		}
+	}
+	return EntityType{}, false
+}
+
+// prefixEntity returns the entity type with the given prefix, and whether
+// any registered entity type has a prefix at all.
+func prefixEntity(prefix string) (t EntityType, ok, inUse bool) {
+	entitiesMu.RLock()
+	defer entitiesMu.RUnlock()
+	for _, e := range entities {
+		if e.Prefix == "" {
+			continue
+		}
+		inUse = true
+		if e.Prefix == prefix {
+			return e, true, true
+		}
+	}
+	return EntityType{}, false, inUse
+}
+
+// EntityNames returns the names of all registered entity types, sorted.
+func EntityNames() []string {
+	entitiesMu.RLock()
+	defer entitiesMu.RUnlock()
+	names := make([]string, 0, len(entities))
+	for name := range entities {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateEntity validates id against the registered entity type name.
+// Unknown entity names are an error.
+func ValidateEntity(name string, id Nano64) error {
+	t, ok := LookupEntity(name)
+	if !ok {
+		return fmt.Errorf("unknown entity %q", name)
+	}
+	return t.Validate(id)
+}
+
+// ParseEntity parses s and validates it against the registered entity type name.
+func ParseEntity(name, s string) (Nano64, error) {
+	t, ok := LookupEntity(name)
+	if !ok {
+		return Nil, fmt.Errorf("unknown entity %q", name)
+	}
+	return t.Parse(s)
+}
@@ -0,0 +1,98 @@
+package nano64
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// MaxRandomTokenBytes caps RandomToken to keep accidental huge reads out of hot paths.
+const MaxRandomTokenBytes = 1024
+
+// readEntropy fills p with cryptographically secure random bytes.
+// All package entropy that is not drawn through an RNG goes through here.
+func readEntropy(p []byte) error {
+	if _, err := rand.Read(p); err != nil {
+		return fmt.Errorf("failed to read entropy: %w", err)
+	}
+	return nil
+}
+
+// entropyChunk is how many bytes a pooled entropy buffer reads from crypto/rand at once.
+const entropyChunk = 1024
+
+// entropyBuffer is a chunk of crypto/rand output handed out 4 bytes at a time.
+type entropyBuffer struct {
+	buf [entropyChunk]byte
+	off int
+}
+
+// entropyPool holds entropy buffers. sync.Pool keeps one per processor, so
+// concurrent callers rarely share a buffer and never take a lock. A buffer is
+// owned by one caller between Get and Put, so no bytes are handed out twice.
+var entropyPool = sync.Pool{
+	New: func() any { return &entropyBuffer{off: entropyChunk} },
+}
+
+// pooledUint32 returns 4 bytes of crypto/rand output from a pooled buffer,
+// refilling it when exhausted. Consumed bytes are zeroed.
+func pooledUint32() (uint32, error) {
+	b := entropyPool.Get().(*entropyBuffer)
+	defer entropyPool.Put(b)
+	if b.off+4 > len(b.buf) {
+		if err := readEntropy(b.buf[:]); err != nil {
+			return 0, err
+		}
+		b.off = 0
+	}
+	chunk := b.buf[b.off : b.off+4]
+	v := binary.BigEndian.Uint32(chunk)
+	clear(chunk)
+	b.off += 4
+	return v, nil
+}
+
+// RandomToken returns n cryptographically secure random bytes encoded as
+// unpadded URL-safe base64, suitable for CSRF tokens and other web nonces.
+// n must be 1-MaxRandomTokenBytes; 16 or more is recommended.
+func RandomToken(n int) (string, error) {
+	if n < 1 || n > MaxRandomTokenBytes {
+		return "", fmt.Errorf("token bytes must be 1-%d, got %d", MaxRandomTokenBytes, n)
+	}
+	buf := make([]byte, n)
+	if err := readEntropy(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Nonce96 returns a fresh random 96-bit nonce, the standard AES-GCM nonce size.
+func Nonce96() ([IVLength]byte, error) {
+	var nonce [IVLength]byte
+	err := readEntropy(nonce[:])
+	return nonce, err
+}
+
+// RNGInRange returns a uniformly distributed random value in [0, max) drawn
+// from the package entropy source. It uses rejection sampling, so unlike
+// taking a raw RNG value modulo max it has no bias toward small values.
+// Use it for bounded fields such as node IDs or retry jitter.
+func RNGInRange(max uint32) (uint32, error) {
+	if max == 0 {
+		return 0, fmt.Errorf("max must be positive")
+	}
+	// Values below threshold would make some residues more likely; reject them.
+	threshold := -max % max
+	var buf [4]byte
+	for {
+		if err := readEntropy(buf[:]); err != nil {
+			return 0, err
+		}
+		v := binary.BigEndian.Uint32(buf[:])
+		if v >= threshold {
+			return v % max, nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package nano64
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned when decrypting encrypted IDs and sealed blobs. Match them
+// with errors.Is to tell a garbled token from a forged one.
+var (
+	// ErrMalformedPayload reports input that cannot be a payload at all:
+	// invalid hex, the wrong length, or a plaintext of the wrong size.
+	ErrMalformedPayload = errors.New("malformed encrypted payload")
+
+	// ErrAuthFailed reports a well-formed payload whose authentication tag
+	// does not verify. It was tampered with or sealed under a different key;
+	// the two cases cannot be told apart until payloads carry a key ID.
+	ErrAuthFailed = errors.New("authentication failed")
+)
+
+// ErrClockRegression is returned by a strict monotonic generator (see
+// WithStrictMonotonic) when it is asked for an ID with a timestamp further
+// behind the last issued one than its tolerance, typically after an NTP step.
+var ErrClockRegression = errors.New("clock regression")
+
+// ErrQuotaExceeded is returned by Issuer.Issue when a tenant has used its
+// quota for the current window.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrPrecisionLoss is returned by Rebase when part of an ID does not fit the
+// target layout, so converting it would lose information.
+var ErrPrecisionLoss = errors.New("precision loss")
+
+// ErrPredatesEntity is returned when an ID is older than the business epoch
+// of the entity type it is validated against (see EntityType).
+var ErrPredatesEntity = errors.New("ID predates entity")
+
+// idError annotates an error with the ID of the entity it concerns.
+type idError struct {
+	id  Nano64
+	err error
+}
+
+// Error returns the wrapped message prefixed with the ID.
+func (e *idError) Error() string {
+	return fmt.Sprintf("id %s: %v", e.id.ToHex(), e.err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *idError) Unwrap() error {
+	return e.err
+}
+
+// WrapWithID annotates err with the ID of the entity it concerns, so handlers
+// further up the stack can recover it with IDFromError.
+// The result still matches err with errors.Is and errors.As. Returns nil if err is nil.
+func WrapWithID(err error, id Nano64) error {
+	if err == nil {
+		return nil
+	}
+	return &idError{id: id, err: err}
+}
+
+// IDFromError returns the ID attached by the outermost WrapWithID in err's chain.
+func IDFromError(err error) (Nano64, bool) {
+	var e *idError
+	if errors.As(err, &e) {
+		return e.id, true
+	}
+	return Nil, false
+}
+
+// ParseError reports why text is not a valid ID. FromHex, Parse and
+// ParseWithOptions return it for every rejected input, so callers can use
+// errors.As to build precise responses such as "invalid character at
+// position 12".
+type ParseError struct {
+	// Input is the rejected text.
+	Input string
+
+	// Offset is the byte offset of the offending character. It equals
+	// len(Input) when the input ended too early, and is -1 when the input is
+	// well-formed text but not an acceptable ID (wrong length, timestamp out of range).
+	Offset int
+
+	// Expected describes what the parser expected at Offset, e.g. "hex digit".
+	Expected string
+}
+
+// Error describes the failure, e.g.
+// `invalid ID "199C01B6659-5861G": unexpected 'G' at position 16, expected hex digit`.
+func (e *ParseError) Error() string {
+	switch {
+	case e.Offset < 0:
+		return fmt.Sprintf("invalid ID %q: expected %s", e.Input, e.Expected)
+	case e.Offset >= len(e.Input):
+		return fmt.Sprintf("invalid ID %q: unexpected end of input at position %d, expected %s", e.Input, e.Offset, e.Expected)
+	}
+	return fmt.Sprintf("invalid ID %q: unexpected %q at position %d, expected %s", e.Input, e.Input[e.Offset], e.Offset, e.Expected)
+}
+
+// ErrChecksumMismatch is returned by FromHexChecked when the check character
+// does not match the ID, typically because of a typo or transposition.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrOrderMismatch is returned by VerifyStorageOrder when a storage engine's
+// ORDER BY disagrees with Nano64's unsigned order.
+var ErrOrderMismatch = errors.New("storage order mismatch")
+
+// ErrUnknownPrefix is returned by ParsePrefixed for a prefix no registered
+// entity type uses, once any entity type has registered one.
+var ErrUnknownPrefix = errors.New("unknown ID prefix")
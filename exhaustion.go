@@ -0,0 +1,76 @@
+package nano64
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// exhaustionPoll caps how long NotifyBeforeExhaustion sleeps between clock checks,
+// so it follows clocks installed with SetDefaultClock and wall-clock jumps.
+const exhaustionPoll = time.Hour
+
+// ExhaustionTime returns the last instant representable by the 44-bit
+// timestamp, counted from the default epoch (see SetDefaultEpoch).
+func ExhaustionTime() time.Time {
+	return time.UnixMilli(DefaultEpoch() + maxTimestamp).UTC()
+}
+
+// TimeUntilExhaustion returns how long until the timestamp range runs out,
+// measured with the package default clock. It saturates at the maximum
+// time.Duration (about 292 years) and is zero or negative once exhausted.
+func TimeUntilExhaustion() time.Duration {
+	return untilExhaustion(DefaultEpoch() + maxTimestamp - now())
+}
+
+// ExhaustionTime returns the last instant representable by the generator's
+// 44-bit timestamp, which is ExhaustionTime shifted by its epoch (see WithEpoch).
+func (g *Generator) ExhaustionTime() time.Time {
+	return time.UnixMilli(g.epoch + maxTimestamp).UTC()
+}
+
+// TimeUntilExhaustion returns how long until the generator's timestamp
+// range runs out, measured with its clock. It saturates like the
+// package-level TimeUntilExhaustion.
+func (g *Generator) TimeUntilExhaustion() time.Duration {
+	return untilExhaustion(g.epoch + maxTimestamp - g.now())
+}
+
+// untilExhaustion converts the remaining milliseconds to a saturated duration.
+func untilExhaustion(remaining int64) time.Duration {
+	if remaining > math.MaxInt64/int64(time.Millisecond) {
+		return math.MaxInt64
+	}
+	return time.Duration(remaining) * time.Millisecond
+}
+
+// NotifyBeforeExhaustion returns a channel that is closed once the timestamp
+// range of gen, or of the package-level functions if gen is nil, is within
+// threshold of running out. If that is already the case, the channel is
+// closed immediately. A background goroutine re-checks the clock at least
+// hourly until it fires or ctx is done; in the latter case the channel stays
+// open, so select on ctx.Done() as well.
+func NotifyBeforeExhaustion(ctx context.Context, gen *Generator, threshold time.Duration) <-chan struct{} {
+	until := TimeUntilExhaustion
+	if gen != nil {
+		until = gen.TimeUntilExhaustion
+	}
+	ch := make(chan struct{})
+	go func() {
+		for {
+			wait := until() - threshold
+			if wait <= 0 {
+				close(ch)
+				return
+			}
+			timer := time.NewTimer(min(wait, exhaustionPoll))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,53 @@
+package nano64
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// Anonymize returns an ID with the same timestamp as id but with the random
+// field replaced by a keyed PRF (HMAC-SHA256) of the whole original value.
+// Analytics exports keep their time distribution, and the same ID always maps
+// to the same pseudonym under one key, so joins within the export still work.
+// Without the key, pseudonyms cannot be linked back to the original IDs.
+//
+// The 20-bit output space means distinct IDs from the same millisecond can
+// share a pseudonym; expect the collision rate of Generate at that volume.
+// Nil maps to Nil. Use a key of at least 32 random bytes and rotate it per
+// export if datasets must not be joinable with each other.
+func Anonymize(id Nano64, key []byte) Nano64 {
+	if id.IsNil() {
+		return Nil
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], id.value)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	random := binary.BigEndian.Uint64(sum[:8]) & randomMask
+	return Nano64{value: id.value&^randomMask | random}
+}
+
+// GeneralizeForExport returns id with its random field zeroed and its
+// timestamp truncated down to a multiple of granularity since the Unix epoch
+// (so 24h truncates to UTC days). Shared data then carries only coarse
+// creation times, and every ID created in the same window becomes identical.
+// Granularities under a millisecond keep full precision.
+func GeneralizeForExport(id Nano64, granularity time.Duration) Nano64 {
+	ts := id.GetTimestamp()
+	if step := granularity.Milliseconds(); step > 1 {
+		ts -= ts % step
+	}
+	return minIDAt(max(ts-DefaultEpoch(), 0))
+}
+
+// GeneralizeAllForExport applies GeneralizeForExport to every ID, returning a new slice.
+func GeneralizeAllForExport(ids []Nano64, granularity time.Duration) []Nano64 {
+	out := make([]Nano64, len(ids))
+	for i, id := range ids {
+		out[i] = GeneralizeForExport(id, granularity)
+	}
+	return out
+}
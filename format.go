@@ -0,0 +1,50 @@
+package nano64
+
+// GroupOption selects how FormatHex splits the 16 hex digits.
+type GroupOption int
+
+const (
+	// GroupCanonical is the 11-5 timestamp-random split produced by ToHex.
+	GroupCanonical GroupOption = iota
+
+	// GroupNone emits the 16 digits without dashes.
+	GroupNone
+
+	// GroupQuads emits four dash-separated groups of four digits.
+	GroupQuads
+)
+
+const hexDigitsUpper = "0123456789ABCDEF"
+
+// FormatHex returns the uppercase hex form of the ID using the given grouping.
+// Parse and ParseWithOptions (with AllowGrouping) accept every grouping.
+func (n Nano64) FormatHex(group GroupOption) string {
+	var buf [19]byte
+	return string(n.appendHex(buf[:0], group))
+}
+
+// AppendHex appends the canonical ToHex form of the ID to dst and returns the
+// extended buffer. It does not allocate when dst has 17 bytes of spare
+// capacity, so hot logging and serialization paths can reuse a buffer.
+func (n Nano64) AppendHex(dst []byte) []byte {
+	return n.appendHex(dst, GroupCanonical)
+}
+
+// AppendText implements the encoding.TextAppender interface, appending the
+// canonical hex form like AppendHex. It never returns an error.
+func (n Nano64) AppendText(dst []byte) ([]byte, error) {
+	return n.AppendHex(dst), nil
+}
+
+// appendHex appends the uppercase hex form of the ID using the given grouping to dst.
+func (n Nano64) appendHex(dst []byte, group GroupOption) []byte {
+	for i := 0; i < 16; i++ {
+		switch {
+		case group == GroupCanonical && i == 11,
+			group == GroupQuads && i > 0 && i%4 == 0:
+			dst = append(dst, '-')
+		}
+		dst = append(dst, hexDigitsUpper[n.value>>(60-4*i)&0xF])
+	}
+	return dst
+}
@@ -0,0 +1,390 @@
+package nano64
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Generator produces IDs from its own clock, RNG and monotonic state, so
+// several independent ID streams can run in one process without sharing the
+// package-level monotonic sequence. Its methods mirror the package-level
+// functions. A Generator is safe for concurrent use.
+type Generator struct {
+	clock Clock
+	rng   RNG
+	epoch int64
+
+	nodeID   uint32
+	nodeBits int
+
+	overflow  string
+	strict    bool
+	tolerance int64
+
+	drift         DriftPolicy
+	driftBackward int64
+	driftForward  int64
+	lastSeen      atomic.Int64
+
+	floor    uint64            // see WithFloor; zero means none
+	reserved func(Nano64) bool // see WithReserved
+	profile  Profile           // see WithProfile
+
+	state monotonicState
+}
+
+// GeneratorOption configures a Generator.
+type GeneratorOption func(*Generator)
+
+// WithClock sets the generator's clock. Without it the generator follows the
+// package default clock (see SetDefaultClock).
+func WithClock(clock Clock) GeneratorOption {
+	return func(g *Generator) {
+		g.clock = clock
+	}
+}
+
+// WithRNG sets the generator's RNG. It is wrapped with RaceSafeRNG, so RNGs
+// over non-thread-safe sources such as *math/rand.Rand can be passed as is.
+// Without it the generator follows the package default RNG (see SetDefaultRNG).
+func WithRNG(rng RNG) GeneratorOption {
+	return func(g *Generator) {
+		g.rng = RaceSafeRNG(rng)
+	}
+}
+
+// WithFastRNG makes the generator use FastRNG. Unlike WithRNG(FastRNG) it
+// does not add a lock, since FastRNG is already safe for concurrent use. See
+// FastRNG for when a non-cryptographic RNG is appropriate.
+func WithFastRNG() GeneratorOption {
+	return func(g *Generator) {
+		g.rng = FastRNG
+	}
+}
+
+// WithEpoch makes the generator count timestamps from epoch (Unix ms) instead
+// of the default epoch (see SetDefaultEpoch). With a recent epoch the 44-bit
+// field lasts until epoch + 557 years rather than 2527. Timestamps passed to
+// and read through the generator stay Unix milliseconds; it subtracts and adds
+// the epoch. To have GetTimestamp and ToDate decode the IDs everywhere, set the
+// same epoch package-wide with SetDefaultEpoch instead; otherwise decode them
+// with the generator's Timestamp and ToDate. Negative epochs are treated as zero.
+func WithEpoch(epoch int64) GeneratorOption {
+	return func(g *Generator) {
+		g.epoch = max(epoch, 0)
+	}
+}
+
+// WithNodeID reserves the top bits of the random field for id, so generators
+// on different nodes can never collide. The remaining RandomBits-bits bits
+// hold the random value and, for monotonic IDs, the per-ms sequence, so
+// each node gets 2^(RandomBits-bits) monotonic IDs per millisecond.
+// Read the node back with GetNodeID(bits). Panics unless bits is
+// 1-(RandomBits-1) and id fits in bits.
+func WithNodeID(id uint32, bits int) GeneratorOption {
+	if bits < 1 || bits >= RandomBits {
+		panic(fmt.Sprintf("nano64: node bits must be 1-%d, got %d", RandomBits-1, bits))
+	}
+	if id >= 1<<bits {
+		panic(fmt.Sprintf("nano64: node ID %d does not fit in %d bits", id, bits))
+	}
+	return func(g *Generator) {
+		g.nodeID = id
+		g.nodeBits = bits
+	}
+}
+
+// WithOverflowPolicy sets what GenerateMonotonic does when a millisecond's
+// sequence is exhausted. OverflowAdvance, the default, borrows the next
+// millisecond, so under sustained load IDs carry timestamps ahead of the
+// clock. OverflowWait instead sleeps until the generator's clock reaches the
+// next millisecond, for systems that treat the embedded timestamp as
+// authoritative; the clock must advance in real time. After waiting, the
+// ID is stamped with the clock reading even if GenerateMonotonic was given
+// an explicit timestamp. Panics on any other policy.
+func WithOverflowPolicy(policy string) GeneratorOption {
+	if policy != OverflowAdvance && policy != OverflowWait {
+		panic(fmt.Sprintf("nano64: unknown overflow policy %q", policy))
+	}
+	return func(g *Generator) {
+		g.overflow = policy
+	}
+}
+
+// WithStrictMonotonic makes GenerateMonotonic return an error wrapping
+// ErrClockRegression when given a timestamp more than tolerance behind the
+// last issued one, instead of silently reusing the last timestamp. Use it to
+// surface NTP steps and other clock jumps so they can be alerted on.
+// Regressions within tolerance are still clamped. Borrowed milliseconds count
+// as issued, so pair it with OverflowWait under sustained load.
+func WithStrictMonotonic(tolerance time.Duration) GeneratorOption {
+	return func(g *Generator) {
+		g.strict = true
+		g.tolerance = max(tolerance.Milliseconds(), 0)
+	}
+}
+
+// maxReservedSkips bounds how many reserved IDs in a row a generator skips
+// before giving up, so a predicate that reserves everything cannot hang it.
+const maxReservedSkips = 16
+
+// WithReserved makes the generator skip IDs for which isReserved returns
+// true, generating the next candidate instead. Use it to keep fresh IDs clear
+// of sentinel and seed-data IDs, e.g. with a list from the reserved
+// subpackage. isReserved must be fast and safe for concurrent use.
+func WithReserved(isReserved func(Nano64) bool) GeneratorOption {
+	return func(g *Generator) {
+		g.reserved = isReserved
+	}
+}
+
+// NewGenerator returns a Generator with fresh monotonic state.
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{overflow: OverflowAdvance, epoch: DefaultEpoch()}
+	g.lastSeen.Store(noTimestamp)
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.nodeBits > 0 {
+		g.state.pressure.capacity = 1 << g.sequenceBits()
+	}
+	g.seedFloor()
+	return g
+}
+
+// GeneratorFromManifest returns a new Generator using the epoch and policies
+// of a JSON manifest, as produced by (*Generator).Manifest, after checking
+// that its layout is one this package produces, so services configured from a
+// shared manifest refuse to start on a mismatch. A manifest reserving node
+// bits requires a WithNodeID option of that width. The error clock regression
+// policy starts with zero tolerance; pass WithStrictMonotonic in opts to
+// allow more.
+func GeneratorFromManifest(data []byte, opts ...GeneratorOption) (*Generator, error) {
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	base := []GeneratorOption{WithEpoch(manifest.Layout.Epoch)}
+	switch manifest.Policies.Overflow {
+	case "", OverflowAdvance:
+	case OverflowWait:
+		base = append(base, WithOverflowPolicy(OverflowWait))
+	default:
+		return nil, fmt.Errorf("invalid manifest: unknown overflow policy %q", manifest.Policies.Overflow)
+	}
+	switch manifest.Policies.ClockRegression {
+	case "", ClockRegressionClamp:
+	case ClockRegressionError:
+		base = append(base, WithStrictMonotonic(0))
+	default:
+		return nil, fmt.Errorf("invalid manifest: unknown clock regression policy %q", manifest.Policies.ClockRegression)
+	}
+	g := NewGenerator(append(base, opts...)...)
+	if g.nodeBits != manifest.Layout.NodeBits {
+		return nil, fmt.Errorf("manifest reserves %d node bits but the generator uses %d", manifest.Layout.NodeBits, g.nodeBits)
+	}
+	return g, nil
+}
+
+// Manifest returns the JSON manifest describing the IDs this generator
+// produces: its layout, epoch, node bits and policies, but never its RNG or
+// other secrets. Decode it with ParseManifest to compare or fingerprint it.
+func (g *Generator) Manifest() ([]byte, error) {
+	return g.manifest().Marshal()
+}
+
+// manifest returns the generator's manifest before encoding.
+func (g *Generator) manifest() Manifest {
+	m := CurrentManifest()
+	m.Layout.Epoch = g.epoch
+	m.Layout.NodeBits = g.nodeBits
+	m.Policies.Overflow = g.overflow
+	if g.strict {
+		m.Policies.ClockRegression = ClockRegressionError
+	}
+	return m
+}
+
+// sequenceBits returns the width of the random field left after the node ID.
+func (g *Generator) sequenceBits() int {
+	return RandomBits - g.nodeBits
+}
+
+// policy returns the monotonic policy configured by the generator's options.
+func (g *Generator) policy() monotonicPolicy {
+	return monotonicPolicy{
+		seqBits:   g.sequenceBits(),
+		overflow:  g.overflow,
+		strict:    g.strict,
+		tolerance: g.tolerance,
+	}
+}
+
+// withNode replaces the node bits of id with the generator's node ID.
+func (g *Generator) withNode(id Nano64) Nano64 {
+	if g.nodeBits == 0 {
+		return id
+	}
+	shift := g.sequenceBits()
+	nodeMask := uint64(1)<<RandomBits - 1<<shift
+	return Nano64{value: id.value&^nodeMask | uint64(g.nodeID)<<shift}
+}
+
+// Epoch returns the Unix millisecond the generator's timestamps count from.
+func (g *Generator) Epoch() int64 {
+	return g.epoch
+}
+
+// Timestamp returns the Unix millisecond timestamp of an ID from this generator.
+func (g *Generator) Timestamp(id Nano64) int64 {
+	return id.rawTimestamp() + g.epoch
+}
+
+// ToDate returns the creation time of an ID from this generator.
+func (g *Generator) ToDate(id Nano64) time.Time {
+	return time.UnixMilli(g.Timestamp(id))
+}
+
+// offset converts a Unix millisecond timestamp to the generator's epoch.
+func (g *Generator) offset(timestamp int64) (int64, error) {
+	if timestamp < g.epoch {
+		return 0, fmt.Errorf("timestamp %d is before the generator epoch %d", timestamp, g.epoch)
+	}
+	return timestamp - g.epoch, nil
+}
+
+// now returns the generator's current time.
+func (g *Generator) now() int64 {
+	if g.clock != nil {
+		return g.clock()
+	}
+	return now()
+}
+
+// randomSource returns the generator's RNG.
+func (g *Generator) randomSource() RNG {
+	if g.rng != nil {
+		return g.rng
+	}
+	return packageRNG()
+}
+
+// Generate creates an ID with the given Unix millisecond timestamp and the generator's RNG.
+func (g *Generator) Generate(timestamp int64) (Nano64, error) {
+	return g.traced(false, func(trace *GenerateTrace) (Nano64, error) {
+		return g.skipReserved(func() (Nano64, error) { return g.generate(timestamp, trace) })
+	})
+}
+
+// generate implements Generate, filling trace timings when trace is non-nil.
+func (g *Generator) generate(timestamp int64, trace *GenerateTrace) (Nano64, error) {
+	ts, err := g.checkDrift(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
+	ts, err = g.offset(ts)
+	if err != nil {
+		return Nano64{}, err
+	}
+	id, err := generate(ts, g.randomSource(), trace)
+	if err != nil {
+		return Nano64{}, err
+	}
+	return g.aboveFloor(g.withNode(id))
+}
+
+// GenerateNow creates an ID at the generator's current time.
+func (g *Generator) GenerateNow() (Nano64, error) {
+	return g.Generate(g.now())
+}
+
+// GenerateAsync generates an ID at the generator's current time on a new
+// goroutine and passes the result to cb, returning immediately. Use it to
+// overlap generation with other work where the RNG is slow. cb runs on that
+// goroutine, so it must be safe to call concurrently with the caller.
+// IDs are not monotonic: concurrent callbacks may complete in any order.
+func (g *Generator) GenerateAsync(cb func(Nano64, error)) {
+	go func() {
+		cb(g.GenerateNow())
+	}()
+}
+
+// GenerateMonotonic creates an ID strictly greater than every ID this
+// generator has produced monotonically, following the same rules as the
+// package-level GenerateMonotonic but with the generator's own state.
+func (g *Generator) GenerateMonotonic(timestamp int64) (Nano64, error) {
+	return g.traced(true, func(trace *GenerateTrace) (Nano64, error) {
+		return g.skipReserved(func() (Nano64, error) { return g.generateMonotonic(timestamp, trace) })
+	})
+}
+
+// GenerateMonotonicNow creates a monotonic ID at the generator's current time.
+func (g *Generator) GenerateMonotonicNow() (Nano64, error) {
+	return g.GenerateMonotonic(g.now())
+}
+
+// generateMonotonic implements GenerateMonotonic, filling trace timings when trace is non-nil.
+func (g *Generator) generateMonotonic(timestamp int64, trace *GenerateTrace) (Nano64, error) {
+	timestamp, err := g.checkDrift(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
+	timestamp, err = g.offset(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
+	if timestamp > maxTimestamp {
+		return Nano64{}, fmt.Errorf("timestamp exceeds 44-bit range: %d > %d", timestamp, maxTimestamp)
+	}
+	rng := g.randomSource()
+
+	for {
+		id, err := g.state.advance(timestamp, g.policy(), rng, trace)
+		if err == nil {
+			return g.aboveFloor(g.withNode(id))
+		}
+		if err != errSequenceExhausted {
+			return Nano64{}, err
+		}
+		time.Sleep(time.Millisecond)
+		timestamp = max(timestamp, g.now()-g.epoch)
+	}
+}
+
+// Pressure returns the fraction (0-1) of the per-millisecond random space
+// consumed by this generator's monotonic IDs, averaged over roughly the last
+// ten milliseconds. See MonotonicPressure.
+func (g *Generator) Pressure() float64 {
+	return g.state.pressure.value(g.now() - g.epoch)
+}
+
+// skipReserved calls gen until it returns an error or an ID that is not
+// reserved (see WithReserved).
+func (g *Generator) skipReserved(gen func() (Nano64, error)) (Nano64, error) {
+	for skipped := 0; ; skipped++ {
+		id, err := gen()
+		if err != nil || g.reserved == nil || !g.reserved(id) {
+			return id, err
+		}
+		if skipped == maxReservedSkips {
+			return Nano64{}, fmt.Errorf("generated %d reserved IDs in a row", skipped+1)
+		}
+	}
+}
+
+// traced runs fn, reporting to the OnGenerate hook when one is installed.
+func (g *Generator) traced(monotonic bool, fn func(trace *GenerateTrace) (Nano64, error)) (Nano64, error) {
+	h := hooks.Load()
+	if h == nil || h.OnGenerate == nil {
+		return fn(nil)
+	}
+
+	trace := GenerateTrace{Monotonic: monotonic}
+	start := time.Now()
+	id, err := fn(&trace)
+	trace.Total = time.Since(start)
+	trace.Err = err
+	h.OnGenerate(trace)
+	return id, err
+}
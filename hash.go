@@ -0,0 +1,22 @@
+package nano64
+
+// Hash returns a well-mixed 64-bit hash of the ID for maps and partitioners.
+//
+// The algorithm is fixed so every service and language hashes IDs identically:
+// it is one SplitMix64 step over (value XOR seed), all arithmetic mod 2^64:
+//
+//	z := value ^ seed
+//	z += 0x9E3779B97F4A7C15
+//	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+//	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+//	return z ^ (z >> 31)
+//
+// Hash is not cryptographic; do not use it where an adversary picks the IDs
+// and collisions matter.
+func (n Nano64) Hash(seed uint64) uint64 {
+	z := n.value ^ seed
+	z += 0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
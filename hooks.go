@@ -0,0 +1,258 @@
+package nano64
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hooks receives opt-in instrumentation callbacks from ID generation and encryption.
+// Nil fields are skipped. Callbacks run synchronously on the calling goroutine,
+// so they must be fast and safe for concurrent use.
+type Hooks struct {
+	// OnGenerate is called after every Generate and GenerateMonotonic call,
+	// including those made through a Generator.
+	OnGenerate func(GenerateTrace)
+
+	// OnEncrypt is called after every EncryptedIDConfig.Encrypt call.
+	OnEncrypt func(EncryptTrace)
+
+	// OnDecrypt is called after every EncryptedIDConfig.FromEncryptedBytes
+	// call, including those made through FromEncryptedHex.
+	OnDecrypt func(DecryptTrace)
+
+	// OnIssue is called after every Issuer.Issue call with the tenant's
+	// counters, including calls rejected with ErrQuotaExceeded.
+	OnIssue func(IssueTrace)
+}
+
+// GenerateTrace describes where time went during one ID generation.
+type GenerateTrace struct {
+	// Monotonic is true for GenerateMonotonic calls.
+	Monotonic bool
+
+	// LockWait is the time lost to contention on the monotonic state, i.e.
+	// retrying after other goroutines advanced it first. The name predates
+	// lock-free generation.
+	LockWait time.Duration
+
+	// RNGWait is the time spent inside the RNG. Zero when no entropy was drawn.
+	RNGWait time.Duration
+
+	// Total is the wall time of the whole call.
+	Total time.Duration
+
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// EncryptTrace describes where time went during one encryption.
+type EncryptTrace struct {
+	// IVWait is the time spent reading entropy for the IV.
+	IVWait time.Duration
+
+	// AEAD is the time spent sealing the payload.
+	AEAD time.Duration
+
+	// Total is the wall time of the whole call.
+	Total time.Duration
+
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// DecryptTrace describes one decryption.
+type DecryptTrace struct {
+	// CacheHit is true when the ID came from the decrypt cache (see WithDecryptCache).
+	CacheHit bool
+
+	// Total is the wall time of the whole call.
+	Total time.Duration
+
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// hooks holds the package-level hooks; nil means instrumentation is off.
+var hooks atomic.Pointer[Hooks]
+
+// SetHooks installs package-level instrumentation hooks and returns a function
+// restoring the previous ones. Passing nil disables instrumentation.
+// With no hooks installed, generation does not read the clock for tracing.
+func SetHooks(h *Hooks) (restore func()) {
+	prev := hooks.Swap(h)
+	return func() {
+		hooks.Store(prev)
+	}
+}
+
+// traceStart returns the current time if tracing is enabled, or the zero time otherwise.
+func traceStart(enabled bool) time.Time {
+	if !enabled {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// traceSince returns the time elapsed since start, or zero if tracing is disabled.
+func traceSince(start time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// histogramBuckets is the number of exponential buckets; bucket i counts
+// durations in (2^(i-1), 2^i] nanoseconds, the last bucket is unbounded.
+const histogramBuckets = 40
+
+// LatencyHistogram is a lock-free exponential (power-of-two) histogram of durations.
+// The zero value is ready to use.
+type LatencyHistogram struct {
+	counts [histogramBuckets]atomic.Uint64
+}
+
+// HistogramBucket is one bucket of a LatencyHistogram snapshot.
+type HistogramBucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	// The last bucket's bound is the largest representable duration.
+	UpperBound time.Duration
+
+	// Count is the number of observations in the bucket.
+	Count uint64
+}
+
+// Observe records one duration.
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	h.counts[bucketFor(d)].Add(1)
+}
+
+// bucketFor returns the index of the bucket holding d.
+func bucketFor(d time.Duration) int {
+	if d <= 1 {
+		return 0
+	}
+	i := bits.Len64(uint64(d - 1))
+	if i >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return i
+}
+
+// bucketBound returns the inclusive upper bound of bucket i.
+func bucketBound(i int) time.Duration {
+	if i == histogramBuckets-1 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(1) << i
+}
+
+// Count returns the total number of observations.
+func (h *LatencyHistogram) Count() uint64 {
+	var total uint64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+	}
+	return total
+}
+
+// Snapshot returns the non-empty buckets in ascending order.
+func (h *LatencyHistogram) Snapshot() []HistogramBucket {
+	var buckets []HistogramBucket
+	for i := range h.counts {
+		if c := h.counts[i].Load(); c > 0 {
+			buckets = append(buckets, HistogramBucket{UpperBound: bucketBound(i), Count: c})
+		}
+	}
+	return buckets
+}
+
+// Quantile returns the upper bound of the bucket containing quantile q (0..1),
+// e.g. Quantile(0.99) for p99. Returns 0 if nothing was observed.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	if q < 0 {
+		q = 0
+	}
+	if q > 1 {
+		q = 1
+	}
+
+	rank := uint64(q * float64(total))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i := range h.counts {
+		seen += h.counts[i].Load()
+		if seen >= rank {
+			return bucketBound(i)
+		}
+	}
+	return bucketBound(histogramBuckets - 1)
+}
+
+// Reset clears all observations.
+func (h *LatencyHistogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
+
+// LatencyRecorder collects generation and encryption latencies into histograms,
+// separating contention, entropy and AEAD time so p99 regressions can be attributed.
+type LatencyRecorder struct {
+	// LockWait records monotonic contention time (see GenerateTrace.LockWait).
+	LockWait LatencyHistogram
+
+	// RNGWait records time spent in the RNG.
+	RNGWait LatencyHistogram
+
+	// Generate records total generation time.
+	Generate LatencyHistogram
+
+	// IVWait records time spent reading IV entropy during encryption.
+	IVWait LatencyHistogram
+
+	// AEAD records time spent sealing encrypted payloads.
+	AEAD LatencyHistogram
+
+	// Encrypt records total encryption time.
+	Encrypt LatencyHistogram
+
+	once  sync.Once
+	hooks *Hooks
+}
+
+// NewLatencyRecorder creates an empty LatencyRecorder.
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{}
+}
+
+// Hooks returns hooks feeding this recorder, for use with SetHooks.
+func (r *LatencyRecorder) Hooks() *Hooks {
+	r.once.Do(func() {
+		r.hooks = &Hooks{
+			OnGenerate: func(t GenerateTrace) {
+				if t.Monotonic {
+					r.LockWait.Observe(t.LockWait)
+				}
+				if t.RNGWait > 0 {
+					r.RNGWait.Observe(t.RNGWait)
+				}
+				r.Generate.Observe(t.Total)
+			},
+			OnEncrypt: func(t EncryptTrace) {
+				r.IVWait.Observe(t.IVWait)
+				r.AEAD.Observe(t.AEAD)
+				r.Encrypt.Observe(t.Total)
+			},
+		}
+	})
+	return r.hooks
+}
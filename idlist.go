@@ -0,0 +1,63 @@
+package nano64
+
+import "io"
+
+// idListElementLength is the size of one quoted canonical hex ID in a JSON array.
+const idListElementLength = 1 + 17 + 1
+
+// idListChunk is the buffer size EncodeIDList fills before each write.
+const idListChunk = 4096
+
+// MarshalIDList returns ids as a JSON array of canonical hex strings, the same
+// output as json.Marshal(ids) but computed directly into one exactly sized
+// buffer, with no per-element allocation or reflection. A nil or empty slice
+// encodes as [].
+func MarshalIDList(ids []Nano64) ([]byte, error) {
+	size := 2
+	if len(ids) > 0 {
+		size += len(ids)*(idListElementLength+1) - 1
+	}
+	return appendIDList(make([]byte, 0, size), ids), nil
+}
+
+// EncodeIDList streams ids to w as a JSON array of canonical hex strings,
+// writing in fixed-size chunks so memory stays constant however many IDs are
+// encoded. Use it for list endpoints returning tens of thousands of IDs.
+func EncodeIDList(w io.Writer, ids []Nano64) error {
+	buf := make([]byte, 0, idListChunk)
+	buf = append(buf, '[')
+	for i, id := range ids {
+		if len(buf)+idListElementLength+1 > cap(buf) {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendQuotedHex(buf, id)
+	}
+	buf = append(buf, ']')
+	_, err := w.Write(buf)
+	return err
+}
+
+// appendIDList appends ids to dst as a JSON array of canonical hex strings.
+func appendIDList(dst []byte, ids []Nano64) []byte {
+	dst = append(dst, '[')
+	for i, id := range ids {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendQuotedHex(dst, id)
+	}
+	return append(dst, ']')
+}
+
+// appendQuotedHex appends the canonical hex form of id as a JSON string.
+func appendQuotedHex(dst []byte, id Nano64) []byte {
+	dst = append(dst, '"')
+	dst = id.appendHex(dst, GroupCanonical)
+	return append(dst, '"')
+}
@@ -0,0 +1,356 @@
+// Package index provides an in-memory B-tree keyed by Nano64.
+//
+// Because Nano64 IDs sort by creation time, an ordered tree doubles as a time
+// index: ScanRange visits every entry created in a time window without a
+// secondary structure. Tree is the reference structure for caches and embedded
+// query layers built on nano64.
+package index
+
+import (
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// degree is the B-tree minimum degree: non-root nodes hold degree-1 to
+// 2*degree-1 items.
+const degree = 32
+
+const maxItems = 2*degree - 1
+
+type item[V any] struct {
+	key   uint64
+	value V
+}
+
+type node[V any] struct {
+	items    []item[V]
+	children []*node[V]
+}
+
+func (n *node[V]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// find returns the index of the first item with key >= k and whether it equals k.
+func (n *node[V]) find(k uint64) (int, bool) {
+	lo, hi := 0, len(n.items)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if n.items[mid].key < k {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, lo < len(n.items) && n.items[lo].key == k
+}
+
+// Tree is a B-tree mapping Nano64 IDs to values of type V.
+// The zero value is an empty tree ready to use. Tree is not safe for
+// concurrent use; guard it with a sync.RWMutex when sharing.
+type Tree[V any] struct {
+	root   *node[V]
+	length int
+}
+
+// New returns an empty tree.
+func New[V any]() *Tree[V] {
+	return &Tree[V]{}
+}
+
+// Len returns the number of entries.
+func (t *Tree[V]) Len() int {
+	return t.length
+}
+
+// Get returns the value stored under id.
+func (t *Tree[V]) Get(id nano64.Nano64) (V, bool) {
+	k := id.Uint64Value()
+	for n := t.root; n != nil; {
+		i, found := n.find(k)
+		if found {
+			return n.items[i].value, true
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	var zero V
+	return zero, false
+}
+
+// Put stores v under id and reports whether an existing value was replaced.
+func (t *Tree[V]) Put(id nano64.Nano64, v V) bool {
+	k := id.Uint64Value()
+	if t.root == nil {
+		t.root = &node[V]{items: []item[V]{{k, v}}}
+		t.length = 1
+		return false
+	}
+	if len(t.root.items) == maxItems {
+		t.root = &node[V]{children: []*node[V]{t.root}}
+		t.root.splitChild(0)
+	}
+	replaced := t.root.insert(k, v)
+	if !replaced {
+		t.length++
+	}
+	return replaced
+}
+
+// insert adds k to the subtree rooted at the non-full node n.
+func (n *node[V]) insert(k uint64, v V) bool {
+	i, found := n.find(k)
+	if found {
+		n.items[i].value = v
+		return true
+	}
+	if n.leaf() {
+		n.items = append(n.items, item[V]{})
+		copy(n.items[i+1:], n.items[i:])
+		n.items[i] = item[V]{k, v}
+		return false
+	}
+	if len(n.children[i].items) == maxItems {
+		n.splitChild(i)
+		switch {
+		case k == n.items[i].key:
+			n.items[i].value = v
+			return true
+		case k > n.items[i].key:
+			i++
+		}
+	}
+	return n.children[i].insert(k, v)
+}
+
+// splitChild splits the full child i around its median, which moves up into n.
+func (n *node[V]) splitChild(i int) {
+	child := n.children[i]
+	median := child.items[degree-1]
+	right := &node[V]{items: append([]item[V](nil), child.items[degree:]...)}
+	clear(child.items[degree-1:])
+	child.items = child.items[:degree-1]
+	if !child.leaf() {
+		right.children = append([]*node[V](nil), child.children[degree:]...)
+		clear(child.children[degree:])
+		child.children = child.children[:degree]
+	}
+
+	n.items = append(n.items, item[V]{})
+	copy(n.items[i+1:], n.items[i:])
+	n.items[i] = median
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+// Delete removes id and reports whether it was present.
+func (t *Tree[V]) Delete(id nano64.Nano64) bool {
+	if t.root == nil {
+		return false
+	}
+	removed := t.root.remove(id.Uint64Value())
+	if len(t.root.items) == 0 {
+		if t.root.leaf() {
+			t.root = nil
+		} else {
+			t.root = t.root.children[0]
+		}
+	}
+	if removed {
+		t.length--
+	}
+	return removed
+}
+
+// remove deletes k from the subtree rooted at n, which has at least degree
+// items unless it is the root.
+func (n *node[V]) remove(k uint64) bool {
+	i, found := n.find(k)
+	if n.leaf() {
+		if !found {
+			return false
+		}
+		n.removeItem(i)
+		return true
+	}
+
+	if found {
+		switch {
+		case len(n.children[i].items) >= degree:
+			pred := n.children[i].max()
+			n.items[i] = pred
+			return n.children[i].remove(pred.key)
+		case len(n.children[i+1].items) >= degree:
+			succ := n.children[i+1].min()
+			n.items[i] = succ
+			return n.children[i+1].remove(succ.key)
+		default:
+			n.merge(i)
+			return n.children[i].remove(k)
+		}
+	}
+
+	if len(n.children[i].items) == degree-1 {
+		switch {
+		case i > 0 && len(n.children[i-1].items) >= degree:
+			n.rotateRight(i)
+		case i < len(n.items) && len(n.children[i+1].items) >= degree:
+			n.rotateLeft(i)
+		case i < len(n.items):
+			n.merge(i)
+		default:
+			n.merge(i - 1)
+			i--
+		}
+	}
+	return n.children[i].remove(k)
+}
+
+func (n *node[V]) removeItem(i int) {
+	copy(n.items[i:], n.items[i+1:])
+	n.items[len(n.items)-1] = item[V]{}
+	n.items = n.items[:len(n.items)-1]
+}
+
+func (n *node[V]) removeChild(i int) {
+	copy(n.children[i:], n.children[i+1:])
+	n.children[len(n.children)-1] = nil
+	n.children = n.children[:len(n.children)-1]
+}
+
+func (n *node[V]) min() item[V] {
+	for !n.leaf() {
+		n = n.children[0]
+	}
+	return n.items[0]
+}
+
+func (n *node[V]) max() item[V] {
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+	return n.items[len(n.items)-1]
+}
+
+// rotateRight moves an item from child i-1 through n into child i.
+func (n *node[V]) rotateRight(i int) {
+	left, child := n.children[i-1], n.children[i]
+	child.items = append(child.items, item[V]{})
+	copy(child.items[1:], child.items)
+	child.items[0] = n.items[i-1]
+	n.items[i-1] = left.items[len(left.items)-1]
+	left.removeItem(len(left.items) - 1)
+	if !left.leaf() {
+		child.children = append(child.children, nil)
+		copy(child.children[1:], child.children)
+		child.children[0] = left.children[len(left.children)-1]
+		left.removeChild(len(left.children) - 1)
+	}
+}
+
+// rotateLeft moves an item from child i+1 through n into child i.
+func (n *node[V]) rotateLeft(i int) {
+	child, right := n.children[i], n.children[i+1]
+	child.items = append(child.items, n.items[i])
+	n.items[i] = right.items[0]
+	right.removeItem(0)
+	if !right.leaf() {
+		child.children = append(child.children, right.children[0])
+		right.removeChild(0)
+	}
+}
+
+// merge joins child i, item i and child i+1 into child i.
+func (n *node[V]) merge(i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.items = append(left.items, n.items[i])
+	left.items = append(left.items, right.items...)
+	left.children = append(left.children, right.children...)
+	n.removeItem(i)
+	n.removeChild(i + 1)
+}
+
+// Ascend calls fn for every entry in ID order until fn returns false.
+func (t *Tree[V]) Ascend(fn func(id nano64.Nano64, v V) bool) {
+	if t.root != nil {
+		t.root.ascend(0, 0, false, fn)
+	}
+}
+
+// AscendRange calls fn for every entry with an ID in [lo, hi), in ID order,
+// until fn returns false.
+func (t *Tree[V]) AscendRange(lo, hi nano64.Nano64, fn func(id nano64.Nano64, v V) bool) {
+	if t.root != nil {
+		t.root.ascend(lo.Uint64Value(), hi.Uint64Value(), true, fn)
+	}
+}
+
+// ScanRange calls fn for every entry whose ID was created in [from, to),
+// oldest first, until fn returns false. Times outside the 44-bit timestamp
+// range are clamped.
+func (t *Tree[V]) ScanRange(from, to time.Time, fn func(id nano64.Nano64, v V) bool) {
+	if t.root == nil {
+		return
+	}
+	lo, hi := timeKey(from), timeKey(to)
+	t.root.ascend(lo, hi, hi != ^uint64(0), fn)
+}
+
+// timeKey returns the smallest key at the given time, clamping out-of-range times.
+// Times at or past the end of the timestamp range map to the largest key.
+func timeKey(t time.Time) uint64 {
+	ms := t.UnixMilli() - nano64.DefaultEpoch()
+	switch {
+	case ms <= 0:
+		return 0
+	case ms > 1<<nano64.TimestampBits-1:
+		return ^uint64(0)
+	}
+	return uint64(ms) << nano64.RandomBits
+}
+
+// ascend visits keys >= lo (and < hi when bounded) in order; it returns false
+// once iteration should stop.
+func (n *node[V]) ascend(lo, hi uint64, bounded bool, fn func(nano64.Nano64, V) bool) bool {
+	i, _ := n.find(lo)
+	for ; i < len(n.items); i++ {
+		if !n.leaf() && !n.children[i].ascend(lo, hi, bounded, fn) {
+			return false
+		}
+		it := n.items[i]
+		if bounded && it.key >= hi {
+			return false
+		}
+		if !fn(nano64.New(it.key), it.value) {
+			return false
+		}
+	}
+	if !n.leaf() {
+		return n.children[len(n.items)].ascend(lo, hi, bounded, fn)
+	}
+	return true
+}
+
+// Min returns the entry with the smallest (oldest) ID.
+func (t *Tree[V]) Min() (nano64.Nano64, V, bool) {
+	if t.root == nil {
+		var zero V
+		return nano64.Nil, zero, false
+	}
+	it := t.root.min()
+	return nano64.New(it.key), it.value, true
+}
+
+// Max returns the entry with the largest (newest) ID.
+func (t *Tree[V]) Max() (nano64.Nano64, V, bool) {
+	if t.root == nil {
+		var zero V
+		return nano64.Nil, zero, false
+	}
+	it := t.root.max()
+	return nano64.New(it.key), it.value, true
+}
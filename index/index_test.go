@@ -0,0 +1,178 @@
+package index
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// check verifies B-tree invariants and returns the tree height.
+func (n *node[V]) check(t *testing.T, root bool, lo, hi uint64) int {
+	t.Helper()
+	if !root && (len(n.items) < degree-1 || len(n.items) > maxItems) {
+		t.Fatalf("node has %d items, want %d-%d", len(n.items), degree-1, maxItems)
+	}
+	for i, it := range n.items {
+		if it.key < lo || it.key > hi || (i > 0 && it.key <= n.items[i-1].key) {
+			t.Fatalf("node keys out of order or bounds")
+		}
+	}
+	if n.leaf() {
+		return 1
+	}
+	if len(n.children) != len(n.items)+1 {
+		t.Fatalf("node has %d children for %d items", len(n.children), len(n.items))
+	}
+	height := -1
+	for i, c := range n.children {
+		clo, chi := lo, hi
+		if i > 0 {
+			clo = n.items[i-1].key + 1
+		}
+		if i < len(n.items) {
+			chi = n.items[i].key - 1
+		}
+		h := c.check(t, false, clo, chi)
+		if height >= 0 && h != height {
+			t.Fatal("leaves at different depths")
+		}
+		height = h
+	}
+	return height + 1
+}
+
+func (tr *Tree[V]) check(t *testing.T) {
+	t.Helper()
+	if tr.root != nil {
+		tr.root.check(t, true, 0, ^uint64(0))
+	}
+}
+
+func TestTree_RandomOps(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tree := New[int]()
+	ref := map[uint64]int{}
+
+	for op := 0; op < 50000; op++ {
+		k := r.Uint64N(5000)
+		id := nano64.New(k)
+		switch r.IntN(3) {
+		case 0, 1:
+			_, existed := ref[k]
+			if replaced := tree.Put(id, op); replaced != existed {
+				t.Fatalf("Put(%d) replaced = %v, want %v", k, replaced, existed)
+			}
+			ref[k] = op
+		case 2:
+			_, existed := ref[k]
+			if removed := tree.Delete(id); removed != existed {
+				t.Fatalf("Delete(%d) = %v, want %v", k, removed, existed)
+			}
+			delete(ref, k)
+		}
+		if op%5000 == 0 {
+			tree.check(t)
+		}
+	}
+	tree.check(t)
+
+	if tree.Len() != len(ref) {
+		t.Fatalf("Len() = %d, want %d", tree.Len(), len(ref))
+	}
+	for k, v := range ref {
+		if got, ok := tree.Get(nano64.New(k)); !ok || got != v {
+			t.Fatalf("Get(%d) = %d, %v, want %d", k, got, ok, v)
+		}
+	}
+
+	var keys []uint64
+	tree.Ascend(func(id nano64.Nano64, _ int) bool {
+		keys = append(keys, id.Uint64Value())
+		return true
+	})
+	if len(keys) != len(ref) || !slices.IsSorted(keys) {
+		t.Fatalf("Ascend() visited %d keys (sorted %v), want %d sorted", len(keys), slices.IsSorted(keys), len(ref))
+	}
+
+	for k := range ref {
+		tree.Delete(nano64.New(k))
+	}
+	if tree.Len() != 0 || tree.root != nil {
+		t.Errorf("tree not empty after deleting everything: Len() = %d", tree.Len())
+	}
+}
+
+func TestTree_AscendRange(t *testing.T) {
+	var tree Tree[string]
+	for i := uint64(0); i < 1000; i++ {
+		tree.Put(nano64.New(i*10), "v")
+	}
+
+	var got []uint64
+	tree.AscendRange(nano64.New(95), nano64.New(150), func(id nano64.Nano64, _ string) bool {
+		got = append(got, id.Uint64Value())
+		return true
+	})
+	if want := []uint64{100, 110, 120, 130, 140}; !slices.Equal(got, want) {
+		t.Errorf("AscendRange() = %v, want %v", got, want)
+	}
+
+	got = got[:0]
+	tree.AscendRange(nano64.New(0), nano64.New(10000), func(id nano64.Nano64, _ string) bool {
+		got = append(got, id.Uint64Value())
+		return len(got) < 3
+	})
+	if want := []uint64{0, 10, 20}; !slices.Equal(got, want) {
+		t.Errorf("AscendRange() with early stop = %v, want %v", got, want)
+	}
+
+	if id, _, ok := tree.Min(); !ok || id.Uint64Value() != 0 {
+		t.Errorf("Min() = %d, %v, want 0", id.Uint64Value(), ok)
+	}
+	if id, _, ok := tree.Max(); !ok || id.Uint64Value() != 9990 {
+		t.Errorf("Max() = %d, %v, want 9990", id.Uint64Value(), ok)
+	}
+}
+
+func TestTree_ScanRange(t *testing.T) {
+	base := time.UnixMilli(1_700_000_000_000)
+	tree := New[int]()
+	for i := 0; i < 100; i++ {
+		id, err := nano64.Generate(base.Add(time.Duration(i)*time.Second).UnixMilli(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Put(id, i)
+	}
+	tree.Put(nano64.New(^uint64(0)), -1)
+
+	var got []int
+	tree.ScanRange(base.Add(10*time.Second), base.Add(20*time.Second), func(_ nano64.Nano64, v int) bool {
+		got = append(got, v)
+		return true
+	})
+	if len(got) != 10 || got[0] != 10 || got[9] != 19 {
+		t.Errorf("ScanRange() = %v, want 10..19", got)
+	}
+
+	count := 0
+	tree.ScanRange(time.Time{}, time.UnixMilli(1<<nano64.TimestampBits), func(nano64.Nano64, int) bool {
+		count++
+		return true
+	})
+	if count != 101 {
+		t.Errorf("ScanRange(all) visited %d, want 101", count)
+	}
+
+	var empty Tree[int]
+	empty.ScanRange(base, base.Add(time.Hour), func(nano64.Nano64, int) bool {
+		t.Error("ScanRange() on empty tree called fn")
+		return true
+	})
+	if _, _, ok := empty.Min(); ok {
+		t.Error("Min() on empty tree ok = true")
+	}
+}
@@ -0,0 +1,56 @@
+// Command shortener is a small URL shortener built on nano64. It shows how
+// generators, Base62 codes, SQL storage and encrypted IDs fit together:
+//
+//	POST   /links          {"url": "https://..."} -> code and admin token
+//	GET    /{code}         redirect to the stored URL
+//	GET    /admin/{token}  link details and hit count
+//	DELETE /admin/{token}  delete the link
+//
+// Admin tokens are sealed with the AES key in SHORTENER_KEY (64 hex digits).
+// Without it a random key is used and tokens stop working on restart.
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	dbPath := flag.String("db", "shortener.db", "sqlite database path")
+	flag.Parse()
+
+	key, err := loadKey()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	srv, err := NewServer(db, key, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
+
+func loadKey() ([]byte, error) {
+	if s := os.Getenv("SHORTENER_KEY"); s != "" {
+		return hex.DecodeString(s)
+	}
+	log.Print("SHORTENER_KEY not set, using a random key; admin tokens will not survive a restart")
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	return key, err
+}
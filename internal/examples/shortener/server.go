@@ -0,0 +1,186 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS links (
+	id   BLOB PRIMARY KEY,
+	url  TEXT NOT NULL,
+	hits INTEGER NOT NULL DEFAULT 0
+)`
+
+// Server is the shortener's HTTP API.
+//
+// Each link is keyed by a Nano64 stored as 8 big-endian bytes, so rows sort by
+// creation time. Visitors see the ID as a Base62 code; the link's owner gets
+// an encrypted admin token for the same ID, which reveals nothing about it and
+// cannot be forged without the server's key.
+type Server struct {
+	db     *sql.DB
+	gen    *nano64.Generator
+	tokens *nano64.EncryptedIDConfig
+	mux    *http.ServeMux
+}
+
+// link is the JSON form of a stored link.
+type link struct {
+	Code       string `json:"code"`
+	URL        string `json:"url"`
+	Hits       int64  `json:"hits"`
+	CreatedAt  string `json:"created_at"`
+	AdminToken string `json:"admin_token,omitempty"`
+}
+
+// NewServer creates the links table if needed and returns a server storing
+// links in db and sealing admin tokens with the AES key.
+func NewServer(db *sql.DB, key []byte, gen *nano64.Generator) (*Server, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	tokens, err := nano64.NewEncryptedIDConfig(key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if gen == nil {
+		gen = nano64.NewGenerator()
+	}
+	s := &Server{db: db, gen: gen, tokens: tokens, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /links", s.create)
+	s.mux.HandleFunc("GET /{code}", s.redirect)
+	s.mux.HandleFunc("GET /admin/{token}", s.stats)
+	s.mux.HandleFunc("DELETE /admin/{token}", s.remove)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// create stores the URL in the request body ({"url": "..."}) and returns its
+// code and admin token.
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "url must be an absolute http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	// A duplicate ID is astronomically unlikely, but RetryOnConflict makes
+	// the insert safe without reasoning about it.
+	id, err := nano64.RetryOnConflict(func(id nano64.Nano64) error {
+		_, err := s.db.ExecContext(r.Context(), "INSERT INTO links (id, url) VALUES (?, ?)", id, req.URL)
+		return err
+	}, s.gen, 3)
+	if err != nil {
+		http.Error(w, "failed to store link", http.StatusInternalServerError)
+		return
+	}
+	token, err := s.tokens.Encrypt(id)
+	if err != nil {
+		http.Error(w, "failed to issue admin token", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, link{
+		Code:       id.ToBase62(),
+		URL:        req.URL,
+		CreatedAt:  id.ToDate().Format(time.RFC3339Nano),
+		AdminToken: token.ToEncryptedHex(),
+	})
+}
+
+// redirect sends the visitor to the link's URL and counts the hit.
+func (s *Server) redirect(w http.ResponseWriter, r *http.Request) {
+	id, err := nano64.FromBase62(r.PathValue("code"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	var target string
+	err = s.db.QueryRowContext(r.Context(),
+		"UPDATE links SET hits = hits + 1 WHERE id = ? RETURNING url", id).Scan(&target)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to load link", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// stats returns the link behind an admin token.
+func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.adminID(w, r)
+	if !ok {
+		return
+	}
+	l := link{Code: id.ToBase62(), CreatedAt: id.ToDate().Format(time.RFC3339Nano)}
+	err := s.db.QueryRowContext(r.Context(),
+		"SELECT url, hits FROM links WHERE id = ?", id).Scan(&l.URL, &l.Hits)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to load link", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, l)
+}
+
+// remove deletes the link behind an admin token.
+func (s *Server) remove(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.adminID(w, r)
+	if !ok {
+		return
+	}
+	res, err := s.db.ExecContext(r.Context(), "DELETE FROM links WHERE id = ?", id)
+	if err != nil {
+		http.Error(w, "failed to delete link", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminID decrypts the request's admin token. Tampered, truncated and
+// foreign tokens all get the same 404 so the endpoint leaks nothing.
+func (s *Server) adminID(w http.ResponseWriter, r *http.Request) (nano64.Nano64, bool) {
+	enc, err := s.tokens.FromEncryptedHex(r.PathValue("token"))
+	if errors.Is(err, nano64.ErrAuthFailed) || errors.Is(err, nano64.ErrMalformedPayload) {
+		http.NotFound(w, r)
+		return nano64.Nil, false
+	}
+	if err != nil {
+		http.Error(w, "failed to read admin token", http.StatusInternalServerError)
+		return nano64.Nil, false
+	}
+	return enc.ID, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
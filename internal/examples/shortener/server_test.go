@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "shortener.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	srv, err := NewServer(db, bytes.Repeat([]byte{0x42}, 32), nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestShortener(t *testing.T) {
+	ts := newTestServer(t)
+	client := ts.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	resp, err := client.Post(ts.URL+"/links", "application/json", strings.NewReader(`{"url":"https://example.com/a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created link
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || created.Code == "" || created.AdminToken == "" {
+		t.Fatalf("create: status %d, body %+v", resp.StatusCode, created)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL + "/" + created.Code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "https://example.com/a" {
+			t.Fatalf("redirect: status %d, location %q", resp.StatusCode, resp.Header.Get("Location"))
+		}
+	}
+
+	resp, err = client.Get(ts.URL + "/admin/" + created.AdminToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats link
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if stats.Code != created.Code || stats.Hits != 2 || stats.CreatedAt != created.CreatedAt {
+		t.Errorf("stats = %+v, want code %s with 2 hits", stats, created.Code)
+	}
+
+	// A tampered token must not decrypt.
+	tampered := []byte(created.AdminToken)
+	if tampered[len(tampered)-1] == '0' {
+		tampered[len(tampered)-1] = '1'
+	} else {
+		tampered[len(tampered)-1] = '0'
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/admin/"+string(tampered), nil)
+	if resp, err = client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("delete with tampered token: status %d, want 404", resp.StatusCode)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, ts.URL+"/admin/"+created.AdminToken, nil)
+	if resp, err = client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: status %d, want 204", resp.StatusCode)
+	}
+
+	if resp, err = client.Get(ts.URL + "/" + created.Code); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("redirect after delete: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestShortener_RejectsBadInput(t *testing.T) {
+	ts := newTestServer(t)
+	for _, body := range []string{`not json`, `{"url":"ftp://example.com"}`, `{"url":"/relative"}`} {
+		resp, err := ts.Client().Post(ts.URL+"/links", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST %s: status %d, want 400", body, resp.StatusCode)
+		}
+	}
+	for _, path := range []string{"/not-base62!", "/admin/00", "/admin/" + strings.Repeat("0", 72)} {
+		resp, err := ts.Client().Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package randdist draws from random distributions shared by the simulation
+// and load-generation packages.
+package randdist
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// Poisson draws from a Poisson distribution: Knuth's method for small lambda,
+// a rounded normal approximation for large lambda.
+func Poisson(r *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		n := int(math.Round(lambda + math.Sqrt(lambda)*r.NormFloat64()))
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	limit := math.Exp(-lambda)
+	n := 0
+	for p := r.Float64(); p > limit; p *= r.Float64() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,187 @@
+package nano64
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Quota caps how many IDs a tenant may be issued.
+type Quota struct {
+	// Limit is the number of IDs allowed per window. Zero or negative means unlimited.
+	Limit int64
+
+	// Window is the length of each fixed window, aligned to the Unix epoch
+	// and measured on the generator's clock. Zero makes Limit a lifetime cap.
+	Window time.Duration
+}
+
+// TenantUsage is a snapshot of one tenant's counters.
+type TenantUsage struct {
+	// Issued is the number of IDs issued in the current window.
+	Issued int64
+
+	// Total is the number of IDs issued since the tenant was first seen.
+	Total int64
+
+	// Denied is the number of Issue calls rejected with ErrQuotaExceeded.
+	Denied int64
+
+	// Quota is the quota in force for the tenant.
+	Quota Quota
+}
+
+// IssueTrace describes one Issuer.Issue call.
+type IssueTrace struct {
+	// Tenant is the tenant the ID was requested for.
+	Tenant string
+
+	// Usage is the tenant's counters after the call.
+	Usage TenantUsage
+
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// tenantState holds one tenant's counters; Issuer.mu guards it.
+type tenantState struct {
+	quota  Quota
+	window int64 // start of the current window, Unix ms
+	usage  TenantUsage
+}
+
+// Issuer issues monotonic IDs from a Generator on behalf of tenants, enforcing
+// a per-tenant Quota. Multi-tenant platforms can route every ID-creating
+// operation through one Issuer to cap them centrally. Counters are available
+// from Usage and, per call, through Hooks.OnIssue. It is safe for concurrent use.
+type Issuer struct {
+	gen *Generator
+
+	mu       sync.Mutex
+	fallback Quota
+	tenants  map[string]*tenantState
+}
+
+// NewIssuer returns an Issuer drawing IDs from gen (a default NewGenerator if
+// nil) and applying quota to every tenant without its own.
+func NewIssuer(gen *Generator, quota Quota) *Issuer {
+	if gen == nil {
+		gen = NewGenerator()
+	}
+	return &Issuer{gen: gen, fallback: quota, tenants: make(map[string]*tenantState)}
+}
+
+// SetQuota sets tenant's quota. Its counters for the current window are kept.
+func (i *Issuer) SetQuota(tenant string, quota Quota) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.tenant(tenant).quota = quota
+}
+
+// ClearQuota reverts tenant to the default quota.
+func (i *Issuer) ClearQuota(tenant string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if t, ok := i.tenants[tenant]; ok {
+		t.quota = i.fallback
+	}
+}
+
+// Issue returns a new monotonic ID for tenant, or an error wrapping
+// ErrQuotaExceeded if the tenant has used its quota for the current window.
+// A failed generation does not count against the quota.
+func (i *Issuer) Issue(tenant string) (Nano64, error) {
+	id, usage, err := i.issue(tenant)
+	if h := hooks.Load(); h != nil && h.OnIssue != nil {
+		h.OnIssue(IssueTrace{Tenant: tenant, Usage: usage, Err: err})
+	}
+	return id, err
+}
+
+// issue implements Issue, returning the tenant's counters after the call.
+func (i *Issuer) issue(tenant string) (Nano64, TenantUsage, error) {
+	now := i.gen.now()
+
+	i.mu.Lock()
+	t := i.tenant(tenant)
+	t.roll(now)
+	if q := t.quota; q.Limit > 0 && t.usage.Issued >= q.Limit {
+		t.usage.Denied++
+		usage := t.snapshot()
+		i.mu.Unlock()
+		return Nil, usage, fmt.Errorf("%w: tenant %q has issued %d of %d IDs", ErrQuotaExceeded, tenant, usage.Issued, q.Limit)
+	}
+	// Reserve the slot before generating so concurrent callers cannot overshoot.
+	t.usage.Issued++
+	window := t.window
+	i.mu.Unlock()
+
+	id, err := i.gen.GenerateMonotonic(now)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	switch {
+	case err != nil && t.window == window:
+		t.usage.Issued--
+	case err == nil:
+		t.usage.Total++
+	}
+	return id, t.snapshot(), err
+}
+
+// Usage returns tenant's counters. Unknown tenants report zero usage under
+// the default quota.
+func (i *Issuer) Usage(tenant string) TenantUsage {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	t, ok := i.tenants[tenant]
+	if !ok {
+		return TenantUsage{Quota: i.fallback}
+	}
+	t.roll(i.gen.now())
+	return t.snapshot()
+}
+
+// Tenants returns the counters of every tenant seen so far.
+func (i *Issuer) Tenants() map[string]TenantUsage {
+	now := i.gen.now()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	out := make(map[string]TenantUsage, len(i.tenants))
+	for name, t := range i.tenants {
+		t.roll(now)
+		out[name] = t.snapshot()
+	}
+	return out
+}
+
+// tenant returns tenant's state, creating it under the default quota; the
+// caller holds i.mu.
+func (i *Issuer) tenant(name string) *tenantState {
+	t, ok := i.tenants[name]
+	if !ok {
+		t = &tenantState{quota: i.fallback}
+		i.tenants[name] = t
+	}
+	return t
+}
+
+// roll starts a new window, resetting Issued, once now has passed the current
+// one. A clock stepping backwards never reopens an earlier window.
+func (t *tenantState) roll(now int64) {
+	w := t.quota.Window.Milliseconds()
+	if w <= 0 {
+		return
+	}
+	if start := now - now%w; start > t.window {
+		t.window = start
+		t.usage.Issued = 0
+	}
+}
+
+// snapshot returns the counters with the quota filled in.
+func (t *tenantState) snapshot() TenantUsage {
+	u := t.usage
+	u.Quota = t.quota
+	return u
+}
@@ -0,0 +1,76 @@
+package nano64
+
+import (
+	"fmt"
+	"time"
+)
+
+// WarningCode identifies the kind of problem Lint found.
+type WarningCode string
+
+const (
+	// WarnNil flags the all-zero ID.
+	WarnNil WarningCode = "nil"
+
+	// WarnTooOld flags timestamps before LintEarliest.
+	WarnTooOld WarningCode = "too-old"
+
+	// WarnFuture flags timestamps after now plus the tolerance.
+	WarnFuture WarningCode = "future"
+
+	// WarnSeconds flags timestamps that read as a plausible time when treated as seconds.
+	WarnSeconds WarningCode = "seconds-not-ms"
+
+	// WarnZeroRandom flags an all-zero random field, typical of hand-built IDs.
+	WarnZeroRandom WarningCode = "zero-random"
+)
+
+// LintEarliest is the earliest timestamp Lint considers plausible (2015-01-01 UTC).
+var LintEarliest = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// LintFutureTolerance is how far ahead of now Lint allows timestamps to be.
+const LintFutureTolerance = 5 * time.Minute
+
+// Warning is one finding from Lint.
+type Warning struct {
+	Code    WarningCode
+	Message string
+}
+
+// String returns "code: message".
+func (w Warning) String() string {
+	return string(w.Code) + ": " + w.Message
+}
+
+// Lint checks an ID for values that usually mean it was built by hand or with
+// the wrong time unit. It uses the package default clock and LintFutureTolerance.
+// An empty result means nothing looked suspicious; warnings are not errors.
+func Lint(id Nano64) []Warning {
+	return LintAt(id, time.UnixMilli(now()), LintFutureTolerance)
+}
+
+// LintAt is Lint with an explicit current time and future tolerance.
+func LintAt(id Nano64, at time.Time, tolerance time.Duration) []Warning {
+	if id.IsNil() {
+		return []Warning{{Code: WarnNil, Message: "ID is the zero value"}}
+	}
+
+	var warnings []Warning
+	ts := id.GetTimestamp()
+	earliest := LintEarliest.UnixMilli()
+	latest := at.Add(tolerance).UnixMilli()
+	switch {
+	case ts < earliest:
+		warnings = append(warnings, Warning{WarnTooOld, fmt.Sprintf("timestamp %s is before %s", id.ToDate().Format(time.RFC3339), LintEarliest.Format("2006-01-02"))})
+		if ts*1000 >= earliest && ts*1000 <= latest {
+			warnings = append(warnings, Warning{WarnSeconds, fmt.Sprintf("timestamp %d reads as %s if taken as seconds", ts, time.Unix(ts, 0).UTC().Format(time.RFC3339))})
+		}
+	case ts > latest:
+		warnings = append(warnings, Warning{WarnFuture, fmt.Sprintf("timestamp %s is more than %v ahead of %s", id.ToDate().Format(time.RFC3339), tolerance, at.UTC().Format(time.RFC3339))})
+	}
+
+	if id.GetRandom() == 0 {
+		warnings = append(warnings, Warning{WarnZeroRandom, "random field is zero"})
+	}
+	return warnings
+}
@@ -0,0 +1,182 @@
+// Package loadgen produces Nano64 ID streams shaped like production traffic,
+// for load-testing storage keyed by Nano64.
+//
+// A faucet walks a virtual timeline one millisecond at a time. Each millisecond
+// draws a Poisson-distributed number of IDs around the target rate, with
+// optional bursts and per-ID clock skew. IDs are sent on an unbuffered channel,
+// so a slow consumer throttles the faucet instead of growing a queue.
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.codycody31.dev/nano64"
+	"go.codycody31.dev/nano64/internal/randdist"
+)
+
+// burstProbability is the share of milliseconds that carry a burst.
+const burstProbability = 0.05
+
+// Option configures a faucet.
+type Option func(*config)
+
+type config struct {
+	ctx      context.Context
+	start    int64
+	burst    float64
+	skew     int64
+	seed     uint64
+	seeded   bool
+	realtime bool
+}
+
+// WithContext stops the faucet when ctx is cancelled.
+func WithContext(ctx context.Context) Option {
+	return func(c *config) { c.ctx = ctx }
+}
+
+// WithStart sets the first millisecond of the virtual timeline (default: now).
+func WithStart(ms int64) Option {
+	return func(c *config) { c.start = ms }
+}
+
+// WithBurst makes roughly 5% of milliseconds carry factor times the mean rate,
+// with the remaining milliseconds scaled down to keep the overall rate. A factor
+// of 1 or less disables bursts.
+func WithBurst(factor float64) Option {
+	return func(c *config) { c.burst = factor }
+}
+
+// WithClockSkew offsets each ID's timestamp by a uniform random amount in
+// [-max, +max], simulating producers whose clocks disagree.
+func WithClockSkew(max time.Duration) Option {
+	return func(c *config) { c.skew = max.Milliseconds() }
+}
+
+// WithSeed makes the stream reproducible.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+		c.seeded = true
+	}
+}
+
+// WithRealtime paces the faucet to the wall clock so duration is real time.
+// Without it the faucet emits as fast as the consumer reads.
+func WithRealtime() Option {
+	return func(c *config) { c.realtime = true }
+}
+
+// Stream is a running faucet.
+type Stream struct {
+	// C receives the generated IDs and is closed when the faucet finishes.
+	C <-chan nano64.Nano64
+
+	mu   sync.Mutex
+	sent uint64
+	err  error
+}
+
+// Sent returns the number of IDs delivered so far.
+func (s *Stream) Sent() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent
+}
+
+// Err returns the error that stopped the faucet early, if any.
+// Only meaningful after C is closed.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Faucet starts producing about rate IDs per second over duration of virtual time.
+func Faucet(rate int, duration time.Duration, opts ...Option) *Stream {
+	cfg := config{
+		ctx:   context.Background(),
+		start: time.Now().UnixMilli(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !cfg.seeded {
+		cfg.seed = rand.Uint64()
+	}
+
+	ch := make(chan nano64.Nano64)
+	s := &Stream{C: ch}
+	go s.run(ch, cfg, rate, duration.Milliseconds())
+	return s
+}
+
+func (s *Stream) run(ch chan<- nano64.Nano64, cfg config, rate int, millis int64) {
+	defer close(ch)
+
+	r := rand.New(rand.NewPCG(cfg.seed, cfg.seed^0x9E3779B97F4A7C15))
+	rng := func(bits int) (uint32, error) {
+		return r.Uint32() & uint32(1<<bits-1), nil
+	}
+
+	mean := float64(rate) / 1000
+	burstMean, calmMean := mean, mean
+	if cfg.burst > 1 {
+		burstMean = mean * cfg.burst
+		calmMean = math.Max(0, mean*(1-burstProbability*cfg.burst)/(1-burstProbability))
+	}
+
+	wallStart := time.Now()
+	for ms := int64(0); ms < millis; ms++ {
+		if cfg.realtime {
+			if wait := time.Until(wallStart.Add(time.Duration(ms) * time.Millisecond)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-cfg.ctx.Done():
+					s.fail(cfg.ctx.Err())
+					return
+				}
+			}
+		}
+
+		lambda := calmMean
+		if cfg.burst > 1 && r.Float64() < burstProbability {
+			lambda = burstMean
+		}
+
+		for n := randdist.Poisson(r, lambda); n > 0; n-- {
+			ts := cfg.start + ms
+			if cfg.skew > 0 {
+				ts += r.Int64N(2*cfg.skew+1) - cfg.skew
+			}
+			if ts < 0 {
+				ts = 0
+			}
+			id, err := nano64.Generate(ts, rng)
+			if err != nil {
+				s.fail(fmt.Errorf("failed to generate ID: %w", err))
+				return
+			}
+			select {
+			case ch <- id:
+				s.mu.Lock()
+				s.sent++
+				s.mu.Unlock()
+			case <-cfg.ctx.Done():
+				s.fail(cfg.ctx.Err())
+				return
+			}
+		}
+	}
+}
+
+func (s *Stream) fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
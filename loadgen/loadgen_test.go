@@ -0,0 +1,107 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func drain(s *Stream) []nano64.Nano64 {
+	var ids []nano64.Nano64
+	for id := range s.C {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestFaucet_Rate(t *testing.T) {
+	const start = 1_700_000_000_000
+	s := Faucet(50_000, 2*time.Second, WithStart(start), WithSeed(1))
+	ids := drain(s)
+
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if uint64(len(ids)) != s.Sent() {
+		t.Errorf("Sent() = %d, want %d", s.Sent(), len(ids))
+	}
+	if len(ids) < 95_000 || len(ids) > 105_000 {
+		t.Errorf("got %d IDs, want about 100000", len(ids))
+	}
+	for i, id := range ids {
+		ts := id.GetTimestamp()
+		if ts < start || ts >= start+2000 {
+			t.Fatalf("ids[%d] timestamp %d outside [%d, %d)", i, ts, start, start+2000)
+		}
+		if i > 0 && ts < ids[i-1].GetTimestamp() {
+			t.Fatalf("ids[%d] timestamp went backwards without skew", i)
+		}
+	}
+}
+
+func TestFaucet_Reproducible(t *testing.T) {
+	a := drain(Faucet(5000, 100*time.Millisecond, WithStart(1000), WithSeed(7), WithBurst(10)))
+	b := drain(Faucet(5000, 100*time.Millisecond, WithStart(1000), WithSeed(7), WithBurst(10)))
+	if len(a) != len(b) {
+		t.Fatalf("lengths differ: %d vs %d", len(a), len(b))
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			t.Fatalf("ids[%d] differ: %s vs %s", i, a[i].ToHex(), b[i].ToHex())
+		}
+	}
+}
+
+func TestFaucet_Burst(t *testing.T) {
+	perMs := map[int64]int{}
+	for _, id := range drain(Faucet(10_000, time.Second, WithStart(0), WithSeed(3), WithBurst(15))) {
+		perMs[id.GetTimestamp()]++
+	}
+	peak := 0
+	for _, n := range perMs {
+		peak = max(peak, n)
+	}
+	if peak < 50 {
+		t.Errorf("peak per-ms count = %d, want bursts well above the mean of 10", peak)
+	}
+}
+
+func TestFaucet_ClockSkew(t *testing.T) {
+	const start = 1_000_000
+	backwards := false
+	ids := drain(Faucet(20_000, 200*time.Millisecond, WithStart(start), WithSeed(5), WithClockSkew(50*time.Millisecond)))
+	for i, id := range ids {
+		ts := id.GetTimestamp()
+		if ts < start-50 || ts >= start+200+50 {
+			t.Fatalf("ids[%d] timestamp %d outside skew window", i, ts)
+		}
+		if i > 0 && ts < ids[i-1].GetTimestamp() {
+			backwards = true
+		}
+	}
+	if !backwards {
+		t.Error("clock skew produced a perfectly ordered stream")
+	}
+}
+
+func TestFaucet_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := Faucet(1000, time.Hour, WithContext(ctx), WithSeed(9))
+	<-s.C
+	cancel()
+	for range s.C {
+	}
+	if s.Err() != context.Canceled {
+		t.Errorf("Err() = %v, want %v", s.Err(), context.Canceled)
+	}
+}
+
+func TestFaucet_Realtime(t *testing.T) {
+	begin := time.Now()
+	drain(Faucet(1000, 50*time.Millisecond, WithRealtime(), WithSeed(11)))
+	if elapsed := time.Since(begin); elapsed < 40*time.Millisecond {
+		t.Errorf("realtime faucet finished in %v, want about 50ms", elapsed)
+	}
+}
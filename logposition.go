@@ -0,0 +1,130 @@
+package nano64
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// LogPosition is a position in an append-only log, backed by a monotonic Nano64.
+// Positions handed out by NextLogPosition and Next, or by one Generator's
+// NextLogPosition and NextFrom, are strictly increasing within a process, so
+// they can be used directly as event offsets.
+// The zero value is LogStart, which precedes every generated position.
+type LogPosition Nano64
+
+// LogStart is the position before the first entry of any log.
+var LogStart = LogPosition{}
+
+// NextLogPosition returns a fresh position from the package monotonic generator.
+func NextLogPosition() (LogPosition, error) {
+	id, err := GenerateMonotonicDefault()
+	if err != nil {
+		return LogStart, fmt.Errorf("failed to generate log position: %w", err)
+	}
+	return LogPosition(id), nil
+}
+
+// Next returns a position strictly after p from the package monotonic
+// generator. If the clock or a restart would place the new position at or
+// before p, the generator is first advanced past p, so appends resumed from a
+// persisted position never go backwards and later NextLogPosition calls stay
+// after the result.
+func (p LogPosition) Next() (LogPosition, error) {
+	monotonic.raise(p.value)
+	return NextLogPosition()
+}
+
+// NextLogPosition returns a fresh position from the generator's monotonic
+// sequence. Event logs should use a generator in strict mode
+// (WithStrictMonotonic), so a clock stepping back fails the append with
+// ErrClockRegression instead of borrowing future milliseconds.
+func (g *Generator) NextLogPosition() (LogPosition, error) {
+	id, err := g.GenerateMonotonicNow()
+	if err != nil {
+		return LogStart, fmt.Errorf("failed to generate log position: %w", err)
+	}
+	return LogPosition(id), nil
+}
+
+// NextFrom is Next for positions drawn from g: it advances g past p, then
+// returns g.NextLogPosition(). In strict mode a p far ahead of g's clock
+// fails with ErrClockRegression rather than borrowing future milliseconds.
+func (p LogPosition) NextFrom(g *Generator) (LogPosition, error) {
+	g.state.raise(g.statePosition(p.value))
+	return g.NextLogPosition()
+}
+
+// ID returns the underlying Nano64.
+func (p LogPosition) ID() Nano64 {
+	return Nano64(p)
+}
+
+// Compare orders positions. Returns -1, 0 or 1.
+func (p LogPosition) Compare(other LogPosition) int {
+	return Compare(Nano64(p), Nano64(other))
+}
+
+// Before reports whether p precedes other.
+func (p LogPosition) Before(other LogPosition) bool {
+	return p.value < other.value
+}
+
+// After reports whether p follows other.
+func (p LogPosition) After(other LogPosition) bool {
+	return p.value > other.value
+}
+
+// Distance returns the time between the two positions' timestamps (other - p).
+// Positions are not dense, so this is a measure of log lag rather than an entry count.
+func (p LogPosition) Distance(other LogPosition) time.Duration {
+	return time.Duration(Nano64(other).GetTimestamp()-Nano64(p).GetTimestamp()) * time.Millisecond
+}
+
+// ToBytes returns the 8-byte big-endian form for persistence.
+func (p LogPosition) ToBytes() []byte {
+	return Nano64(p).ToBytes()
+}
+
+// FromLogPositionBytes restores a position persisted with ToBytes.
+func FromLogPositionBytes(bytes []byte) (LogPosition, error) {
+	id, err := FromBytes(bytes)
+	if err != nil {
+		return LogStart, fmt.Errorf("invalid log position: %w", err)
+	}
+	return LogPosition(id), nil
+}
+
+// String returns the canonical hex form.
+func (p LogPosition) String() string {
+	return Nano64(p).ToHex()
+}
+
+// ParseLogPosition restores a position persisted with String.
+func ParseLogPosition(s string) (LogPosition, error) {
+	id, err := FromHex(s)
+	if err != nil {
+		return LogStart, fmt.Errorf("invalid log position: %w", err)
+	}
+	return LogPosition(id), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p LogPosition) MarshalJSON() ([]byte, error) {
+	return Nano64(p).MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *LogPosition) UnmarshalJSON(data []byte) error {
+	return (*Nano64)(p).UnmarshalJSON(data)
+}
+
+// Value implements the driver.Valuer interface.
+func (p LogPosition) Value() (driver.Value, error) {
+	return Nano64(p).Value()
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *LogPosition) Scan(value interface{}) error {
+	return (*Nano64)(p).Scan(value)
+}
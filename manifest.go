@@ -0,0 +1,172 @@
+package nano64
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ManifestVersion is the current manifest format version.
+const ManifestVersion = 1
+
+// Layout describes how an ID's 64 bits are allocated and what its timestamp is relative to.
+type Layout struct {
+	// TimestampBits is the width of the millisecond timestamp field.
+	TimestampBits int `json:"timestamp_bits"`
+
+	// RandomBits is the width of the random field, including any node bits.
+	RandomBits int `json:"random_bits"`
+
+	// NodeBits is the number of high random-field bits reserved for a node ID.
+	NodeBits int `json:"node_bits"`
+
+	// Epoch is the Unix millisecond the timestamp field counts from.
+	Epoch int64 `json:"epoch_ms"`
+}
+
+// Policies describes how a generator reacts to edge conditions.
+type Policies struct {
+	// Overflow is the behaviour when the per-millisecond monotonic sequence is exhausted.
+	Overflow string `json:"overflow"`
+
+	// ClockRegression is the behaviour when the clock moves backwards during monotonic generation.
+	ClockRegression string `json:"clock_regression"`
+}
+
+const (
+	// OverflowAdvance borrows the next millisecond when the sequence is exhausted.
+	OverflowAdvance = "advance"
+
+	// OverflowWait sleeps until the clock reaches the next millisecond when the
+	// sequence is exhausted, so no ID carries a timestamp ahead of the clock.
+	OverflowWait = "wait"
+
+	// ClockRegressionClamp reuses the last timestamp when the clock moves backwards.
+	ClockRegressionClamp = "clamp"
+
+	// ClockRegressionError fails with ErrClockRegression when the clock moves
+	// backwards beyond the generator's tolerance.
+	ClockRegressionError = "error"
+)
+
+// Manifest describes an ID scheme: layout, epoch, node bits and policies.
+// It never contains secrets. Services exchange manifests to verify at startup
+// that every node generates IDs the same way.
+type Manifest struct {
+	Version  int      `json:"version"`
+	Layout   Layout   `json:"layout"`
+	Policies Policies `json:"policies"`
+}
+
+// DefaultLayout returns the layout used by the package-level functions.
+func DefaultLayout() Layout {
+	return Layout{
+		TimestampBits: TimestampBits,
+		RandomBits:    RandomBits,
+		Epoch:         DefaultEpoch(),
+	}
+}
+
+// CurrentManifest returns the manifest describing the package-level generation functions.
+func CurrentManifest() Manifest {
+	return Manifest{
+		Version: ManifestVersion,
+		Layout:  DefaultLayout(),
+		Policies: Policies{
+			Overflow:        OverflowAdvance,
+			ClockRegression: ClockRegressionClamp,
+		},
+	}
+}
+
+// Validate checks that the manifest describes a scheme this package can produce.
+func (m Manifest) Validate() error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return m.Layout.Validate()
+}
+
+// Validate checks that the layout fits in 64 bits.
+func (l Layout) Validate() error {
+	if l.TimestampBits != TimestampBits || l.RandomBits != RandomBits {
+		return fmt.Errorf("layout must be %d timestamp + %d random bits, got %d + %d",
+			TimestampBits, RandomBits, l.TimestampBits, l.RandomBits)
+	}
+	if l.NodeBits < 0 || l.NodeBits >= l.RandomBits {
+		return fmt.Errorf("node bits must be 0-%d, got %d", l.RandomBits-1, l.NodeBits)
+	}
+	if l.Epoch < 0 {
+		return fmt.Errorf("epoch cannot be negative: %d", l.Epoch)
+	}
+	return nil
+}
+
+// Marshal encodes the manifest as JSON.
+func (m Manifest) Marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// ParseManifest decodes and validates a JSON manifest.
+func ParseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return Manifest{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Fingerprint returns a short, stable hash of the manifest (16 lowercase hex chars).
+// Two manifests with the same fingerprint describe the same scheme.
+func (m Manifest) Fingerprint() string {
+	data, err := m.Marshal()
+	if err != nil {
+		// Manifest contains only ints and strings; marshalling cannot fail.
+		panic(fmt.Sprintf("nano64: failed to marshal manifest: %v", err))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SchemeFingerprint returns the fingerprint of CurrentManifest, suitable for
+// logging at startup or publishing alongside a service's health endpoint.
+func SchemeFingerprint() string {
+	return CurrentManifest().Fingerprint()
+}
+
+// VerifyCompatibility reports whether IDs generated under remote interleave
+// correctly with IDs generated under m. Only the version and layout (bit widths,
+// node bits and epoch) are compared; policies may differ between services.
+func (m Manifest) VerifyCompatibility(remote Manifest) error {
+	var diffs []string
+	if m.Version != remote.Version {
+		diffs = append(diffs, fmt.Sprintf("version %d != %d", m.Version, remote.Version))
+	}
+	if m.Layout.TimestampBits != remote.Layout.TimestampBits {
+		diffs = append(diffs, fmt.Sprintf("timestamp bits %d != %d", m.Layout.TimestampBits, remote.Layout.TimestampBits))
+	}
+	if m.Layout.RandomBits != remote.Layout.RandomBits {
+		diffs = append(diffs, fmt.Sprintf("random bits %d != %d", m.Layout.RandomBits, remote.Layout.RandomBits))
+	}
+	if m.Layout.NodeBits != remote.Layout.NodeBits {
+		diffs = append(diffs, fmt.Sprintf("node bits %d != %d", m.Layout.NodeBits, remote.Layout.NodeBits))
+	}
+	if m.Layout.Epoch != remote.Layout.Epoch {
+		diffs = append(diffs, fmt.Sprintf("epoch %d != %d", m.Layout.Epoch, remote.Layout.Epoch))
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("incompatible ID scheme: %s", strings.Join(diffs, ", "))
+	}
+	return nil
+}
+
+// VerifyCompatibility checks remote against CurrentManifest. Services can call
+// it at startup with the cluster's manifest and refuse to start on error.
+func VerifyCompatibility(remote Manifest) error {
+	return CurrentManifest().VerifyCompatibility(remote)
+}
@@ -0,0 +1,238 @@
+// Package merkle builds Merkle trees over sets of Nano64 IDs for anti-entropy
+// synchronization: two nodes compare roots, then walk down only the subtrees
+// whose hashes differ to find the time ranges they must exchange, instead of
+// shipping full ID lists.
+//
+// Every tree has the same shape regardless of content. IDs are bucketed by
+// timestamp: each leaf covers 2^BucketBits ms (about 65 s), and leaves sit
+// under a fixed-depth binary trie keyed by the remaining timestamp bits. Empty subtrees hash to the zero hash and are not
+// stored, so memory is proportional to the number of non-empty buckets.
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+
+	"go.codycody31.dev/nano64"
+)
+
+const (
+	// BucketBits is the number of low timestamp bits covered by one leaf;
+	// each leaf spans 2^16 ms (about 65 s).
+	BucketBits = 16
+
+	// Depth is the number of levels above the leaves.
+	Depth = nano64.TimestampBits - BucketBits
+
+	// bucketShift converts an ID value to its bucket number.
+	bucketShift = BucketBits + nano64.RandomBits
+)
+
+// Hash is a SHA-256 node hash. The zero Hash denotes an empty subtree.
+type Hash [sha256.Size]byte
+
+// Range is an inclusive span of IDs whose contents differ between two trees.
+type Range struct {
+	From, To nano64.Nano64
+}
+
+// String formats the range as "FROM..TO".
+func (r Range) String() string {
+	return r.From.ToHex() + ".." + r.To.ToHex()
+}
+
+// Source serves node hashes of a tree, so DiffRanges can walk a peer's tree
+// over the network, one request per node, without holding it locally. *Tree
+// implements Source.
+type Source interface {
+	Node(level int, key uint64) Hash
+}
+
+// Tree is an immutable Merkle tree over a set of IDs.
+type Tree struct {
+	// levels[0] holds leaf hashes keyed by bucket; levels[Depth] holds the root under key 0.
+	levels  [Depth + 1]map[uint64]Hash
+	buckets map[uint64][]nano64.Nano64
+}
+
+// Build returns the tree for ids. The input may be unsorted and contain
+// duplicates; it is not modified.
+func Build(ids []nano64.Nano64) *Tree {
+	sorted := slices.Clone(ids)
+	nano64.SortIDs(sorted)
+	sorted = slices.Compact(sorted)
+
+	t := &Tree{buckets: make(map[uint64][]nano64.Nano64)}
+	for i := range t.levels {
+		t.levels[i] = make(map[uint64]Hash)
+	}
+	for start := 0; start < len(sorted); {
+		bucket := bucketOf(sorted[start])
+		end := start + 1
+		for end < len(sorted) && bucketOf(sorted[end]) == bucket {
+			end++
+		}
+		t.buckets[bucket] = sorted[start:end:end]
+		t.levels[0][bucket] = leafHash(sorted[start:end])
+		start = end
+	}
+	for level := 1; level <= Depth; level++ {
+		for key := range t.levels[level-1] {
+			parent := key >> 1
+			if _, done := t.levels[level][parent]; done {
+				continue
+			}
+			t.levels[level][parent] = nodeHash(t.Node(level-1, parent<<1), t.Node(level-1, parent<<1|1))
+		}
+	}
+	return t
+}
+
+// Root returns the root hash. Equal sets have equal roots.
+func (t *Tree) Root() Hash {
+	return t.Node(Depth, 0)
+}
+
+// Node returns the hash of the subtree at level (0 for leaves, Depth for the
+// root) with the given key, the bucket number shifted right by level. Remote
+// peers can serve Node to walk a tree over the network.
+func (t *Tree) Node(level int, key uint64) Hash {
+	return t.levels[level][key]
+}
+
+// Len returns the number of distinct IDs in the tree.
+func (t *Tree) Len() int {
+	n := 0
+	for _, ids := range t.buckets {
+		n += len(ids)
+	}
+	return n
+}
+
+// DiffRanges returns the ID ranges whose contents differ between t and
+// other, oldest first, with adjacent differing buckets merged. It only
+// descends into subtrees whose hashes differ, so other is asked for at most
+// two nodes per level for each differing bucket.
+func (t *Tree) DiffRanges(other Source) []Range {
+	var buckets []uint64
+	var walk func(level int, key uint64)
+	walk = func(level int, key uint64) {
+		if t.Node(level, key) == other.Node(level, key) {
+			return
+		}
+		if level == 0 {
+			buckets = append(buckets, key)
+			return
+		}
+		walk(level-1, key<<1)
+		walk(level-1, key<<1|1)
+	}
+	walk(Depth, 0)
+
+	var ranges []Range
+	for _, b := range buckets {
+		from, to := bucketRange(b)
+		if n := len(ranges); n > 0 && ranges[n-1].To.Uint64Value()+1 == from.Uint64Value() {
+			ranges[n-1].To = to
+			continue
+		}
+		ranges = append(ranges, Range{From: from, To: to})
+	}
+	return ranges
+}
+
+// Proof shows that an ID belongs to a tree with a given root.
+type Proof struct {
+	// ID is the proven ID.
+	ID nano64.Nano64
+
+	// Bucket holds every ID in ID's leaf, ascending.
+	Bucket []nano64.Nano64
+
+	// Siblings holds the sibling hash at each level, leaf level first.
+	Siblings [Depth]Hash
+}
+
+// Proof returns an inclusion proof for id, or an error if id is not in the tree.
+func (t *Tree) Proof(id nano64.Nano64) (Proof, error) {
+	bucket := bucketOf(id)
+	ids := t.buckets[bucket]
+	if _, found := slices.BinarySearchFunc(ids, id, nano64.Compare); !found {
+		return Proof{}, fmt.Errorf("id %s not in tree", id.ToHex())
+	}
+	p := Proof{ID: id, Bucket: slices.Clone(ids)}
+	key := bucket
+	for level := 0; level < Depth; level++ {
+		p.Siblings[level] = t.Node(level, key^1)
+		key >>= 1
+	}
+	return p, nil
+}
+
+// Verify reports whether the proof shows p.ID is in the tree with the given root.
+func (p Proof) Verify(root Hash) bool {
+	if len(p.Bucket) == 0 || !slices.IsSortedFunc(p.Bucket, nano64.Compare) {
+		return false
+	}
+	bucket := bucketOf(p.ID)
+	found := false
+	for _, id := range p.Bucket {
+		if bucketOf(id) != bucket {
+			return false
+		}
+		found = found || id == p.ID
+	}
+	if !found {
+		return false
+	}
+
+	h := leafHash(p.Bucket)
+	key := bucket
+	for _, sibling := range p.Siblings {
+		if key&1 == 0 {
+			h = nodeHash(h, sibling)
+		} else {
+			h = nodeHash(sibling, h)
+		}
+		key >>= 1
+	}
+	return h == root
+}
+
+// bucketOf returns the leaf bucket number for id.
+func bucketOf(id nano64.Nano64) uint64 {
+	return id.Uint64Value() >> bucketShift
+}
+
+// bucketRange returns the smallest and largest IDs in bucket.
+func bucketRange(bucket uint64) (nano64.Nano64, nano64.Nano64) {
+	from := bucket << bucketShift
+	return nano64.New(from), nano64.New(from | (1<<bucketShift - 1))
+}
+
+// leafHash hashes a leaf's ascending IDs, domain-separated from interior nodes.
+func leafHash(ids []nano64.Nano64) Hash {
+	h := sha256.New()
+	h.Write([]byte{0})
+	for _, id := range ids {
+		h.Write(id.ToBytes())
+	}
+	var out Hash
+	h.Sum(out[:0])
+	return out
+}
+
+// nodeHash combines two child hashes. Two empty children give an empty parent.
+func nodeHash(left, right Hash) Hash {
+	if left == (Hash{}) && right == (Hash{}) {
+		return Hash{}
+	}
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left[:])
+	h.Write(right[:])
+	var out Hash
+	h.Sum(out[:0])
+	return out
+}
@@ -0,0 +1,159 @@
+package merkle
+
+import (
+	"slices"
+	"testing"
+
+	"go.codycody31.dev/nano64"
+)
+
+const base = int64(1760000000000)
+
+func generateIDs(t *testing.T, n int, spread int64) []nano64.Nano64 {
+	t.Helper()
+	ids := make([]nano64.Nano64, n)
+	for i := range ids {
+		id, err := nano64.Generate(base+int64(i)*spread/int64(n), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func TestBuild_RootIsOrderIndependent(t *testing.T) {
+	ids := generateIDs(t, 1000, 3_600_000)
+	a := Build(ids)
+
+	shuffled := slices.Clone(ids)
+	slices.Reverse(shuffled)
+	shuffled = append(shuffled, ids[:10]...)
+	b := Build(shuffled)
+
+	if a.Root() != b.Root() {
+		t.Error("Root() differs for the same set in a different order")
+	}
+	if a.Len() != 1000 {
+		t.Errorf("Len() = %d, want 1000", a.Len())
+	}
+	if a.Root() == (Hash{}) {
+		t.Error("Root() of a non-empty tree is the zero hash")
+	}
+	if Build(nil).Root() != (Hash{}) {
+		t.Error("Root() of an empty tree is not the zero hash")
+	}
+	if len(a.DiffRanges(b)) != 0 {
+		t.Error("DiffRanges() between equal trees is not empty")
+	}
+}
+
+func TestDiffRanges(t *testing.T) {
+	ids := generateIDs(t, 2000, 24*3_600_000)
+	a := Build(ids)
+
+	missing := ids[500]
+	extra := nano64.New(ids[1500].Uint64Value() + 1)
+	changed := append(slices.Delete(slices.Clone(ids), 500, 501), extra)
+	b := Build(changed)
+
+	if a.Root() == b.Root() {
+		t.Fatal("Root() equal for different sets")
+	}
+	ranges := a.DiffRanges(b)
+	if len(ranges) != 2 {
+		t.Fatalf("DiffRanges() = %v, want 2 ranges", ranges)
+	}
+	for i, id := range []nano64.Nano64{missing, extra} {
+		r := ranges[i]
+		if nano64.Compare(id, r.From) < 0 || nano64.Compare(id, r.To) > 0 {
+			t.Errorf("range %s does not contain %s", r, id.ToHex())
+		}
+		if span := r.To.GetTimestamp() - r.From.GetTimestamp() + 1; span != 1<<BucketBits {
+			t.Errorf("range %s spans %d ms, want one bucket", r, span)
+		}
+	}
+
+	// Reconciling only the reported ranges makes the trees equal.
+	var synced []nano64.Nano64
+	for _, id := range changed {
+		if !inRanges(id, ranges) {
+			synced = append(synced, id)
+		}
+	}
+	for _, id := range ids {
+		if inRanges(id, ranges) {
+			synced = append(synced, id)
+		}
+	}
+	if Build(synced).Root() != a.Root() {
+		t.Error("syncing the reported ranges did not converge")
+	}
+}
+
+// countingSource stands in for a remote peer, recording how many nodes are fetched.
+type countingSource struct {
+	tree  *Tree
+	calls int
+}
+
+func (s *countingSource) Node(level int, key uint64) Hash {
+	s.calls++
+	return s.tree.Node(level, key)
+}
+
+func TestDiffRanges_Source(t *testing.T) {
+	ids := generateIDs(t, 2000, 24*3_600_000)
+	a := Build(ids)
+	b := Build(ids[1:])
+
+	remote := &countingSource{tree: b}
+	ranges := a.DiffRanges(remote)
+	if !slices.Equal(ranges, a.DiffRanges(b)) {
+		t.Errorf("DiffRanges(source) = %v, want %v", ranges, a.DiffRanges(b))
+	}
+	if len(ranges) != 1 || !inRanges(ids[0], ranges) {
+		t.Errorf("DiffRanges(source) = %v, want one range containing %s", ranges, ids[0].ToHex())
+	}
+	// One differing bucket: the root, then both children at every level below.
+	if want := 1 + 2*Depth; remote.calls != want {
+		t.Errorf("DiffRanges(source) fetched %d nodes, want %d", remote.calls, want)
+	}
+}
+
+func inRanges(id nano64.Nano64, ranges []Range) bool {
+	for _, r := range ranges {
+		if nano64.Compare(id, r.From) >= 0 && nano64.Compare(id, r.To) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProof(t *testing.T) {
+	ids := generateIDs(t, 500, 600_000)
+	tree := Build(ids)
+	root := tree.Root()
+
+	for _, id := range []nano64.Nano64{ids[0], ids[250], ids[499]} {
+		p, err := tree.Proof(id)
+		if err != nil {
+			t.Fatalf("Proof(%s) error = %v", id.ToHex(), err)
+		}
+		if !p.Verify(root) {
+			t.Errorf("Proof(%s).Verify() = false", id.ToHex())
+		}
+		forged := p
+		forged.ID = nano64.New(id.Uint64Value() + 1)
+		if forged.Verify(root) {
+			t.Error("Verify() accepted a proof for a different ID")
+		}
+		if p.Verify(Build(ids[1:]).Root()) {
+			t.Error("Verify() accepted a proof against another root")
+		}
+	}
+
+	if _, err := tree.Proof(nano64.New(ids[0].Uint64Value() + 1)); err == nil {
+		t.Error("Proof() of a missing ID error = nil, want error")
+	}
+}
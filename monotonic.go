@@ -0,0 +1,131 @@
+package nano64
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// errSequenceExhausted is returned by advance under OverflowWait when the next
+// ID would have to borrow a millisecond the clock has not reached yet.
+var errSequenceExhausted = errors.New("per-millisecond sequence exhausted")
+
+// monotonicPolicy configures how a monotonicState advances.
+type monotonicPolicy struct {
+	// seqBits is the width of the per-ms sequence in the low random bits;
+	// any bits above it are reserved for a node ID.
+	seqBits int
+
+	// overflow selects what happens when the sequence of the current ms
+	// wraps: OverflowAdvance moves on to the next ms, OverflowWait returns
+	// errSequenceExhausted if that ms is still ahead of the timestamp.
+	overflow string
+
+	// strict rejects timestamps more than tolerance ms behind the last
+	// issued one with ErrClockRegression instead of clamping them.
+	strict    bool
+	tolerance int64
+}
+
+// defaultMonotonicPolicy is the policy of the package-level functions.
+var defaultMonotonicPolicy = monotonicPolicy{seqBits: RandomBits, overflow: OverflowAdvance}
+
+// monotonicState is the last issued monotonic position packed into one word,
+// timestamp<<seqBits | sequence, so concurrent generators advance it with
+// compare-and-swap instead of serializing on a lock. Zero means nothing has
+// been issued yet; the zero value is ready to use.
+type monotonicState struct {
+	last     atomic.Uint64
+	pressure pressureMeter
+}
+
+// advance returns the next monotonic ID at or after timestamp and records it
+// in the pressure meter. Only the low policy.seqBits of the random field are
+// used; callers reserving the high bits for a node ID fill them in afterwards.
+// Callers validate timestamp. Time spent retrying lost compare-and-swaps is
+// reported as trace.LockWait.
+func (s *monotonicState) advance(timestamp int64, policy monotonicPolicy, rng RNG, trace *GenerateTrace) (Nano64, error) {
+	seqBits := policy.seqBits
+	seqMask := uint64(1)<<seqBits - 1
+
+	var (
+		random    uint64
+		drawn     bool
+		contended time.Time
+		next      uint64
+	)
+	for {
+		old := s.last.Load()
+		lastTs := int64(old >> seqBits)
+		if old == 0 {
+			lastTs = -1
+		}
+		if policy.strict && timestamp < lastTs-policy.tolerance {
+			return Nano64{}, fmt.Errorf("%w: timestamp %d is %d ms behind the last issued %d",
+				ErrClockRegression, timestamp, lastTs-timestamp, lastTs)
+		}
+
+		if timestamp > lastTs {
+			// First ID in this newer ms. The draw survives lost races, so
+			// contention never costs more than one RNG call.
+			if !drawn {
+				rngStart := traceStart(trace != nil)
+				randVal, err := rng(seqBits)
+				if trace != nil {
+					trace.RNGWait = traceSince(rngStart)
+				}
+				if err != nil {
+					return Nano64{}, fmt.Errorf("failed to generate random value: %w", err)
+				}
+				random, drawn = uint64(randVal)&seqMask, true
+			}
+			next = uint64(timestamp)<<seqBits | random
+			if next == 0 {
+				// Zero marks the empty state, so the very first ms starts at 1.
+				next = 1
+			}
+		} else {
+			// Same or earlier ms → increment. A wrapping sequence carries
+			// into the timestamp, moving to the next ms at sequence 0.
+			next = old + 1
+			if next == 0 || int64(next>>seqBits) > maxTimestamp {
+				return Nano64{}, fmt.Errorf("timestamp overflow after incrementing for monotonic generation")
+			}
+			if policy.overflow == OverflowWait && next&seqMask == 0 && int64(next>>seqBits) > timestamp {
+				return Nano64{}, errSequenceExhausted
+			}
+		}
+
+		if s.last.CompareAndSwap(old, next) {
+			break
+		}
+		if contended.IsZero() {
+			contended = traceStart(trace != nil)
+		}
+	}
+	if trace != nil {
+		trace.LockWait = traceSince(contended)
+	}
+
+	t := int64(next >> seqBits)
+	s.pressure.record(t)
+	return Nano64{value: uint64(t)<<timestampShift | next&seqMask}, nil
+}
+
+// raise moves the state forward to at least packed (timestamp<<seqBits |
+// sequence), so the next ID advance returns comes after it.
+func (s *monotonicState) raise(packed uint64) {
+	for {
+		old := s.last.Load()
+		if old >= packed || s.last.CompareAndSwap(old, packed) {
+			return
+		}
+	}
+}
+
+// reset forgets all issued IDs.
+func (s *monotonicState) reset() {
+	s.last.Store(0)
+	s.pressure.reset()
+}
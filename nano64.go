@@ -5,9 +5,11 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -48,38 +50,45 @@ type Nano64 struct {
 	value uint64
 }
 
-var (
-	// lastTimestamp is used by GenerateMonotonic to track the last used timestamp.
-	lastTimestamp int64 = -1
-
-	// lastRandom is used by GenerateMonotonic to track the last used random value.
-	lastRandom uint64
-
-	// monotonicMutex protects the monotonic generation state.
-	monotonicMutex sync.Mutex
-)
+// monotonic is the state shared by the package-level GenerateMonotonic functions.
+var monotonic monotonicState
 
 // DefaultRNG provides a cryptographically-secure RNG using crypto/rand.
 // Returns an unsigned integer with exactly `bits` bits of entropy.
+// Bytes are read from crypto/rand in chunks and handed out from a pool of
+// per-processor buffers, so most calls make no system call; use
+// UnbufferedRNG to read crypto/rand on every call instead.
 func DefaultRNG(bits int) (uint32, error) {
 	if bits <= 0 || bits > 32 {
 		return 0, fmt.Errorf("bits must be 1-32, got %d", bits)
 	}
+	val, err := pooledUint32()
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return maskBits(val, bits), nil
+}
 
-	// Generate 4 bytes for simplicity
-	buf := make([]byte, 4)
-	if _, err := rand.Read(buf); err != nil {
+// UnbufferedRNG is DefaultRNG without the entropy pool: every call reads 4
+// bytes straight from crypto/rand, so no random bytes are held in memory
+// between calls. It is slower on platforms where crypto/rand is a system call.
+func UnbufferedRNG(bits int) (uint32, error) {
+	if bits <= 0 || bits > 32 {
+		return 0, fmt.Errorf("bits must be 1-32, got %d", bits)
+	}
+	var buf [4]byte
+	if _, err := rand.Read(buf[:]); err != nil {
 		return 0, fmt.Errorf("failed to generate random bytes: %w", err)
 	}
+	return maskBits(binary.BigEndian.Uint32(buf[:]), bits), nil
+}
 
-	// Convert to uint32 and mask to requested bits
-	val := binary.BigEndian.Uint32(buf)
+// maskBits keeps the low bits (1-32) of val.
+func maskBits(val uint32, bits int) uint32 {
 	if bits == 32 {
-		return val, nil
+		return val
 	}
-
-	mask := uint32((1 << bits) - 1)
-	return val & mask, nil
+	return val & (1<<bits - 1)
 }
 
 // DefaultClock returns the current time in Unix milliseconds.
@@ -179,6 +188,117 @@ func (n *NullNano64) UnmarshalJSON(data []byte) error {
 	return n.ID.UnmarshalJSON(data)
 }
 
+// NullFromHex parses s with FromHex into a valid NullNano64. The empty string
+// yields an invalid (NULL) one.
+func NullFromHex(s string) (NullNano64, error) {
+	if s == "" {
+		return NullNano64{}, nil
+	}
+	id, err := FromHex(s)
+	if err != nil {
+		return NullNano64{}, err
+	}
+	return NullNano64{ID: id, Valid: true}, nil
+}
+
+// Hex returns ID.ToHex(), or the empty string if n is NULL.
+func (n NullNano64) Hex() string {
+	if !n.Valid {
+		return ""
+	}
+	return n.ID.ToHex()
+}
+
+// String returns ID.String(), or "NULL" if n is NULL.
+func (n NullNano64) String() string {
+	if !n.Valid {
+		return "NULL"
+	}
+	return n.ID.String()
+}
+
+// Equal reports whether n and other are both NULL, or both valid with equal IDs.
+func (n NullNano64) Equal(other NullNano64) bool {
+	return n.Compare(other) == 0
+}
+
+// Compare compares n and other like Compare, with NULL sorting before every
+// valid ID, including Nil. Returns -1, 0 or 1.
+func (n NullNano64) Compare(other NullNano64) int {
+	switch {
+	case !n.Valid && !other.Valid:
+		return 0
+	case !n.Valid:
+		return -1
+	case !other.Valid:
+		return 1
+	}
+	return Compare(n.ID, other.ID)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for NullNano64.
+// NULL encodes as empty text.
+func (n NullNano64) MarshalText() ([]byte, error) {
+	if !n.Valid {
+		return []byte{}, nil
+	}
+	return n.ID.MarshalText()
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for
+// NullNano64. Empty text decodes as NULL.
+func (n *NullNano64) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		n.ID = Nil
+		n.Valid = false
+		return nil
+	}
+	n.Valid = true
+	return n.ID.UnmarshalText(text)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// Encodes the Nano64 in the canonical ToHex form, which also makes it usable
+// as a JSON object key and with flag.TextVar.
+func (n Nano64) MarshalText() ([]byte, error) {
+	return n.AppendHex(make([]byte, 0, 17)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Accepts the hex forms FromHex accepts.
+func (n *Nano64) UnmarshalText(text []byte) error {
+	parsed, err := FromHex(string(text))
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// Encodes the Nano64 as 8 big-endian bytes, the ToBytes form, for gob, Redis
+// clients and other binary codecs.
+func (n Nano64) MarshalBinary() ([]byte, error) {
+	return n.ToBytes(), nil
+}
+
+// AppendBinary implements the encoding.BinaryAppender interface, appending
+// the 8 big-endian bytes to dst.
+func (n Nano64) AppendBinary(dst []byte) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(dst, n.value), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// Requires exactly 8 big-endian bytes.
+func (n *Nano64) UnmarshalBinary(data []byte) error {
+	parsed, err := FromBytes(data)
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 // Encodes the Nano64 as a hex string in JSON.
 func (n Nano64) MarshalJSON() ([]byte, error) {
@@ -186,31 +306,115 @@ func (n Nano64) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
-// Accepts either a hex string or a numeric value from JSON.
+// Accepts a hex string, a JSON number, or a decimal number wrapped in a string
+// (as emitted by encoders that stringify 64-bit integers). Numbers may use
+// scientific notation if they denote an exact integer; values outside the
+// unsigned 64-bit range are rejected. Strings that are valid hex are always
+// read as hex.
 func (n *Nano64) UnmarshalJSON(data []byte) error {
 	// Try to unmarshal as string first (hex format)
-	var hexStr string
-	if err := json.Unmarshal(data, &hexStr); err == nil {
-		parsed, err := FromHex(hexStr)
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		parsed, hexErr := FromHex(str)
+		if hexErr == nil {
+			*n = parsed
+			return nil
+		}
+		if !looksNumeric(str) {
+			return fmt.Errorf("failed to parse hex string: %w", hexErr)
+		}
+		value, err := parseDecimalID(str)
 		if err != nil {
-			return fmt.Errorf("failed to parse hex string: %w", err)
+			return err
 		}
-		*n = parsed
+		*n = Nano64{value: value}
 		return nil
 	}
 
 	// Try to unmarshal as number
-	var num uint64
+	var num json.Number
 	if err := json.Unmarshal(data, &num); err != nil {
 		return fmt.Errorf("failed to unmarshal Nano64: expected hex string or number")
 	}
-	*n = Nano64{value: num}
+	value, err := parseDecimalID(num.String())
+	if err != nil {
+		return err
+	}
+	*n = Nano64{value: value}
 	return nil
 }
 
+// looksNumeric reports whether s only contains characters of a JSON number.
+func looksNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	// maxDecimalIDLength bounds the numbers parseDecimalID accepts.
+	maxDecimalIDLength = 64
+
+	// maxDecimalIDExponent bounds the exponents parseDecimalID accepts.
+	maxDecimalIDExponent = 128
+)
+
+// parseDecimalID parses a decimal or scientific-notation number that must be an
+// exact unsigned 64-bit integer.
+func parseDecimalID(s string) (uint64, error) {
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err == nil {
+		return value, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("number %s overflows unsigned 64-bit ID", s)
+	}
+
+	// big.Rat materializes 10^exp, so bound the input before handing it over:
+	// "1e1000000" would otherwise cost tens of milliseconds of CPU. No exact
+	// uint64 needs a longer number or a larger exponent.
+	if len(s) > maxDecimalIDLength {
+		return 0, fmt.Errorf("number too long for an ID: %d chars", len(s))
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxDecimalIDExponent || exp < -maxDecimalIDExponent {
+			return 0, fmt.Errorf("number %s has an exponent out of range for an ID", s)
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	if !r.IsInt() {
+		return 0, fmt.Errorf("number %s is not an integer", s)
+	}
+	if r.Sign() < 0 {
+		return 0, fmt.Errorf("number %s is negative", s)
+	}
+	if !r.Num().IsUint64() {
+		return 0, fmt.Errorf("number %s overflows unsigned 64-bit ID", s)
+	}
+	return r.Num().Uint64(), nil
+}
+
 // GetTimestamp extracts the embedded UNIX-epoch milliseconds from the ID.
-// Returns integer milliseconds in range [0, 2^44-1].
+// Returns integer milliseconds in range [0, 2^44-1], shifted by the default
+// epoch when one is set with SetDefaultEpoch.
 func (n Nano64) GetTimestamp() int64 {
+	return n.rawTimestamp() + DefaultEpoch()
+}
+
+// rawTimestamp returns the 44-bit timestamp field, before adding any epoch.
+func (n Nano64) rawTimestamp() int64 {
 	return int64((n.value >> timestampShift) & timestampMask)
 }
 
@@ -219,6 +423,16 @@ func (n Nano64) GetRandom() uint32 {
 	return uint32(n.value & randomMask)
 }
 
+// GetNodeID returns the node ID embedded in the top bits of the random field
+// by a generator configured with WithNodeID(id, bits).
+// Panics unless bits is 1-(RandomBits-1).
+func (n Nano64) GetNodeID(bits int) uint32 {
+	if bits < 1 || bits >= RandomBits {
+		panic(fmt.Sprintf("nano64: node bits must be 1-%d, got %d", RandomBits-1, bits))
+	}
+	return n.GetRandom() >> (RandomBits - bits)
+}
+
 // ToDate builds a time.Time from the embedded timestamp.
 func (n Nano64) ToDate() time.Time {
 	return time.UnixMilli(n.GetTimestamp())
@@ -227,6 +441,33 @@ func (n Nano64) ToDate() time.Time {
 // Generate creates an ID with a given or current timestamp.
 // Random field is filled with DefaultRNG(20) bits of entropy.
 func Generate(timestamp int64, rng RNG) (Nano64, error) {
+	h := hooks.Load()
+	if h == nil || h.OnGenerate == nil {
+		return generateUnix(timestamp, rng, nil)
+	}
+
+	var trace GenerateTrace
+	start := time.Now()
+	id, err := generateUnix(timestamp, rng, &trace)
+	trace.Total = time.Since(start)
+	trace.Err = err
+	h.OnGenerate(trace)
+	return id, err
+}
+
+// generateUnix implements Generate, converting timestamp from Unix ms to the
+// default epoch.
+func generateUnix(timestamp int64, rng RNG, trace *GenerateTrace) (Nano64, error) {
+	timestamp, err := fromUnix(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
+	return generate(timestamp, rng, trace)
+}
+
+// generate creates an ID from a timestamp already relative to the epoch,
+// filling trace timings when trace is non-nil.
+func generate(timestamp int64, rng RNG, trace *GenerateTrace) (Nano64, error) {
 	if timestamp < 0 {
 		return Nano64{}, fmt.Errorf("timestamp cannot be negative: %d", timestamp)
 	}
@@ -235,10 +476,14 @@ func Generate(timestamp int64, rng RNG) (Nano64, error) {
 	}
 
 	if rng == nil {
-		rng = DefaultRNG
+		rng = packageRNG()
 	}
 
+	rngStart := traceStart(trace != nil)
 	randVal, err := rng(RandomBits)
+	if trace != nil {
+		trace.RNGWait = traceSince(rngStart)
+	}
 	if err != nil {
 		return Nano64{}, fmt.Errorf("failed to generate random value: %w", err)
 	}
@@ -250,19 +495,52 @@ func Generate(timestamp int64, rng RNG) (Nano64, error) {
 	return Nano64{value: value}, nil
 }
 
-// GenerateNow creates an ID with the current timestamp using DefaultClock.
+// GenerateNow creates an ID with the current timestamp using DefaultClock,
+// or the clock installed with SetDefaultClock.
 func GenerateNow(rng RNG) (Nano64, error) {
-	return Generate(DefaultClock(), rng)
+	return Generate(now(), rng)
 }
 
 // GenerateDefault creates an ID with the current timestamp and default RNG.
 func GenerateDefault() (Nano64, error) {
-	return GenerateNow(DefaultRNG)
+	return GenerateNow(packageRNG())
+}
+
+// MustGenerate is like GenerateDefault but panics on error. It is intended
+// for tests, fixtures and package-level variables; production code should
+// handle the error from GenerateDefault.
+func MustGenerate() Nano64 {
+	id, err := GenerateDefault()
+	if err != nil {
+		panic(fmt.Sprintf("nano64: %v", err))
+	}
+	return id
 }
 
 // GenerateMonotonic creates monotonic IDs. Nondecreasing across calls in one process.
 // If the per-ms sequence wraps, the timestamp is bumped by 1 ms and the random field resets to 0.
+// It is lock-free, so concurrent callers scale across cores.
 func GenerateMonotonic(timestamp int64, rng RNG) (Nano64, error) {
+	h := hooks.Load()
+	if h == nil || h.OnGenerate == nil {
+		return generateMonotonic(timestamp, rng, nil)
+	}
+
+	trace := GenerateTrace{Monotonic: true}
+	start := time.Now()
+	id, err := generateMonotonic(timestamp, rng, &trace)
+	trace.Total = time.Since(start)
+	trace.Err = err
+	h.OnGenerate(trace)
+	return id, err
+}
+
+// generateMonotonic implements GenerateMonotonic, filling trace timings when trace is non-nil.
+func generateMonotonic(timestamp int64, rng RNG, trace *GenerateTrace) (Nano64, error) {
+	timestamp, err := fromUnix(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
 	if timestamp < 0 {
 		return Nano64{}, fmt.Errorf("timestamp cannot be negative: %d", timestamp)
 	}
@@ -271,59 +549,20 @@ func GenerateMonotonic(timestamp int64, rng RNG) (Nano64, error) {
 	}
 
 	if rng == nil {
-		rng = DefaultRNG
-	}
-
-	monotonicMutex.Lock()
-	defer monotonicMutex.Unlock()
-
-	// Enforce nondecreasing time
-	t := timestamp
-	if t < lastTimestamp {
-		t = lastTimestamp
-	}
-
-	var random uint64
-	if t == lastTimestamp {
-		// Same ms → increment
-		random = (lastRandom + 1) & randomMask
-		if random == 0 {
-			// Per-ms space exhausted → move to next ms and start at 0
-			t++
-			if t > maxTimestamp {
-				return Nano64{}, fmt.Errorf("timestamp overflow after incrementing for monotonic generation")
-			}
-			lastTimestamp = t
-			lastRandom = 0
-			ms := uint64(t) & timestampMask
-			value := ms << timestampShift
-			return Nano64{value: value}, nil
-		}
-	} else {
-		// First ID in this newer ms
-		randVal, err := rng(RandomBits)
-		if err != nil {
-			return Nano64{}, fmt.Errorf("failed to generate random value: %w", err)
-		}
-		random = uint64(randVal) & randomMask
+		rng = packageRNG()
 	}
 
-	lastTimestamp = t
-	lastRandom = random
-
-	ms := uint64(t) & timestampMask
-	value := (ms << timestampShift) | random
-	return Nano64{value: value}, nil
+	return monotonic.advance(timestamp, defaultMonotonicPolicy, rng, trace)
 }
 
 // GenerateMonotonicNow creates a monotonic ID with the current timestamp.
 func GenerateMonotonicNow(rng RNG) (Nano64, error) {
-	return GenerateMonotonic(DefaultClock(), rng)
+	return GenerateMonotonic(now(), rng)
 }
 
 // GenerateMonotonicDefault creates a monotonic ID with current timestamp and default RNG.
 func GenerateMonotonicDefault() (Nano64, error) {
-	return GenerateMonotonicNow(DefaultRNG)
+	return GenerateMonotonicNow(packageRNG())
 }
 
 // Compare compares two IDs as unsigned 64-bit numbers.
@@ -355,10 +594,9 @@ func (n Nano64) String() string {
 
 // ToHex returns uppercase 16-char hex encoding of the u64, with a dash between timestamp and random parts.
 func (n Nano64) ToHex() string {
-	full := fmt.Sprintf("%016X", n.value)
-	// Split 44-bit (11 hex digits) timestamp + 20-bit (5 hex digits) random = 16 hex total
-	const split = 11 // ceil(44 / 4)
-	return full[:split] + "-" + full[split:]
+	// 44-bit (11 hex digits) timestamp + "-" + 20-bit (5 hex digits) random.
+	var buf [17]byte
+	return string(n.AppendHex(buf[:0]))
 }
 
 // ToBytes returns 8-byte big-endian encoding of the u64.
@@ -370,29 +608,41 @@ func (n Nano64) ToBytes() []byte {
 // Accepts uppercase or lowercase, optional `0x` prefix.
 func FromHex(hexStr string) (Nano64, error) {
 	clean := strings.ReplaceAll(hexStr, "-", "")
+	prefix := 0
 	if strings.HasPrefix(clean, "0x") || strings.HasPrefix(clean, "0X") {
 		clean = clean[2:]
+		prefix = 2
 	}
 
 	if len(clean) != 16 {
-		return Nano64{}, fmt.Errorf("hex must be 16 chars after removing dash, got %d", len(clean))
+		return Nano64{}, &ParseError{Input: hexStr, Offset: -1, Expected: "16 hex digits"}
 	}
 
-	bytes, err := Hex.ToBytes(clean)
-	if err != nil {
-		return Nano64{}, fmt.Errorf("invalid hex: %w", err)
+	var value uint64
+	for i := 0; i < len(clean); i++ {
+		d, ok := hexDigit(clean[i], true)
+		if !ok {
+			// Report the position in the original input, which may contain dashes.
+			return Nano64{}, &ParseError{Input: hexStr, Offset: undashedOffset(hexStr, prefix+i), Expected: "hex digit"}
+		}
+		value = value<<4 | uint64(d)
 	}
 
-	if len(bytes) != 8 {
-		return Nano64{}, fmt.Errorf("hex must decode to 8 bytes, got %d", len(bytes))
-	}
+	return Nano64{value: value}, nil
+}
 
-	value, err := BigIntHelpers.FromBytesBE(bytes)
-	if err != nil {
-		return Nano64{}, fmt.Errorf("failed to parse bytes: %w", err)
+// undashedOffset returns the byte offset in s of its n-th (0-based) non-dash character.
+func undashedOffset(s string, n int) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '-' {
+			continue
+		}
+		if n == 0 {
+			return i
+		}
+		n--
 	}
-
-	return Nano64{value: value}, nil
+	return len(s)
 }
 
 // FromBytes parses from 8 big-endian bytes.
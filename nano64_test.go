@@ -1,12 +1,26 @@
 package nano64
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -127,6 +141,92 @@ func TestNano64_ToHex(t *testing.T) {
 	}
 }
 
+func TestNano64_AppendHex(t *testing.T) {
+	id := New(0x123456789ABCDEF0)
+	buf := []byte("id=")
+	if got := string(id.AppendHex(buf)); got != "id=123456789AB-CDEF0" {
+		t.Errorf("AppendHex() = %q", got)
+	}
+	text, err := id.AppendText(nil)
+	if err != nil || string(text) != id.ToHex() {
+		t.Errorf("AppendText() = %q, %v; want %q", text, err, id.ToHex())
+	}
+
+	dst := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() { dst = id.AppendHex(dst[:0]) }); allocs != 0 {
+		t.Errorf("AppendHex() allocated %v times, want 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { _ = id.ToHex() }); allocs > 1 {
+		t.Errorf("ToHex() allocated %v times, want at most 1", allocs)
+	}
+}
+
+func TestNano64_TextMarshaler(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	text, err := id.MarshalText()
+	if err != nil || string(text) != "199C01B6659-5861C" {
+		t.Fatalf("MarshalText() = %q, %v", text, err)
+	}
+	var got Nano64
+	if err := got.UnmarshalText(text); err != nil || got != id {
+		t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, got, err, id)
+	}
+	var pe *ParseError
+	if err := got.UnmarshalText([]byte("not-an-id")); !errors.As(err, &pe) {
+		t.Errorf("UnmarshalText(invalid) error = %v, want *ParseError", err)
+	}
+
+	// JSON object keys go through the text interfaces.
+	m := map[Nano64]int{id: 1}
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"199C01B6659-5861C":1}` {
+		t.Fatalf("json.Marshal(map) = %s, %v", data, err)
+	}
+	var back map[Nano64]int
+	if err := json.Unmarshal(data, &back); err != nil || back[id] != 1 {
+		t.Errorf("json.Unmarshal(map) = %v, %v", back, err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var flagID Nano64
+	fs.TextVar(&flagID, "id", Nil, "ID")
+	if err := fs.Parse([]string{"-id", "199C01B66595861C"}); err != nil || flagID != id {
+		t.Errorf("flag.TextVar parsed %v, %v; want %v", flagID, err, id)
+	}
+}
+
+func TestNano64_BinaryMarshaler(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	data, err := id.MarshalBinary()
+	if err != nil || !bytes.Equal(data, id.ToBytes()) {
+		t.Fatalf("MarshalBinary() = %x, %v; want %x", data, err, id.ToBytes())
+	}
+	if data, _ := id.AppendBinary([]byte{0xFF}); !bytes.Equal(data, append([]byte{0xFF}, id.ToBytes()...)) {
+		t.Errorf("AppendBinary() = %x", data)
+	}
+	var got Nano64
+	if err := got.UnmarshalBinary(data); err != nil || got != id {
+		t.Errorf("UnmarshalBinary() = %v, %v; want %v", got, err, id)
+	}
+	if err := got.UnmarshalBinary(data[:7]); err == nil {
+		t.Error("UnmarshalBinary() of 7 bytes should fail")
+	}
+
+	// gob picks up the binary interfaces, including for struct fields.
+	type record struct {
+		ID   Nano64
+		Name string
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record{ID: id, Name: "a"}); err != nil {
+		t.Fatalf("gob Encode() error = %v", err)
+	}
+	var rec record
+	if err := gob.NewDecoder(&buf).Decode(&rec); err != nil || rec.ID != id || rec.Name != "a" {
+		t.Errorf("gob Decode() = %+v, %v", rec, err)
+	}
+}
+
 func TestNano64_FromHex(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -473,6 +573,15 @@ func TestNano64_UnmarshalJSON(t *testing.T) {
 		{"numeric zero", `0`, 0, false},
 		{"numeric small", `12345`, 12345, false},
 		{"numeric large", `1311768467463790320`, 0x123456789ABCDEF0, false},
+		{"decimal string", `"1311768467463790320"`, 0x123456789ABCDEF0, false},
+		{"decimal string max", `"18446744073709551615"`, ^uint64(0), false},
+		{"decimal string overflow", `"18446744073709551616"`, 0, true},
+		{"scientific number", `1.31176846746379032e18`, 0x123456789ABCDEF0, false},
+		{"scientific string", `"1e3"`, 1000, false},
+		{"scientific fraction", `1.5e0`, 0, true},
+		{"numeric overflow", `18446744073709551616`, 0, true},
+		{"scientific overflow", `1e20`, 0, true},
+		{"negative number", `-1`, 0, true},
 		{"invalid hex", `"ZZZZ"`, 0, true},
 		{"invalid type", `true`, 0, true},
 		{"invalid object", `{}`, 0, true},
@@ -493,6 +602,29 @@ func TestNano64_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestNano64_UnmarshalJSON_HugeExponent(t *testing.T) {
+	inputs := []string{`1e1000000`, `1e-1000000`, `"1e1000000"`, `1E+999999999`, `0.` + strings.Repeat("0", 100) + `1e101`}
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		for _, in := range inputs {
+			var id Nano64
+			if err := json.Unmarshal([]byte(in), &id); err == nil {
+				t.Fatalf("UnmarshalJSON(%.20s) error = nil, want error", in)
+			}
+		}
+	}
+	// Unbounded, each 1e1000000 costs tens of milliseconds.
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rejecting huge exponents took %v", elapsed)
+	}
+
+	// Exponents that can still give an exact ID keep working.
+	var id Nano64
+	if err := json.Unmarshal([]byte(`0.000000000000000000001e40`), &id); err != nil || id.Uint64Value() != 1e19 {
+		t.Errorf("UnmarshalJSON(small mantissa, large exponent) = %d, %v", id.Uint64Value(), err)
+	}
+}
+
 func TestNano64_JSON_Roundtrip(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -554,6 +686,143 @@ func BenchmarkGenerateMonotonic(b *testing.B) {
 	}
 }
 
+// BenchmarkGenerateMonotonicParallel measures monotonic generation under
+// contention; compare it with BenchmarkGenerateMonotonicParallelLocked, which
+// serializes the same state on a mutex as earlier versions did.
+func BenchmarkGenerateMonotonicParallel(b *testing.B) {
+	var state monotonicState
+	benchmarkMonotonicParallel(b, func(ts int64, rng RNG) (Nano64, error) {
+		return state.advance(ts, defaultMonotonicPolicy, rng, nil)
+	})
+}
+
+func BenchmarkGenerateMonotonicParallelLocked(b *testing.B) {
+	var (
+		mu    sync.Mutex
+		state monotonicState
+	)
+	benchmarkMonotonicParallel(b, func(ts int64, rng RNG) (Nano64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return state.advance(ts, defaultMonotonicPolicy, rng, nil)
+	})
+}
+
+func benchmarkMonotonicParallel(b *testing.B, advance func(int64, RNG) (Nano64, error)) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := advance(time.Now().UnixMilli(), DefaultRNG); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestGenerateMonotonic_Concurrent(t *testing.T) {
+	var state monotonicState
+	zero := func(int) (uint32, error) { return 0, nil }
+
+	const workers, perWorker = 8, 1 << 18 // 2M IDs: wraps the 20-bit sequence
+	ids := make([][]Nano64, workers)
+	var wg sync.WaitGroup
+	for w := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[w] = make([]Nano64, perWorker)
+			for i := range ids[w] {
+				id, err := state.advance(5_000, defaultMonotonicPolicy, zero, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids[w][i] = id
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[Nano64]bool, workers*perWorker)
+	for _, list := range ids {
+		for i, id := range list {
+			if i > 0 && Compare(list[i-1], id) >= 0 {
+				t.Fatalf("IDs from one goroutine not increasing: %s then %s", list[i-1].ToHex(), id.ToHex())
+			}
+			if seen[id] {
+				t.Fatalf("duplicate ID %s", id.ToHex())
+			}
+			seen[id] = true
+		}
+	}
+	if got, want := state.last.Load(), uint64(5_000)<<RandomBits+workers*perWorker-1; got != want {
+		t.Errorf("final state = %#x, want %#x", got, want)
+	}
+}
+
+func TestFromUnsafe(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	if got := FromBytesUnsafe(id.ToBytes()); got != id {
+		t.Errorf("FromBytesUnsafe() = %s, want %s", got.ToHex(), id.ToHex())
+	}
+	for _, s := range []string{"199C01B6659-5861C", "199C01B66595861C", "199c01b6659-5861c", "199C-01B6-6595-861C"} {
+		if got := FromHexUnsafe(s); got != id {
+			t.Errorf("FromHexUnsafe(%q) = %s, want %s", s, got.ToHex(), id.ToHex())
+		}
+	}
+	if got := FromHexUnsafe("FFFFFFFFFFF-FFFFF"); got.Uint64Value() != ^uint64(0) {
+		t.Errorf("FromHexUnsafe(max) = %s", got.ToHex())
+	}
+}
+
+func BenchmarkFromBytes(b *testing.B) {
+	data := New(0x199C01B66595861C).ToBytes()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromBytesUnsafe(b *testing.B) {
+	data := New(0x199C01B66595861C).ToBytes()
+	for i := 0; i < b.N; i++ {
+		FromBytesUnsafe(data)
+	}
+}
+
+func BenchmarkToHex(b *testing.B) {
+	id := New(0x199C01B66595861C)
+	for i := 0; i < b.N; i++ {
+		_ = id.ToHex()
+	}
+}
+
+func BenchmarkAppendHex(b *testing.B) {
+	id := New(0x199C01B66595861C)
+	buf := make([]byte, 0, 17)
+	for i := 0; i < b.N; i++ {
+		buf = id.AppendHex(buf[:0])
+	}
+}
+
+func BenchmarkFromHex(b *testing.B) {
+	s := New(0x199C01B66595861C).ToHex()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromHex(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromHexUnsafe(b *testing.B) {
+	s := New(0x199C01B66595861C).ToHex()
+	for i := 0; i < b.N; i++ {
+		FromHexUnsafe(s)
+	}
+}
+
 // setupTestDB creates a temporary SQLite database for testing.
 func setupTestDB(t *testing.T) (*sql.DB, func()) {
 	t.Helper()
@@ -997,10 +1266,7 @@ func TestNano64_FromUint64(t *testing.T) {
 // TestNano64_GenerateMonotonicNow tests GenerateMonotonicNow
 func TestNano64_GenerateMonotonicNow(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = -1
-	lastRandom = 0
-	monotonicMutex.Unlock()
+	monotonic.reset()
 
 	id1, err := GenerateMonotonicNow(nil)
 	if err != nil {
@@ -1020,10 +1286,7 @@ func TestNano64_GenerateMonotonicNow(t *testing.T) {
 // TestNano64_GenerateMonotonicDefault tests GenerateMonotonicDefault
 func TestNano64_GenerateMonotonicDefault(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = -1
-	lastRandom = 0
-	monotonicMutex.Unlock()
+	monotonic.reset()
 
 	id, err := GenerateMonotonicDefault()
 	if err != nil {
@@ -1125,6 +1388,57 @@ func TestEncryptedNano64_GenerateEncryptedZeroTimestamp(t *testing.T) {
 	}
 }
 
+// TestEncryptedIDConfig_WithGenerator tests sharing a monotonic sequence with plain generation
+func TestEncryptedIDConfig_WithGenerator(t *testing.T) {
+	key := make([]byte, 32)
+	frozen := func() int64 { return 1_700_000_000_000 }
+	gen := NewGenerator(WithClock(frozen))
+	config, err := NewEncryptedIDConfig(key, func() int64 { return 42 }, nil, WithGenerator(gen))
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+
+	// Plain and encrypted IDs interleave on one strictly increasing sequence,
+	// even with the clock frozen.
+	var prev Nano64
+	for i := 0; i < 100; i++ {
+		var id Nano64
+		if i%2 == 0 {
+			id, err = gen.GenerateMonotonicNow()
+		} else {
+			var enc *EncryptedNano64
+			enc, err = config.GenerateEncryptedNow()
+			if enc != nil {
+				id = enc.ID
+			}
+		}
+		if err != nil {
+			t.Fatalf("generation %d error = %v", i, err)
+		}
+		if id.GetTimestamp() < frozen() {
+			t.Fatalf("generation %d used timestamp %d, want the generator's clock", i, id.GetTimestamp())
+		}
+		if Compare(id, prev) <= 0 {
+			t.Fatalf("generation %d: %s not after %s", i, id.ToHex(), prev.ToHex())
+		}
+		prev = id
+	}
+
+	enc, err := config.GenerateEncrypted(0)
+	if err != nil || Compare(enc.ID, prev) <= 0 {
+		t.Errorf("GenerateEncrypted(0) = %v, %v, want an ID after %s", enc, err, prev.ToHex())
+	}
+
+	// A nil generator restores independent generation from the config's clock.
+	plain, err := NewEncryptedIDConfig(key, func() int64 { return 42 }, nil, WithGenerator(gen), WithGenerator(nil))
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	if enc, err := plain.GenerateEncryptedNow(); err != nil || enc.ID.GetTimestamp() != 42 {
+		t.Errorf("GenerateEncryptedNow() without a generator = %v, %v", enc, err)
+	}
+}
+
 // TestEncryptedNano64_Encrypt tests encrypting an existing ID
 func TestEncryptedNano64_Encrypt(t *testing.T) {
 	key := make([]byte, 32)
@@ -1166,8 +1480,8 @@ func TestEncryptedNano64_Errors(t *testing.T) {
 		}
 
 		_, err = config.FromEncryptedBytes([]byte{0x01, 0x02, 0x03})
-		if err == nil {
-			t.Errorf("FromEncryptedBytes() with wrong length should error")
+		if !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("FromEncryptedBytes() with wrong length error = %v, want ErrMalformedPayload", err)
 		}
 	})
 
@@ -1179,8 +1493,8 @@ func TestEncryptedNano64_Errors(t *testing.T) {
 		}
 
 		_, err = config.FromEncryptedHex("INVALID")
-		if err == nil {
-			t.Errorf("FromEncryptedHex() with invalid hex should error")
+		if !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("FromEncryptedHex() with invalid hex error = %v, want ErrMalformedPayload", err)
 		}
 	})
 
@@ -1192,8 +1506,8 @@ func TestEncryptedNano64_Errors(t *testing.T) {
 		}
 
 		_, err = config.FromEncryptedHex("AABBCCDD")
-		if err == nil {
-			t.Errorf("FromEncryptedHex() with wrong length should error")
+		if !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("FromEncryptedHex() with wrong length error = %v, want ErrMalformedPayload", err)
 		}
 	})
 
@@ -1214,8 +1528,35 @@ func TestEncryptedNano64_Errors(t *testing.T) {
 		bytes[20] ^= 0xFF
 
 		_, err = config.FromEncryptedBytes(bytes)
-		if err == nil {
-			t.Errorf("FromEncryptedBytes() with tampered data should error")
+		if !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("FromEncryptedBytes() with tampered data error = %v, want ErrAuthFailed", err)
+		}
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+		if err != nil {
+			t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+		}
+		other, err := NewEncryptedIDConfig(bytes.Repeat([]byte{1}, 32), nil, nil)
+		if err != nil {
+			t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+		}
+
+		encrypted, err := config.GenerateEncryptedNow()
+		if err != nil {
+			t.Fatalf("GenerateEncryptedNow() error = %v", err)
+		}
+		payload := encrypted.ToEncryptedBytes()
+
+		if err := config.Verify(payload); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+		if err := other.Verify(payload); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("Verify() under another key error = %v, want ErrAuthFailed", err)
+		}
+		if err := config.Verify(payload[:10]); !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("Verify() with a short payload error = %v, want ErrMalformedPayload", err)
 		}
 	})
 }
@@ -1271,10 +1612,7 @@ func TestEncryptedNano64_DifferentKeySizes(t *testing.T) {
 // TestGenerateMonotonic_Overflow tests overflow handling in monotonic generation
 func TestGenerateMonotonic_Overflow(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = maxTimestamp
-	lastRandom = randomMask // Max random value
-	monotonicMutex.Unlock()
+	monotonic.last.Store(uint64(maxTimestamp)<<RandomBits | randomMask) // Max random value
 
 	// This should cause an overflow
 	_, err := GenerateMonotonic(maxTimestamp, nil)
@@ -1286,10 +1624,7 @@ func TestGenerateMonotonic_Overflow(t *testing.T) {
 // TestGenerateMonotonic_BackwardsTime tests monotonic generation with backwards time
 func TestGenerateMonotonic_BackwardsTime(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = 1000000
-	lastRandom = 100
-	monotonicMutex.Unlock()
+	monotonic.last.Store(uint64(1000000)<<RandomBits | 100)
 
 	// Try to generate with an earlier timestamp
 	id, err := GenerateMonotonic(500000, nil)
@@ -1376,10 +1711,7 @@ func TestGenerate_RNGError(t *testing.T) {
 // TestGenerateMonotonic_RNGError tests error handling when RNG fails in monotonic generation
 func TestGenerateMonotonic_RNGError(t *testing.T) {
 	// Reset monotonic state to a different timestamp
-	monotonicMutex.Lock()
-	lastTimestamp = 1000
-	lastRandom = 0
-	monotonicMutex.Unlock()
+	monotonic.last.Store(uint64(1000) << RandomBits)
 
 	failingRNG := func(bits int) (uint32, error) {
 		return 0, fmt.Errorf("RNG failure")
@@ -1440,10 +1772,7 @@ func TestEncryptedNano64_CiphertextLengthCheck(t *testing.T) {
 // TestGenerateMonotonic_SameTimestampIncrement tests incrementing within same millisecond
 func TestGenerateMonotonic_SameTimestampIncrement(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = 1000
-	lastRandom = 50
-	monotonicMutex.Unlock()
+	monotonic.last.Store(uint64(1000)<<RandomBits | 50)
 
 	// Generate multiple IDs with the same timestamp
 	id1, err := GenerateMonotonic(1000, nil)
@@ -1486,10 +1815,7 @@ func TestGenerate_WithNilRNG(t *testing.T) {
 // TestGenerateMonotonic_WithNilRNG tests that nil RNG uses default
 func TestGenerateMonotonic_WithNilRNG(t *testing.T) {
 	// Reset monotonic state
-	monotonicMutex.Lock()
-	lastTimestamp = -1
-	lastRandom = 0
-	monotonicMutex.Unlock()
+	monotonic.reset()
 
 	id, err := GenerateMonotonic(12345, nil)
 	if err != nil {
@@ -1571,6 +1897,129 @@ func TestDefaultRNG_BitsMask(t *testing.T) {
 	}
 }
 
+func TestDefaultRNG_Pooled(t *testing.T) {
+	// Drain several pool chunks concurrently: values stay in range and the
+	// full 32-bit draws are not repeated across goroutines.
+	const goroutines, perG = 8, 2 * entropyChunk / 4
+	var mu sync.Mutex
+	seen := make(map[uint32]int)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]uint32, 0, perG)
+			for i := 0; i < perG; i++ {
+				v, err := DefaultRNG(32)
+				if err != nil {
+					t.Errorf("DefaultRNG(32) error = %v", err)
+					return
+				}
+				local = append(local, v)
+			}
+			mu.Lock()
+			for _, v := range local {
+				seen[v]++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	// 4096 draws from 2^32 collide with probability ~0.2%; allow a couple.
+	if dups := goroutines*perG - len(seen); dups > 2 {
+		t.Errorf("%d duplicate 32-bit draws, pool is handing out bytes twice", dups)
+	}
+}
+
+func TestUnbufferedRNG(t *testing.T) {
+	for _, bits := range []int{1, 8, 20, 32} {
+		v, err := UnbufferedRNG(bits)
+		if err != nil {
+			t.Fatalf("UnbufferedRNG(%d) error = %v", bits, err)
+		}
+		if bits < 32 && v >= 1<<bits {
+			t.Errorf("UnbufferedRNG(%d) = %d, exceeds %d bits", bits, v, bits)
+		}
+	}
+	for _, bits := range []int{0, 33} {
+		if _, err := UnbufferedRNG(bits); err == nil {
+			t.Errorf("UnbufferedRNG(%d) should fail", bits)
+		}
+	}
+}
+
+func TestFastRNG(t *testing.T) {
+	for _, bits := range []int{1, 8, 20, 32} {
+		v, err := FastRNG(bits)
+		if err != nil {
+			t.Fatalf("FastRNG(%d) error = %v", bits, err)
+		}
+		if bits < 32 && v >= 1<<bits {
+			t.Errorf("FastRNG(%d) = %d, exceeds %d bits", bits, v, bits)
+		}
+	}
+	if _, err := FastRNG(0); err == nil {
+		t.Error("FastRNG(0) should fail")
+	}
+
+	// Concurrent use through a Generator, under -race.
+	gen := NewGenerator(WithFastRNG())
+	var wg sync.WaitGroup
+	ids := make([][]Nano64, 8)
+	for g := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id, err := gen.GenerateMonotonicNow()
+				if err != nil {
+					t.Errorf("GenerateMonotonicNow() error = %v", err)
+					return
+				}
+				ids[g] = append(ids[g], id)
+			}
+		}()
+	}
+	wg.Wait()
+	seen := make(map[Nano64]bool)
+	for _, batch := range ids {
+		for _, id := range batch {
+			if seen[id] {
+				t.Fatalf("duplicate ID %s", id)
+			}
+			seen[id] = true
+		}
+	}
+
+	if _, err := Generate(now(), FastRNG); err != nil {
+		t.Errorf("Generate(ts, FastRNG) error = %v", err)
+	}
+}
+
+func BenchmarkFastRNG(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = FastRNG(RandomBits)
+		}
+	})
+}
+
+func BenchmarkDefaultRNG(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = DefaultRNG(RandomBits)
+		}
+	})
+}
+
+func BenchmarkUnbufferedRNG(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = UnbufferedRNG(RandomBits)
+		}
+	})
+}
+
 func TestNil(t *testing.T) {
 	// Test that Nil is zero value
 	if Nil.Uint64Value() != 0 {
@@ -1754,6 +2203,76 @@ func TestNullNano64_JSON(t *testing.T) {
 	}
 }
 
+func TestNullNano64_Parity(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	valid := NullNano64{ID: id, Valid: true}
+	null := NullNano64{}
+
+	if valid.Hex() != id.ToHex() || null.Hex() != "" {
+		t.Errorf("Hex() = %q, %q", valid.Hex(), null.Hex())
+	}
+	if valid.String() != id.String() || null.String() != "NULL" {
+		t.Errorf("String() = %q, %q", valid.String(), null.String())
+	}
+	if fmt.Sprint(null) != "NULL" {
+		t.Errorf("fmt.Sprint(null) = %q", fmt.Sprint(null))
+	}
+
+	nilValid := NullNano64{Valid: true}
+	ordered := []NullNano64{null, nilValid, {ID: New(1), Valid: true}, valid}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%v.Compare(%v) = %d, want %d", a, b, got, want)
+			}
+			if got := a.Equal(b); got != (i == j) {
+				t.Errorf("%v.Equal(%v) = %v", a, b, got)
+			}
+		}
+	}
+	// A NULL with a stale ID still equals NULL.
+	if !null.Equal(NullNano64{ID: id}) {
+		t.Error("NULLs with different IDs should be equal")
+	}
+
+	for _, n := range []NullNano64{valid, null, nilValid} {
+		text, err := n.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText() error = %v", err)
+		}
+		var got NullNano64
+		if err := got.UnmarshalText(text); err != nil || !got.Equal(n) {
+			t.Errorf("text round trip of %v = %v, %v", n, got, err)
+		}
+		parsed, err := NullFromHex(string(text))
+		if err != nil || !parsed.Equal(n) {
+			t.Errorf("NullFromHex(%q) = %v, %v", text, parsed, err)
+		}
+	}
+	if _, err := NullFromHex("zz"); err == nil {
+		t.Error("NullFromHex(invalid) error = nil, want error")
+	}
+	var bad NullNano64
+	if err := bad.UnmarshalText([]byte("zz")); err == nil {
+		t.Error("UnmarshalText(invalid) error = nil, want error")
+	}
+
+	// NULL map keys encode as "".
+	data, err := json.Marshal(map[NullNano64]int{valid: 1, null: 2})
+	if err != nil {
+		t.Fatalf("json.Marshal(map) error = %v", err)
+	}
+	if want := `{"":2,"199C01B6659-5861C":1}`; string(data) != want {
+		t.Errorf("json.Marshal(map) = %s, want %s", data, want)
+	}
+}
+
 func TestNullNano64_Database(t *testing.T) {
 	// Create in-memory SQLite database
 	tempDir := t.TempDir()
@@ -1812,3 +2331,4260 @@ func TestNullNano64_Database(t *testing.T) {
 		t.Error("Retrieved ID does not match original")
 	}
 }
+
+func TestNano64_LittleEndianFields(t *testing.T) {
+	id := New(0x123456789ABCDEF0)
+
+	le := id.ToBytesLE()
+	want := []byte{0xF0, 0xDE, 0xBC, 0x9A, 0x78, 0x56, 0x34, 0x12}
+	if string(le) != string(want) {
+		t.Errorf("ToBytesLE() = %X, want %X", le, want)
+	}
+
+	buf := make([]byte, 10)
+	if err := id.PutBytesLE(buf[2:]); err != nil {
+		t.Fatalf("PutBytesLE() error = %v", err)
+	}
+	if string(buf[2:]) != string(want) {
+		t.Errorf("PutBytesLE() wrote %X, want %X", buf[2:], want)
+	}
+	if err := id.PutBytesLE(make([]byte, 7)); err == nil {
+		t.Error("PutBytesLE(short) error = nil, want error")
+	}
+
+	parsed, err := FromBytesLE(le)
+	if err != nil {
+		t.Fatalf("FromBytesLE() error = %v", err)
+	}
+	if !parsed.Equals(id) {
+		t.Errorf("FromBytesLE() = %d, want %d", parsed.Uint64Value(), id.Uint64Value())
+	}
+
+	// Reading big-endian bytes as a little-endian field must not round-trip.
+	swapped, err := FromBytesLE(id.ToBytes())
+	if err != nil {
+		t.Fatalf("FromBytesLE() error = %v", err)
+	}
+	if swapped.Equals(id) {
+		t.Error("FromBytesLE(ToBytes()) should byte-swap the ID")
+	}
+
+	if _, err := FromBytesLE([]byte{1, 2, 3}); err == nil {
+		t.Error("FromBytesLE(short) error = nil, want error")
+	}
+}
+
+func TestPattern(t *testing.T) {
+	re := regexp.MustCompile(Pattern())
+
+	for _, v := range []uint64{0, 12345, 0x123456789ABCDEF0, ^uint64(0)} {
+		hex := New(v).ToHex()
+		if !re.MatchString(hex) {
+			t.Errorf("Pattern() does not match ToHex() output %q", hex)
+		}
+	}
+
+	if !re.MatchString(Example()) {
+		t.Errorf("Pattern() does not match Example() %q", Example())
+	}
+	if _, err := FromHex(Example()); err != nil {
+		t.Errorf("FromHex(Example()) error = %v", err)
+	}
+
+	for _, s := range []string{
+		"123456789ab-cdef0",
+		"123456789ABCDEF0",
+		"0x123456789ABCDEF0",
+		"123456789AB-CDEF",
+		"123456789AB-CDEFG",
+	} {
+		if re.MatchString(s) {
+			t.Errorf("Pattern() matches non-canonical %q", s)
+		}
+	}
+}
+
+func TestCUEDefinition(t *testing.T) {
+	want := `#Nano64: =~"^[0-9A-F]{11}-[0-9A-F]{5}$"`
+	if got := CUEDefinition("Nano64"); got != want {
+		t.Errorf("CUEDefinition() = %s, want %s", got, want)
+	}
+}
+
+func TestWrapWithID(t *testing.T) {
+	if WrapWithID(nil, New(1)) != nil {
+		t.Error("WrapWithID(nil) != nil")
+	}
+
+	sentinel := errors.New("row not found")
+	id := New(0x123456789ABCDEF0)
+
+	err := fmt.Errorf("load user: %w", WrapWithID(sentinel, id))
+	if !errors.Is(err, sentinel) {
+		t.Error("errors.Is() lost the wrapped error")
+	}
+	if want := "load user: id 123456789AB-CDEF0: row not found"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	got, ok := IDFromError(err)
+	if !ok || !got.Equals(id) {
+		t.Errorf("IDFromError() = %v, %v; want %v, true", got, ok, id)
+	}
+
+	// The outermost annotation wins.
+	outer := New(42)
+	got, ok = IDFromError(WrapWithID(err, outer))
+	if !ok || !got.Equals(outer) {
+		t.Errorf("IDFromError() = %v, %v; want %v, true", got, ok, outer)
+	}
+
+	if _, ok := IDFromError(sentinel); ok {
+		t.Error("IDFromError() on unannotated error returned ok")
+	}
+	if _, ok := IDFromError(nil); ok {
+		t.Error("IDFromError(nil) returned ok")
+	}
+}
+
+func TestHooks_Generate(t *testing.T) {
+	var traces []GenerateTrace
+	restore := SetHooks(&Hooks{
+		OnGenerate: func(tr GenerateTrace) { traces = append(traces, tr) },
+	})
+	defer restore()
+
+	slowRNG := func(bits int) (uint32, error) {
+		time.Sleep(time.Millisecond)
+		return 1, nil
+	}
+
+	if _, err := Generate(1234567890123, slowRNG); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := GenerateMonotonic(1234567890999, slowRNG); err != nil {
+		t.Fatalf("GenerateMonotonic() error = %v", err)
+	}
+	if _, err := Generate(-1, slowRNG); err == nil {
+		t.Fatal("Generate(-1) error = nil, want error")
+	}
+
+	if len(traces) != 3 {
+		t.Fatalf("OnGenerate called %d times, want 3", len(traces))
+	}
+	if traces[0].Monotonic || !traces[1].Monotonic {
+		t.Errorf("Monotonic flags = %v, %v; want false, true", traces[0].Monotonic, traces[1].Monotonic)
+	}
+	for i, tr := range traces[:2] {
+		if tr.RNGWait < time.Millisecond {
+			t.Errorf("trace %d RNGWait = %v, want >= 1ms", i, tr.RNGWait)
+		}
+		if tr.Total < tr.RNGWait {
+			t.Errorf("trace %d Total %v < RNGWait %v", i, tr.Total, tr.RNGWait)
+		}
+	}
+	if traces[2].Err == nil {
+		t.Error("trace for failed call has nil Err")
+	}
+
+	restore()
+	if _, err := Generate(1234567890123, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(traces) != 3 {
+		t.Errorf("OnGenerate called after restore")
+	}
+}
+
+func TestHooks_LatencyRecorder(t *testing.T) {
+	rec := NewLatencyRecorder()
+	restore := SetHooks(rec.Hooks())
+	defer restore()
+
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := config.GenerateEncryptedNow(); err != nil {
+			t.Fatalf("GenerateEncryptedNow() error = %v", err)
+		}
+		if _, err := GenerateMonotonicDefault(); err != nil {
+			t.Fatalf("GenerateMonotonicDefault() error = %v", err)
+		}
+	}
+
+	if got := rec.Generate.Count(); got != 20 {
+		t.Errorf("Generate.Count() = %d, want 20", got)
+	}
+	if got := rec.LockWait.Count(); got != 10 {
+		t.Errorf("LockWait.Count() = %d, want 10", got)
+	}
+	if got := rec.Encrypt.Count(); got != 10 {
+		t.Errorf("Encrypt.Count() = %d, want 10", got)
+	}
+	if got := rec.AEAD.Count(); got != 10 {
+		t.Errorf("AEAD.Count() = %d, want 10", got)
+	}
+	if rec.Encrypt.Quantile(0.99) < rec.AEAD.Quantile(0) {
+		t.Error("Encrypt p99 below AEAD minimum")
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h LatencyHistogram
+	if h.Quantile(0.5) != 0 {
+		t.Error("Quantile() on empty histogram != 0")
+	}
+
+	for i := 0; i < 90; i++ {
+		h.Observe(100 * time.Nanosecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(time.Millisecond)
+	}
+
+	if h.Count() != 100 {
+		t.Errorf("Count() = %d, want 100", h.Count())
+	}
+	if got := h.Quantile(0.5); got != 128*time.Nanosecond {
+		t.Errorf("Quantile(0.5) = %v, want 128ns", got)
+	}
+	if got := h.Quantile(0.99); got < time.Millisecond || got > 2*time.Millisecond {
+		t.Errorf("Quantile(0.99) = %v, want bucket holding 1ms", got)
+	}
+
+	snap := h.Snapshot()
+	if len(snap) != 2 || snap[0].Count != 90 || snap[1].Count != 10 {
+		t.Errorf("Snapshot() = %+v", snap)
+	}
+
+	h.Observe(0)
+	h.Observe(time.Duration(1<<63 - 1))
+	if h.Count() != 102 {
+		t.Errorf("Count() = %d, want 102", h.Count())
+	}
+
+	h.Reset()
+	if h.Count() != 0 {
+		t.Errorf("Count() after Reset() = %d", h.Count())
+	}
+}
+
+func TestNano64_UUIDv8(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	if got, want := id.UUIDString(), "199c01b6-6595-8861-8c00-000000000000"; got != want {
+		t.Errorf("UUIDString() = %s, want %s", got, want)
+	}
+
+	for _, v := range []uint64{0, 1, 0x123456789ABCDEF0, ^uint64(0)} {
+		u := New(v).UUIDv8()
+		if u[6]>>4 != 8 {
+			t.Errorf("UUIDv8(%X) version = %d, want 8", v, u[6]>>4)
+		}
+		if u[8]&0xC0 != 0x80 {
+			t.Errorf("UUIDv8(%X) variant bits = %02X", v, u[8]&0xC0)
+		}
+		back, err := FromUUIDv8(u)
+		if err != nil {
+			t.Fatalf("FromUUIDv8() error = %v", err)
+		}
+		if back.Uint64Value() != v {
+			t.Errorf("FromUUIDv8() = %X, want %X", back.Uint64Value(), v)
+		}
+	}
+
+	// Derived UUIDs keep time order bytewise.
+	a, b := New(0x123456789ABCDEF0).UUIDv8(), New(0x123456789ABCDEF1).UUIDv8()
+	if string(a[:]) >= string(b[:]) {
+		t.Error("derived UUIDs do not preserve order")
+	}
+
+	// A random UUIDv4 is rejected.
+	if _, err := FromUUIDString("f47ac10b-58cc-4372-a567-0e02b2c3d479"); err == nil {
+		t.Error("FromUUIDString(v4) error = nil, want error")
+	}
+	// Non-zero tail is rejected.
+	if _, err := FromUUIDString("199c01b6-6595-8861-8c00-000000000001"); err == nil {
+		t.Error("FromUUIDString(non-derived v8) error = nil, want error")
+	}
+	if _, err := FromUUIDString("199c01b6659588618c00000000000000"); err != nil {
+		t.Errorf("FromUUIDString(undashed) error = %v", err)
+	}
+	if _, err := FromUUIDString("199c01b6+6595-8861-8c00-000000000000"); err == nil {
+		t.Error("FromUUIDString(bad separator) error = nil, want error")
+	}
+}
+
+func TestDualID_JSON(t *testing.T) {
+	id := New(0x199C01B66595861C)
+
+	tests := []struct {
+		name    string
+		primary Primary
+		want    string
+	}{
+		{"nano64 primary", PrimaryNano64, `{"id":"199C01B6659-5861C","uuid":"199c01b6-6595-8861-8c00-000000000000"}`},
+		{"uuid primary", PrimaryUUID, `{"id":"199c01b6-6595-8861-8c00-000000000000","nano64":"199C01B6659-5861C"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(NewDualID(id, tt.primary))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+
+			var got DualID
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !got.ID.Equals(id) || got.Primary != tt.primary {
+				t.Errorf("Unmarshal() = %+v, want %v/%d", got, id, tt.primary)
+			}
+		})
+	}
+
+	var d DualID
+	if err := json.Unmarshal([]byte(`{"id":"199C01B6659-5861C"}`), &d); err != nil {
+		t.Errorf("Unmarshal(id only) error = %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"199C01B6659-5861C","uuid":"199c01b6-6595-8861-8d00-000000000000"}`), &d); err == nil {
+		t.Error("Unmarshal(mismatched uuid) error = nil, want error")
+	}
+	if err := json.Unmarshal([]byte(`{"id":"nope"}`), &d); err == nil {
+		t.Error("Unmarshal(invalid id) error = nil, want error")
+	}
+}
+
+func TestDualID_SQL(t *testing.T) {
+	id := New(0x123456789ABCDEF0)
+
+	for _, primary := range []Primary{PrimaryNano64, PrimaryUUID} {
+		d := NewDualID(id, primary)
+		v, err := d.Value()
+		if err != nil {
+			t.Fatalf("Value() error = %v", err)
+		}
+		wantLen := 8
+		if primary == PrimaryUUID {
+			wantLen = UUIDLength
+		}
+		if b, ok := v.([]byte); !ok || len(b) != wantLen {
+			t.Errorf("Value() = %v, want %d bytes", v, wantLen)
+		}
+
+		var scanned DualID
+		if err := scanned.Scan(v); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		if !scanned.ID.Equals(id) {
+			t.Errorf("Scan() = %v, want %v", scanned.ID, id)
+		}
+	}
+
+	var scanned DualID
+	if err := scanned.Scan(id.UUIDString()); err != nil || !scanned.ID.Equals(id) {
+		t.Errorf("Scan(uuid string) = %v, %v", scanned.ID, err)
+	}
+	if err := scanned.Scan(int64(42)); err != nil || scanned.ID.Uint64Value() != 42 {
+		t.Errorf("Scan(int64) = %v, %v", scanned.ID, err)
+	}
+	if err := scanned.Scan(make([]byte, 16)); err == nil {
+		t.Error("Scan(zero UUID) error = nil, want error")
+	}
+
+	if got := NewDualID(id, PrimaryNano64).WithPrimary(PrimaryUUID).String(); got != id.UUIDString() {
+		t.Errorf("String() = %s, want %s", got, id.UUIDString())
+	}
+}
+
+func TestManifest(t *testing.T) {
+	m := CurrentManifest()
+	if err := m.Validate(); err != nil {
+		t.Fatalf("CurrentManifest().Validate() error = %v", err)
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"version":1,"layout":{"timestamp_bits":44,"random_bits":20,"node_bits":0,"epoch_ms":0},"policies":{"overflow":"advance","clock_regression":"clamp"}}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	parsed, err := ParseManifest(data)
+	if err != nil {
+		t.Fatalf("ParseManifest() error = %v", err)
+	}
+	if parsed != m {
+		t.Errorf("ParseManifest() = %+v, want %+v", parsed, m)
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"version":2,"layout":{"timestamp_bits":44,"random_bits":20}}`,
+		`{"version":1,"layout":{"timestamp_bits":41,"random_bits":23}}`,
+		`{"version":1,"layout":{"timestamp_bits":44,"random_bits":20,"node_bits":20}}`,
+		`{"version":1,"layout":{"timestamp_bits":44,"random_bits":20,"epoch_ms":-1}}`,
+	}
+	for _, s := range invalid {
+		if _, err := ParseManifest([]byte(s)); err == nil {
+			t.Errorf("ParseManifest(%s) error = nil, want error", s)
+		}
+	}
+}
+
+func TestVerifyCompatibility(t *testing.T) {
+	if err := VerifyCompatibility(CurrentManifest()); err != nil {
+		t.Errorf("VerifyCompatibility(CurrentManifest()) error = %v", err)
+	}
+
+	relaxed := CurrentManifest()
+	relaxed.Policies.Overflow = "wait"
+	if err := VerifyCompatibility(relaxed); err != nil {
+		t.Errorf("VerifyCompatibility() with different policy error = %v, want nil", err)
+	}
+
+	shifted := CurrentManifest()
+	shifted.Layout.Epoch = 1700000000000
+	shifted.Layout.NodeBits = 4
+	err := VerifyCompatibility(shifted)
+	if err == nil {
+		t.Fatal("VerifyCompatibility() with different epoch error = nil, want error")
+	}
+	for _, want := range []string{"epoch", "node bits"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("VerifyCompatibility() error = %q, want mention of %q", err, want)
+		}
+	}
+}
+
+func TestSchemeFingerprint(t *testing.T) {
+	fp := SchemeFingerprint()
+	if len(fp) != 16 {
+		t.Errorf("SchemeFingerprint() length = %d, want 16", len(fp))
+	}
+	if fp != SchemeFingerprint() {
+		t.Error("SchemeFingerprint() is not stable")
+	}
+
+	other := CurrentManifest()
+	other.Layout.Epoch = 1
+	if other.Fingerprint() == fp {
+		t.Error("Fingerprint() did not change with epoch")
+	}
+}
+
+func TestScopedID_Encodings(t *testing.T) {
+	s := ScopedID{Tenant: 42, ID: New(0x199C01B66595861C)}
+
+	if got, want := s.ToText(), "0000002A:199C01B6659-5861C"; got != want {
+		t.Errorf("ToText() = %s, want %s", got, want)
+	}
+	parsed, err := ParseScopedID(s.ToText())
+	if err != nil || !parsed.Equals(s) {
+		t.Errorf("ParseScopedID() = %v, %v, want %v", parsed, err, s)
+	}
+
+	b := s.ToBytes()
+	if len(b) != ScopedIDLength {
+		t.Fatalf("ToBytes() length = %d, want %d", len(b), ScopedIDLength)
+	}
+	decoded, err := FromScopedBytes(b)
+	if err != nil || !decoded.Equals(s) {
+		t.Errorf("FromScopedBytes() = %v, %v, want %v", decoded, err, s)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var fromJSON ScopedID
+	if err := json.Unmarshal(data, &fromJSON); err != nil || !fromJSON.Equals(s) {
+		t.Errorf("json round trip = %v, %v, want %v", fromJSON, err, s)
+	}
+
+	v, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	var scanned ScopedID
+	if err := scanned.Scan(v); err != nil || !scanned.Equals(s) {
+		t.Errorf("Scan(Value()) = %v, %v, want %v", scanned, err, s)
+	}
+	if err := scanned.Scan(int64(1)); err == nil {
+		t.Error("Scan(int64) error = nil, want error")
+	}
+
+	for _, bad := range []string{"", "0000002A-199C01B6659-5861C", "ZZZZZZZZ:199C01B6659-5861C", "0000002A:199C01B6659-5861"} {
+		if _, err := ParseScopedID(bad); err == nil {
+			t.Errorf("ParseScopedID(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestScopedID_Ordering(t *testing.T) {
+	ids := []ScopedID{
+		{Tenant: 1, ID: New(0x200 << timestampShift)},
+		{Tenant: 1, ID: New(0x100 << timestampShift)},
+		{Tenant: 0, ID: New(0x300 << timestampShift)},
+		{Tenant: 0xFFFFFFFF, ID: New(0)},
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Compare(ids[j]) < 0 })
+
+	for i := 1; i < len(ids); i++ {
+		if bytes.Compare(ids[i-1].ToBytes(), ids[i].ToBytes()) >= 0 {
+			t.Errorf("binary order differs from Compare at %d", i)
+		}
+		if ids[i-1].ToText() >= ids[i].ToText() {
+			t.Errorf("text order differs from Compare at %d", i)
+		}
+	}
+	if ids[1].Tenant != 1 || ids[1].ID.GetTimestamp() != 0x100 {
+		t.Errorf("ids[1] = %v, want tenant 1 with earlier timestamp", ids[1])
+	}
+}
+
+func TestRevisionID_Encodings(t *testing.T) {
+	r := RevisionID{ID: New(0x199C01B66595861C), Revision: 3}
+
+	if got, want := r.ToText(), "199C01B6659-5861C:r00003"; got != want {
+		t.Errorf("ToText() = %s, want %s", got, want)
+	}
+	if len(r.ToText()) != RevisionIDTextLength {
+		t.Errorf("ToText() length = %d, want %d", len(r.ToText()), RevisionIDTextLength)
+	}
+	parsed, err := ParseRevisionID(r.ToText())
+	if err != nil || !parsed.Equals(r) {
+		t.Errorf("ParseRevisionID() = %v, %v, want %v", parsed, err, r)
+	}
+
+	decoded, err := FromRevisionBytes(r.ToBytes())
+	if err != nil || !decoded.Equals(r) {
+		t.Errorf("FromRevisionBytes() = %v, %v, want %v", decoded, err, r)
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var fromJSON RevisionID
+	if err := json.Unmarshal(data, &fromJSON); err != nil || !fromJSON.Equals(r) {
+		t.Errorf("json round trip = %v, %v, want %v", fromJSON, err, r)
+	}
+
+	v, _ := r.Value()
+	var scanned RevisionID
+	if err := scanned.Scan(v); err != nil || !scanned.Equals(r) {
+		t.Errorf("Scan(Value()) = %v, %v, want %v", scanned, err, r)
+	}
+
+	for _, bad := range []string{"", "199C01B6659-5861C:r99999", "199C01B6659-5861C-r00003", "199C01B6659-5861C:rABCDE"} {
+		if _, err := ParseRevisionID(bad); err == nil {
+			t.Errorf("ParseRevisionID(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestRevisionID_Ordering(t *testing.T) {
+	a := RevisionID{ID: New(0x100 << timestampShift), Revision: 9}
+	b, err := a.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	c := RevisionID{ID: New(0x200 << timestampShift)}
+
+	if !a.SameEntity(b) || a.SameEntity(c) {
+		t.Error("SameEntity() mismatch")
+	}
+	if a.Compare(b) != -1 || b.Compare(c) != -1 || c.Compare(a) != 1 {
+		t.Error("Compare() did not order by entity then revision")
+	}
+	if bytes.Compare(a.ToBytes(), b.ToBytes()) >= 0 || b.ToText() >= c.ToText() {
+		t.Error("encodings do not sort like Compare()")
+	}
+
+	if _, err := (RevisionID{Revision: 0xFFFF}).Next(); err == nil {
+		t.Error("Next() at max revision error = nil, want error")
+	}
+}
+
+func TestLogPosition_Next(t *testing.T) {
+	prev := LogStart
+	for i := 0; i < 1000; i++ {
+		next, err := prev.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if !next.After(prev) {
+			t.Fatalf("Next() = %s, not after %s", next, prev)
+		}
+		prev = next
+	}
+
+	// A position persisted from the future still yields a later position,
+	// and so does every later NextLogPosition call.
+	t.Cleanup(monotonic.reset)
+	future := LogPosition(New(uint64(maxTimestamp-1) << timestampShift))
+	next, err := future.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if next.Compare(future) != 1 {
+		t.Errorf("Next() = %s, want after %s", next, future)
+	}
+	after, err := NextLogPosition()
+	if err != nil || !after.After(next) {
+		t.Errorf("NextLogPosition() after Next() = %s, %v, want after %s", after, err, next)
+	}
+
+	if _, err := LogPosition(New(^uint64(0))).Next(); err == nil {
+		t.Error("Next() at max position error = nil, want error")
+	}
+}
+
+func TestGenerator_NextLogPosition(t *testing.T) {
+	var now atomic.Int64
+	now.Store(1_700_000_000_000)
+	g := NewGenerator(WithClock(now.Load), WithNodeID(3, 4), WithStrictMonotonic(0))
+
+	prev := LogStart
+	for i := 0; i < 100; i++ {
+		next, err := prev.NextFrom(g)
+		if err != nil {
+			t.Fatalf("NextFrom() error = %v", err)
+		}
+		if !next.After(prev) {
+			t.Fatalf("NextFrom() = %s, not after %s", next, prev)
+		}
+		prev = next
+	}
+
+	// A position in the same millisecond from another node is skipped past.
+	other := LogPosition(New(uint64(now.Load())<<timestampShift | randomMask))
+	next, err := other.NextFrom(g)
+	if err != nil || !next.After(other) {
+		t.Errorf("NextFrom(other node) = %s, %v, want after %s", next, err, other)
+	}
+	now.Add(1)
+	if later, err := g.NextLogPosition(); err != nil || !later.After(next) {
+		t.Errorf("NextLogPosition() = %s, %v, want after %s", later, err, next)
+	}
+
+	// In strict mode a position far ahead of the clock fails rather than
+	// borrowing future milliseconds.
+	future := LogPosition(New(uint64(now.Load()+60_000) << timestampShift))
+	if _, err := future.NextFrom(g); !errors.Is(err, ErrClockRegression) {
+		t.Errorf("NextFrom(future) error = %v, want ErrClockRegression", err)
+	}
+}
+
+func TestLogPosition_Persistence(t *testing.T) {
+	p := LogPosition(New(0x199C01B66595861C))
+
+	fromBytes, err := FromLogPositionBytes(p.ToBytes())
+	if err != nil || fromBytes != p {
+		t.Errorf("FromLogPositionBytes() = %s, %v, want %s", fromBytes, err, p)
+	}
+	fromString, err := ParseLogPosition(p.String())
+	if err != nil || fromString != p {
+		t.Errorf("ParseLogPosition() = %s, %v, want %s", fromString, err, p)
+	}
+
+	data, _ := json.Marshal(p)
+	var fromJSON LogPosition
+	if err := json.Unmarshal(data, &fromJSON); err != nil || fromJSON != p {
+		t.Errorf("json round trip = %s, %v, want %s", fromJSON, err, p)
+	}
+
+	later := LogPosition(New(uint64(p.ID().GetTimestamp()+1500) << timestampShift))
+	if got := p.Distance(later); got != 1500*time.Millisecond {
+		t.Errorf("Distance() = %v, want 1.5s", got)
+	}
+	if !LogStart.Before(p) {
+		t.Error("LogStart.Before() = false, want true")
+	}
+}
+
+func TestSetDefaultClockAndRNG(t *testing.T) {
+	restoreClock := SetDefaultClock(func() int64 { return 1234567890 })
+	restoreRNG := SetDefaultRNG(func(bits int) (uint32, error) { return 0xABCDE, nil })
+
+	id, err := GenerateDefault()
+	if err != nil {
+		t.Fatalf("GenerateDefault() error = %v", err)
+	}
+	if id.GetTimestamp() != 1234567890 || id.GetRandom() != 0xABCDE {
+		t.Errorf("GenerateDefault() = %s, want timestamp 1234567890 random 0xABCDE", id.ToHex())
+	}
+
+	id, err = Generate(42, nil)
+	if err != nil || id.GetRandom() != 0xABCDE {
+		t.Errorf("Generate(42, nil) = %s, %v, want default RNG override", id.ToHex(), err)
+	}
+
+	nested := SetDefaultClock(func() int64 { return 99 })
+	if id, _ := GenerateNow(nil); id.GetTimestamp() != 99 {
+		t.Errorf("GenerateNow() timestamp = %d, want 99", id.GetTimestamp())
+	}
+	nested()
+	if id, _ := GenerateNow(nil); id.GetTimestamp() != 1234567890 {
+		t.Errorf("after restore GenerateNow() timestamp = %d, want 1234567890", id.GetTimestamp())
+	}
+
+	restoreRNG()
+	restoreClock()
+
+	before := time.Now().UnixMilli()
+	id, err = GenerateDefault()
+	if err != nil {
+		t.Fatalf("GenerateDefault() error = %v", err)
+	}
+	if id.GetTimestamp() < before {
+		t.Errorf("GenerateDefault() after restore timestamp = %d, want >= %d", id.GetTimestamp(), before)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	want := New(0x199C01B66595861C)
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		wantErr bool
+	}{
+		{"canonical strict", "199C01B6659-5861C", ParseOptions{}, false},
+		{"undashed strict", "199C01B66595861C", ParseOptions{}, true},
+		{"undashed allowed", "199C01B66595861C", ParseOptions{AllowUndashed: true}, false},
+		{"lowercase strict", "199c01b6659-5861c", ParseOptions{}, true},
+		{"lowercase allowed", "199c01b6659-5861c", ParseOptions{AllowLowercase: true}, false},
+		{"prefix strict", "0x199C01B6659-5861C", ParseOptions{}, true},
+		{"prefix allowed", "0X199C01B6659-5861C", ParseOptions{AllowPrefix: true}, false},
+		{"misplaced dash", "199C01B665-95861C", ParseOptions{AllowUndashed: true}, true},
+		{"grouped allowed", "199C-01B6-6595-861C", ParseOptions{AllowGrouping: true}, false},
+		{"grouped strict", "199C-01B6-6595-861C", ParseOptions{}, true},
+		{"leading dash", "-199C01B6659-5861C", LenientParseOptions, true},
+		{"trailing dash", "199C01B66595861C-", LenientParseOptions, true},
+		{"double dash", "199C01B6659--5861C", LenientParseOptions, true},
+		{"too short", "199C01B6659-5861", LenientParseOptions, true},
+		{"too long", "199C01B6659-5861C0", LenientParseOptions, true},
+		{"non-hex", "199C01B6659-5861G", LenientParseOptions, true},
+		{"within horizon", "199C01B6659-5861C", ParseOptions{MaxTimestamp: want.GetTimestamp()}, false},
+		{"beyond horizon", "199C01B6659-5861C", ParseOptions{MaxTimestamp: want.GetTimestamp() - 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWithOptions(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equals(want) {
+				t.Errorf("ParseWithOptions(%q) = %s, want %s", tt.input, got.ToHex(), want.ToHex())
+			}
+		})
+	}
+}
+
+func TestParse_MatchesFromHex(t *testing.T) {
+	for _, s := range []string{"199C01B6659-5861C", "199c01b66595861c", "0x199C01B6659-5861C", "FFFFFFFFFFF-FFFFF"} {
+		a, errA := Parse(s)
+		b, errB := FromHex(s)
+		if errA != nil || errB != nil || !a.Equals(b) {
+			t.Errorf("Parse(%q) = %s, %v; FromHex = %s, %v", s, a.ToHex(), errA, b.ToHex(), errB)
+		}
+	}
+}
+
+func TestSplitBytes(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	ts, rnd := id.SplitBytes()
+
+	if want := [6]byte{0x01, 0x99, 0xC0, 0x1B, 0x66, 0x59}; ts != want {
+		t.Errorf("SplitBytes() ts = %X, want %X", ts, want)
+	}
+	if want := [3]byte{0x05, 0x86, 0x1C}; rnd != want {
+		t.Errorf("SplitBytes() rand = %X, want %X", rnd, want)
+	}
+
+	joined, err := FromSplitBytes(ts, rnd)
+	if err != nil || !joined.Equals(id) {
+		t.Errorf("FromSplitBytes() = %s, %v, want %s", joined.ToHex(), err, id.ToHex())
+	}
+
+	if _, err := FromSplitBytes([6]byte{0x10}, [3]byte{}); err == nil {
+		t.Error("FromSplitBytes() with 45-bit timestamp error = nil, want error")
+	}
+	if _, err := FromSplitBytes([6]byte{}, [3]byte{0x10}); err == nil {
+		t.Error("FromSplitBytes() with 21-bit random error = nil, want error")
+	}
+
+	maxTs, maxRand := New(^uint64(0)).SplitBytes()
+	if back, err := FromSplitBytes(maxTs, maxRand); err != nil || back.Uint64Value() != ^uint64(0) {
+		t.Errorf("FromSplitBytes(max) = %s, %v", back.ToHex(), err)
+	}
+}
+
+func TestDescending(t *testing.T) {
+	older := New(0x100<<timestampShift | 5)
+	newer := New(0x200<<timestampShift | 1)
+
+	if older.Descending().ToHex() <= newer.Descending().ToHex() {
+		t.Error("descending hex keys do not sort newest-first")
+	}
+	if bytes.Compare(older.ToDescendingBytes(), newer.ToDescendingBytes()) <= 0 {
+		t.Error("descending byte keys do not sort newest-first")
+	}
+
+	if got := FromDescending(newer.Descending()); !got.Equals(newer) {
+		t.Errorf("FromDescending() = %s, want %s", got.ToHex(), newer.ToHex())
+	}
+	if got, err := FromDescendingHex(newer.ToDescendingHex()); err != nil || !got.Equals(newer) {
+		t.Errorf("FromDescendingHex() = %s, %v, want %s", got.ToHex(), err, newer.ToHex())
+	}
+	if got, err := FromDescendingBytes(newer.ToDescendingBytes()); err != nil || !got.Equals(newer) {
+		t.Errorf("FromDescendingBytes() = %s, %v, want %s", got.ToHex(), err, newer.ToHex())
+	}
+	if got := New(0).ToDescendingHex(); got != "FFFFFFFFFFF-FFFFF" {
+		t.Errorf("ToDescendingHex() of zero = %s, want FFFFFFFFFFF-FFFFF", got)
+	}
+	if _, err := FromDescendingHex("nope"); err == nil {
+		t.Error("FromDescendingHex(invalid) error = nil, want error")
+	}
+}
+
+func TestInterleaveWith(t *testing.T) {
+	id := New(0x199C01B66595861C)
+
+	for _, bits := range []int{1, 8, 16, 32} {
+		secondary := uint32(0xDEADBEEF) & uint32(1<<bits-1)
+		key := id.InterleaveWith(secondary, bits)
+		if want := (TimestampBits + bits + RandomBits + 7) / 8; len(key) != want {
+			t.Errorf("InterleaveWith(bits=%d) length = %d, want %d", bits, len(key), want)
+		}
+		gotID, gotSec, err := FromInterleaved(key, bits)
+		if err != nil || !gotID.Equals(id) || gotSec != secondary {
+			t.Errorf("FromInterleaved(bits=%d) = %s, %X, %v, want %s, %X", bits, gotID.ToHex(), gotSec, err, id.ToHex(), secondary)
+		}
+	}
+
+	// The first key bits alternate between timestamp and secondary MSBs.
+	top := New(uint64(1) << 63)
+	if key := top.InterleaveWith(0, 8); key[0] != 0x80 {
+		t.Errorf("timestamp MSB not first: %08b", key[0])
+	}
+	if key := New(0).InterleaveWith(0x80, 8); key[0] != 0x40 {
+		t.Errorf("secondary MSB not second: %08b", key[0])
+	}
+
+	// Keys sort by both dimensions at the coarsest level.
+	a := New(0x100<<timestampShift).InterleaveWith(1, 4)
+	b := New(0x100<<timestampShift).InterleaveWith(2, 4)
+	if bytes.Compare(a, b) >= 0 {
+		t.Error("keys with the same time do not sort by secondary")
+	}
+
+	if _, _, err := FromInterleaved(make([]byte, 3), 8); err == nil {
+		t.Error("FromInterleaved() with short key error = nil, want error")
+	}
+	if _, _, err := FromInterleaved(nil, 0); err == nil {
+		t.Error("FromInterleaved() with bits=0 error = nil, want error")
+	}
+}
+
+func TestPartitionPlan(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(4 * 24 * time.Hour)
+
+	plan, err := PartitionPlan(start, end, 4)
+	if err != nil {
+		t.Fatalf("PartitionPlan() error = %v", err)
+	}
+	if len(plan.Partitions) != 4 {
+		t.Fatalf("len(Partitions) = %d, want 4", len(plan.Partitions))
+	}
+	for i, part := range plan.Partitions {
+		if want := start.Add(time.Duration(i) * 24 * time.Hour); !part.Start.Equal(want) {
+			t.Errorf("Partitions[%d].Start = %v, want %v", i, part.Start, want)
+		}
+		if part.From.GetTimestamp() != part.Start.UnixMilli() || part.From.GetRandom() != 0 {
+			t.Errorf("Partitions[%d].From = %s, want min ID at %v", i, part.From.ToHex(), part.Start)
+		}
+		if i > 0 && !plan.Partitions[i-1].To.Equals(part.From) {
+			t.Errorf("Partitions[%d] not contiguous with previous", i)
+		}
+	}
+	if b := plan.Boundaries(); len(b) != 5 || !b[4].Equals(plan.Partitions[3].To) {
+		t.Errorf("Boundaries() = %v, want 5 IDs ending at last To", b)
+	}
+
+	id, _ := Generate(start.Add(36*time.Hour).UnixMilli(), nil)
+	if got := plan.Locate(id); got != 1 {
+		t.Errorf("Locate() = %d, want 1", got)
+	}
+	late, _ := Generate(end.UnixMilli(), nil)
+	if got := plan.Locate(late); got != -1 {
+		t.Errorf("Locate(after end) = %d, want -1", got)
+	}
+
+	ddl := plan.PostgresDDL("events")
+	wantFirst := fmt.Sprintf(`CREATE TABLE events_p000 PARTITION OF events FOR VALUES FROM ('\x%x') TO ('\x%x');`,
+		plan.Partitions[0].From.ToBytes(), plan.Partitions[0].To.ToBytes())
+	if ddl[0] != wantFirst {
+		t.Errorf("PostgresDDL()[0] = %s, want %s", ddl[0], wantFirst)
+	}
+
+	shards := plan.VitessShards()
+	if !strings.HasPrefix(shards[0], "-") || !strings.HasSuffix(shards[3], "-") {
+		t.Errorf("VitessShards() = %v, want open first and last ranges", shards)
+	}
+	if got, want := shards[1], fmt.Sprintf("%x-%x", plan.Partitions[1].From.ToBytes(), plan.Partitions[1].To.ToBytes()); got != want {
+		t.Errorf("VitessShards()[1] = %s, want %s", got, want)
+	}
+
+	if _, err := PartitionPlan(start, end, 0); err == nil {
+		t.Error("PartitionPlan() with 0 partitions error = nil, want error")
+	}
+	if _, err := PartitionPlan(end, start, 2); err == nil {
+		t.Error("PartitionPlan() with reversed range error = nil, want error")
+	}
+
+	// span*i exceeds 64 bits across the full timestamp range.
+	full, err := PartitionPlan(time.UnixMilli(0), ExhaustionTime(), 1<<20)
+	if err != nil {
+		t.Fatalf("PartitionPlan() over the full range error = %v", err)
+	}
+	for i, part := range full.Partitions {
+		if !part.Start.Before(part.End) || (i > 0 && !full.Partitions[i-1].End.Equal(part.Start)) {
+			t.Fatalf("full-range Partitions[%d] = [%v, %v), not ascending and contiguous", i, part.Start, part.End)
+		}
+	}
+	if last := full.Partitions[len(full.Partitions)-1]; last.End.UnixMilli() != maxTimestamp {
+		t.Errorf("full-range last End = %d, want %d", last.End.UnixMilli(), int64(maxTimestamp))
+	}
+}
+
+func TestNano64_Hash(t *testing.T) {
+	tests := []struct {
+		value uint64
+		seed  uint64
+		want  uint64
+	}{
+		// First two outputs of SplitMix64 seeded with 0.
+		{0, 0, 0xE220A8397B1DCDAF},
+		{0x9E3779B97F4A7C15, 0, 0x6E789E6AA1B965F4},
+		{0, 0x9E3779B97F4A7C15, 0x6E789E6AA1B965F4},
+	}
+	for _, tt := range tests {
+		if got := New(tt.value).Hash(tt.seed); got != tt.want {
+			t.Errorf("New(%#x).Hash(%#x) = %#x, want %#x", tt.value, tt.seed, got, tt.want)
+		}
+	}
+
+	id := New(0x199C01B66595861C)
+	if id.Hash(1) == id.Hash(2) {
+		t.Error("Hash() ignores the seed")
+	}
+	if id.Hash(7) != id.Hash(7) {
+		t.Error("Hash() is not deterministic")
+	}
+
+	// Adjacent monotonic IDs spread across buckets.
+	buckets := make([]int, 16)
+	for i := uint64(0); i < 16000; i++ {
+		buckets[New(0x199C01B6659<<timestampShift|i).Hash(0)%16]++
+	}
+	for i, n := range buckets {
+		if n < 800 || n > 1200 {
+			t.Errorf("bucket %d has %d of 16000 sequential IDs, want about 1000", i, n)
+		}
+	}
+}
+
+func TestSortIDs(t *testing.T) {
+	ids := make([]Nano64, 1000)
+	for i := range ids {
+		ids[i], _ = Generate(int64(1000-i), nil)
+	}
+	ids = append(ids, New(^uint64(0)), New(0))
+
+	SortIDs(ids)
+	if !IDsSorted(ids) {
+		t.Fatal("SortIDs() did not sort")
+	}
+	if !ids[0].IsNil() || ids[len(ids)-1].Uint64Value() != ^uint64(0) {
+		t.Error("SortIDs() did not treat values as unsigned")
+	}
+}
+
+func TestMedianAndPercentile(t *testing.T) {
+	ids := make([]Nano64, 10)
+	for i := range ids {
+		ids[i] = New(uint64(i+1) << timestampShift)
+	}
+
+	tests := []struct {
+		p    float64
+		want int64
+	}{
+		{0, 1},
+		{10, 1},
+		{25, 3},
+		{50, 5},
+		{90, 9},
+		{99, 10},
+		{100, 10},
+	}
+	for _, tt := range tests {
+		got, err := Percentile(ids, tt.p)
+		if err != nil || got.GetTimestamp() != tt.want {
+			t.Errorf("Percentile(%v) = %d, %v, want %d", tt.p, got.GetTimestamp(), err, tt.want)
+		}
+	}
+
+	if m, err := Median(ids); err != nil || m.GetTimestamp() != 5 {
+		t.Errorf("Median() = %d, %v, want 5", m.GetTimestamp(), err)
+	}
+	if m, _ := Median(ids[:9]); m.GetTimestamp() != 5 {
+		t.Errorf("Median(odd) = %d, want 5", m.GetTimestamp())
+	}
+
+	if _, err := Median(nil); err == nil {
+		t.Error("Median(nil) error = nil, want error")
+	}
+	for _, p := range []float64{-1, 101} {
+		if _, err := Percentile(ids, p); err == nil {
+			t.Errorf("Percentile(%v) error = nil, want error", p)
+		}
+	}
+}
+
+func benchmarkIDs(n int) []Nano64 {
+	ids := make([]Nano64, n)
+	for i := range ids {
+		ids[i], _ = GenerateDefault()
+		ids[i] = New(ids[i].Uint64Value() ^ uint64(i)*0x9E3779B97F4A7C15)
+	}
+	return ids
+}
+
+func BenchmarkSortIDs(b *testing.B) {
+	src := benchmarkIDs(100000)
+	ids := make([]Nano64, len(src))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(ids, src)
+		SortIDs(ids)
+	}
+}
+
+func BenchmarkSortSlice(b *testing.B) {
+	src := benchmarkIDs(100000)
+	ids := make([]Nano64, len(src))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(ids, src)
+		sort.Slice(ids, func(i, j int) bool { return Compare(ids[i], ids[j]) < 0 })
+	}
+}
+
+func TestNano64_FormatHex(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	tests := []struct {
+		group GroupOption
+		want  string
+	}{
+		{GroupCanonical, "199C01B6659-5861C"},
+		{GroupNone, "199C01B66595861C"},
+		{GroupQuads, "199C-01B6-6595-861C"},
+	}
+	for _, tt := range tests {
+		got := id.FormatHex(tt.group)
+		if got != tt.want {
+			t.Errorf("FormatHex(%d) = %s, want %s", tt.group, got, tt.want)
+		}
+		parsed, err := Parse(got)
+		if err != nil || !parsed.Equals(id) {
+			t.Errorf("Parse(%s) = %s, %v, want %s", got, parsed.ToHex(), err, id.ToHex())
+		}
+	}
+	if id.FormatHex(GroupCanonical) != id.ToHex() {
+		t.Error("FormatHex(GroupCanonical) differs from ToHex()")
+	}
+}
+
+func TestNano64_Base62(t *testing.T) {
+	tests := []struct {
+		value uint64
+		want  string
+	}{
+		{0, "0"},
+		{61, "z"},
+		{62, "10"},
+		{^uint64(0), "LygHa16AHYF"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.value).ToBase62(); got != tt.want {
+			t.Errorf("New(%d).ToBase62() = %s, want %s", tt.value, got, tt.want)
+		}
+		if got, err := FromBase62(tt.want); err != nil || got.Uint64Value() != tt.value {
+			t.Errorf("FromBase62(%s) = %d, %v, want %d", tt.want, got.Uint64Value(), err, tt.value)
+		}
+	}
+
+	for _, bad := range []string{"", "abc-", "LygHa16AHYG", "100000000000", "00", "0z", "0LygHa16AHYF"} {
+		if _, err := FromBase62(bad); err == nil {
+			t.Errorf("FromBase62(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestNano64_Base58(t *testing.T) {
+	tests := []struct {
+		value uint64
+		want  string
+	}{
+		{0, "1"},
+		{57, "z"},
+		{58, "21"},
+		{^uint64(0), "jpXCZedGfVQ"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.value).ToBase58(); got != tt.want {
+			t.Errorf("New(%d).ToBase58() = %s, want %s", tt.value, got, tt.want)
+		}
+		if got, err := FromBase58(tt.want); err != nil || got.Uint64Value() != tt.value {
+			t.Errorf("FromBase58(%s) = %d, %v, want %d", tt.want, got.Uint64Value(), err, tt.value)
+		}
+	}
+
+	for _, bad := range []string{"", "0", "O", "I", "l", "jpXCZedGfVR", "11", "1z", "1jpXCZedGfVQ", "z z", "z\x00"} {
+		if _, err := FromBase58(bad); err == nil {
+			t.Errorf("FromBase58(%q) error = nil, want error", bad)
+		}
+	}
+
+	id := New(0x199C01B66595861C)
+	if got, err := FromBase58(id.ToBase58()); err != nil || !got.Equals(id) {
+		t.Errorf("Base58 round trip = %s, %v, want %s", got.ToHex(), err, id.ToHex())
+	}
+
+	r := rand.New(rand.NewSource(58))
+	for i := 0; i < 10000; i++ {
+		v := r.Uint64() >> r.Intn(64)
+		s := New(v).ToBase58()
+		if len(s) > 11 {
+			t.Fatalf("New(%d).ToBase58() = %q, longer than 11 chars", v, s)
+		}
+		if got, err := FromBase58(s); err != nil || got.Uint64Value() != v {
+			t.Fatalf("FromBase58(%q) = %d, %v, want %d", s, got.Uint64Value(), err, v)
+		}
+	}
+}
+
+func TestNano64_Base64URL(t *testing.T) {
+	tests := []struct {
+		value uint64
+		want  string
+	}{
+		{0, "AAAAAAAAAAA"},
+		{0x199C01B66595861C, "GZwBtmWVhhw"},
+		{^uint64(0), "__________8"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.value).ToBase64URL(); got != tt.want {
+			t.Errorf("New(%#x).ToBase64URL() = %s, want %s", tt.value, got, tt.want)
+		}
+		if got, err := FromBase64URL(tt.want); err != nil || got.Uint64Value() != tt.value {
+			t.Errorf("FromBase64URL(%s) = %#x, %v, want %#x", tt.want, got.Uint64Value(), err, tt.value)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"GZwBtmWVhh",   // too short
+		"GZwBtmWVhhw=", // padded
+		"GZwBtmWVhh==", // padding in place of data
+		"GZwB+mWVhhw",  // standard alphabet
+		"GZwB/mWVhhw",  // standard alphabet
+		"GZwBtmWVhh\n", // newline, ignored by encoding/base64
+		"GZwBtmWVhhx",  // non-zero trailing bits
+		"GZwBtmWVh.w",
+	} {
+		if _, err := FromBase64URL(bad); err == nil {
+			t.Errorf("FromBase64URL(%q) error = nil, want error", bad)
+		}
+	}
+
+	r := rand.New(rand.NewSource(64))
+	for i := 0; i < 1000; i++ {
+		id := New(r.Uint64())
+		if got, err := FromBase64URL(id.ToBase64URL()); err != nil || got != id {
+			t.Fatalf("Base64URL round trip of %s = %s, %v", id.ToHex(), got.ToHex(), err)
+		}
+	}
+}
+
+func TestBase62And58_NotTimeSortable(t *testing.T) {
+	older, newer := New(61), New(62)
+	if older.ToBase62() < newer.ToBase62() {
+		t.Error("expected Base62 strings to sort out of time order for differing lengths")
+	}
+	older, newer = New(57), New(58)
+	if older.ToBase58() < newer.ToBase58() {
+		t.Error("expected Base58 strings to sort out of time order for differing lengths")
+	}
+}
+
+func TestParseDetailed(t *testing.T) {
+	want := New(0x199C01B66595861C)
+	tests := []struct {
+		input     string
+		grouping  GroupOption
+		irregular bool
+		letters   LetterCase
+		prefix    bool
+		norm      []string
+	}{
+		{"199C01B6659-5861C", GroupCanonical, false, CaseUpper, false, nil},
+		{"199c01b66595861c", GroupNone, false, CaseLower, false, []string{"uppercase", "regroup"}},
+		{"0x199C-01B6-6595-861C", GroupQuads, false, CaseUpper, true, []string{"strip prefix", "regroup"}},
+		{"199C01b6659-5861C", GroupCanonical, false, CaseMixed, false, []string{"uppercase"}},
+		{"19-9C01B6659-5861C", 0, true, CaseUpper, false, []string{"regroup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, info, err := ParseDetailed(tt.input)
+			if err != nil {
+				t.Fatalf("ParseDetailed() error = %v", err)
+			}
+			if !id.Equals(want) {
+				t.Errorf("ParseDetailed() id = %s, want %s", id.ToHex(), want.ToHex())
+			}
+			if info.Irregular != tt.irregular || (!tt.irregular && info.Grouping != tt.grouping) {
+				t.Errorf("Grouping = %d (irregular %v), want %d (irregular %v)", info.Grouping, info.Irregular, tt.grouping, tt.irregular)
+			}
+			if info.Case != tt.letters {
+				t.Errorf("Case = %s, want %s", info.Case, tt.letters)
+			}
+			if info.Prefix != tt.prefix {
+				t.Errorf("Prefix = %v, want %v", info.Prefix, tt.prefix)
+			}
+			if fmt.Sprint(info.Normalizations) != fmt.Sprint(tt.norm) {
+				t.Errorf("Normalizations = %v, want %v", info.Normalizations, tt.norm)
+			}
+			if info.Canonical() != (len(tt.norm) == 0) {
+				t.Errorf("Canonical() = %v, want %v", info.Canonical(), len(tt.norm) == 0)
+			}
+		})
+	}
+
+	if _, _, err := ParseDetailed("not-an-id"); err == nil {
+		t.Error("ParseDetailed(invalid) error = nil, want error")
+	}
+}
+
+func TestLint(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ms := at.UnixMilli()
+
+	codes := func(ws []Warning) []WarningCode {
+		var out []WarningCode
+		for _, w := range ws {
+			out = append(out, w.Code)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		id   Nano64
+		want []WarningCode
+	}{
+		{"clean", New(uint64(ms-1000)<<timestampShift | 0x1234), nil},
+		{"nil", Nil, []WarningCode{WarnNil}},
+		{"zero random", New(uint64(ms) << timestampShift), []WarningCode{WarnZeroRandom}},
+		{"seconds", New(uint64(ms/1000)<<timestampShift | 1), []WarningCode{WarnTooOld, WarnSeconds}},
+		{"too old", New(uint64(1000)<<timestampShift | 1), []WarningCode{WarnTooOld}},
+		{"within tolerance", New(uint64(ms+60_000)<<timestampShift | 1), nil},
+		{"future", New(uint64(ms+3_600_000)<<timestampShift | 1), []WarningCode{WarnFuture}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := codes(LintAt(tt.id, at, LintFutureTolerance))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("LintAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	restore := SetDefaultClock(func() int64 { return ms })
+	defer restore()
+	if ws := Lint(New(uint64(ms+3_600_000)<<timestampShift | 1)); len(ws) != 1 || ws[0].Code != WarnFuture {
+		t.Errorf("Lint() = %v, want one future warning", ws)
+	}
+}
+
+func TestFromUnixSeconds(t *testing.T) {
+	tests := []struct {
+		name    string
+		sec     int64
+		wantErr bool
+	}{
+		{"zero", 0, false},
+		{"typical", 1700000000, false},
+		{"max", maxTimestamp / 1000, false},
+		{"overflow", maxTimestamp/1000 + 1, true},
+		{"negative", -1, true},
+		{"int64 overflow", 1 << 62, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := FromUnixSeconds(tt.sec, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromUnixSeconds(%d) error = %v, wantErr %v", tt.sec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if id.GetTimestamp() != tt.sec*1000 {
+				t.Errorf("GetTimestamp() = %d, want %d", id.GetTimestamp(), tt.sec*1000)
+			}
+			if id.GetTimestampSeconds() != tt.sec {
+				t.Errorf("GetTimestampSeconds() = %d, want %d", id.GetTimestampSeconds(), tt.sec)
+			}
+		})
+	}
+
+	if got := New(1999 << timestampShift).GetTimestampSeconds(); got != 1 {
+		t.Errorf("GetTimestampSeconds() of 1999ms = %d, want 1", got)
+	}
+}
+
+func TestExhaustion(t *testing.T) {
+	if got := ExhaustionTime().UnixMilli(); got != maxTimestamp {
+		t.Errorf("ExhaustionTime() = %d, want %d", got, int64(maxTimestamp))
+	}
+	if got := TimeUntilExhaustion(); got != time.Duration(math.MaxInt64) {
+		t.Errorf("TimeUntilExhaustion() = %v, want saturated maximum", got)
+	}
+
+	restore := SetDefaultClock(func() int64 { return maxTimestamp - 10_000 })
+	defer restore()
+
+	if got := TimeUntilExhaustion(); got != 10*time.Second {
+		t.Errorf("TimeUntilExhaustion() = %v, want 10s", got)
+	}
+
+	ctx := context.Background()
+	select {
+	case <-NotifyBeforeExhaustion(ctx, nil, time.Minute):
+	case <-time.After(time.Second):
+		t.Error("NotifyBeforeExhaustion() did not fire within threshold")
+	}
+
+	// Cancelling stops the watcher without firing.
+	goroutines := runtime.NumGoroutine()
+	cctx, cancel := context.WithCancel(ctx)
+	early := NotifyBeforeExhaustion(cctx, nil, time.Second)
+	select {
+	case <-early:
+		t.Error("NotifyBeforeExhaustion() fired early")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > goroutines; {
+		if time.Now().After(deadline) {
+			t.Fatalf("NotifyBeforeExhaustion() goroutine still running after cancel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-early:
+		t.Error("NotifyBeforeExhaustion() fired after cancel")
+	default:
+	}
+}
+
+func TestGenerator_Exhaustion(t *testing.T) {
+	const epoch = 1_704_067_200_000 // 2024-01-01
+	clock := func() int64 { return epoch + maxTimestamp - 10_000 }
+	gen := NewGenerator(WithClock(clock), WithEpoch(epoch))
+	if got := gen.ExhaustionTime().UnixMilli(); got != epoch+maxTimestamp {
+		t.Errorf("ExhaustionTime() = %d, want %d", got, int64(epoch+maxTimestamp))
+	}
+	if got := gen.TimeUntilExhaustion(); got != 10*time.Second {
+		t.Errorf("TimeUntilExhaustion() = %v, want 10s", got)
+	}
+	select {
+	case <-NotifyBeforeExhaustion(context.Background(), gen, time.Minute):
+	case <-time.After(time.Second):
+		t.Error("NotifyBeforeExhaustion(gen) did not fire within threshold")
+	}
+}
+
+func TestRaceSafeRNG(t *testing.T) {
+	// An RNG with unsynchronized state, like a closure over *math/rand.Rand.
+	var calls int
+	unsafe := func(bits int) (uint32, error) {
+		calls++
+		return uint32(calls) & (1<<bits - 1), nil
+	}
+	rng := RaceSafeRNG(unsafe)
+
+	const workers, perWorker = 8, 1000
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if _, err := Generate(1, rng); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != workers*perWorker {
+		t.Errorf("calls = %d, want %d (lost updates)", calls, workers*perWorker)
+	}
+	if RaceSafeRNG(nil) != nil {
+		t.Error("RaceSafeRNG(nil) != nil")
+	}
+}
+
+func benchmarkEncryptedConfig(b *testing.B) *EncryptedIDConfig {
+	b.Helper()
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return config
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	config := benchmarkEncryptedConfig(b)
+	id := New(0x199C01B66595861C)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := config.Encrypt(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromEncryptedBytes(b *testing.B) {
+	config := benchmarkEncryptedConfig(b)
+	enc, err := config.Encrypt(New(0x199C01B66595861C))
+	if err != nil {
+		b.Fatal(err)
+	}
+	payload := enc.ToEncryptedBytes()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := config.FromEncryptedBytes(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncryptParallel(b *testing.B) {
+	config := benchmarkEncryptedConfig(b)
+	id := New(0x199C01B66595861C)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := config.Encrypt(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEncryptInto(b *testing.B) {
+	config := benchmarkEncryptedConfig(b)
+	id := New(0x199C01B66595861C)
+	var enc EncryptedNano64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := config.EncryptInto(&enc, id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncryptPooledParallel(b *testing.B) {
+	config := benchmarkEncryptedConfig(b)
+	id := New(0x199C01B66595861C)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			enc := AcquireEncrypted()
+			if err := config.EncryptInto(enc, id); err != nil {
+				b.Fatal(err)
+			}
+			ReleaseEncrypted(enc)
+		}
+	})
+}
+
+func TestEncryptedIDConfig_EncryptInto(t *testing.T) {
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	a, b := New(0x199C01B66595861C), New(0x199C01B66595861D)
+
+	var enc EncryptedNano64
+	if err := config.EncryptInto(&enc, a); err != nil {
+		t.Fatalf("EncryptInto() error = %v", err)
+	}
+	dec, err := config.FromEncryptedHex(enc.ToEncryptedHex())
+	if err != nil || dec.ID != a || enc.ID != a {
+		t.Fatalf("round trip = %v, %v; want %v", dec, err, a)
+	}
+
+	// Reuse overwrites the payload in place without allocating.
+	first := &enc.payload[0]
+	if allocs := testing.AllocsPerRun(50, func() {
+		if err := config.EncryptInto(&enc, b); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("EncryptInto() reuse allocs = %v, want 0", allocs)
+	}
+	if &enc.payload[0] != first || enc.ID != b {
+		t.Error("EncryptInto() did not reuse the payload buffer")
+	}
+	if dec, err := config.FromEncryptedBytes(enc.ToEncryptedBytes()); err != nil || dec.ID != b {
+		t.Errorf("reused payload decrypts to %v, %v; want %v", dec, err, b)
+	}
+
+	val, err := config.EncryptValue(a)
+	if err != nil {
+		t.Fatalf("EncryptValue() error = %v", err)
+	}
+	if dec, err := config.FromEncryptedBytes(val.ToEncryptedBytes()); err != nil || dec.ID != a {
+		t.Errorf("EncryptValue() decrypts to %v, %v; want %v", dec, err, a)
+	}
+
+	pooled := AcquireEncrypted()
+	if err := config.EncryptInto(pooled, a); err != nil {
+		t.Fatalf("EncryptInto(pooled) error = %v", err)
+	}
+	if dec, err := config.FromEncryptedHex(pooled.ToEncryptedHex()); err != nil || dec.ID != a {
+		t.Errorf("pooled payload decrypts to %v, %v; want %v", dec, err, a)
+	}
+	ReleaseEncrypted(pooled)
+	ReleaseEncrypted(nil)
+
+	var traces int
+	restore := SetHooks(&Hooks{OnEncrypt: func(EncryptTrace) { traces++ }})
+	defer restore()
+	_ = config.EncryptInto(&enc, a)
+	if traces != 1 {
+		t.Errorf("OnEncrypt called %d times, want 1", traces)
+	}
+}
+
+func TestEncryptedNano64_PayloadViews(t *testing.T) {
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	enc, err := config.Encrypt(New(0x199C01B66595861C))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	want := enc.ToEncryptedBytes()
+
+	if got := enc.PayloadString(); got != string(want) {
+		t.Errorf("PayloadString() = %x, want %x", got, want)
+	}
+	prefix := []byte("p:")
+	if got := enc.AppendPayload(prefix); !bytes.Equal(got, append([]byte("p:"), want...)) {
+		t.Errorf("AppendPayload() = %x, want prefix followed by payload", got)
+	}
+	if (EncryptedNano64{}).PayloadString() != "" {
+		t.Error("PayloadString() of empty value != \"\"")
+	}
+
+	buf := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() {
+		_ = enc.PayloadString()
+		buf = enc.AppendPayload(buf[:0])
+	}); allocs != 0 {
+		t.Errorf("PayloadString/AppendPayload allocs = %v, want 0", allocs)
+	}
+}
+
+func TestEncryptedIDConfig_SealOpen(t *testing.T) {
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	ad := []byte("test/v1")
+
+	sealed, err := config.Seal([]byte("hdr"), []byte("hello"), ad)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !bytes.HasPrefix(sealed, []byte("hdr")) || len(sealed) != 3+IVLength+5+16 {
+		t.Fatalf("Seal() = %x, want prefix and IV||ciphertext||tag", sealed)
+	}
+
+	plaintext, err := config.Open(sealed[3:], ad)
+	if err != nil || string(plaintext) != "hello" {
+		t.Errorf("Open() = %q, %v, want hello", plaintext, err)
+	}
+	if _, err := config.Open(sealed[3:], []byte("other/v1")); err == nil {
+		t.Error("Open() with different additional data error = nil, want error")
+	}
+	if _, err := config.Open(sealed[:10], ad); err == nil {
+		t.Error("Open() of short input error = nil, want error")
+	}
+}
+
+func TestRandomToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		tok, err := RandomToken(32)
+		if err != nil {
+			t.Fatalf("RandomToken() error = %v", err)
+		}
+		if len(tok) != 43 {
+			t.Errorf("RandomToken(32) length = %d, want 43", len(tok))
+		}
+		if strings.ContainsAny(tok, "+/=") {
+			t.Errorf("RandomToken() = %q, want URL-safe unpadded base64", tok)
+		}
+		if seen[tok] {
+			t.Fatalf("RandomToken() repeated %q", tok)
+		}
+		seen[tok] = true
+	}
+
+	for _, n := range []int{0, -1, MaxRandomTokenBytes + 1} {
+		if _, err := RandomToken(n); err == nil {
+			t.Errorf("RandomToken(%d) error = nil, want error", n)
+		}
+	}
+}
+
+func TestNonce96(t *testing.T) {
+	a, err := Nonce96()
+	if err != nil {
+		t.Fatalf("Nonce96() error = %v", err)
+	}
+	b, _ := Nonce96()
+	if a == b {
+		t.Error("Nonce96() returned the same nonce twice")
+	}
+	if a == [IVLength]byte{} {
+		t.Error("Nonce96() returned all zeros")
+	}
+}
+
+func TestOrderedSet(t *testing.T) {
+	var s OrderedSet
+	ids := []Nano64{New(3 << timestampShift), New(1 << timestampShift), New(2 << timestampShift)}
+	for _, id := range ids {
+		if !s.Add(id) {
+			t.Errorf("Add(%s) = false, want true", id.ToHex())
+		}
+	}
+	if s.Add(ids[0]) {
+		t.Error("Add() of duplicate = true, want false")
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", s.Len())
+	}
+	if got, want := s.String(), "[00000000001-00000 00000000002-00000 00000000003-00000]"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	var between []Nano64
+	s.AscendBetween(New(2<<timestampShift), New(3<<timestampShift), func(id Nano64) bool {
+		between = append(between, id)
+		return true
+	})
+	if len(between) != 1 || between[0].GetTimestamp() != 2 {
+		t.Errorf("AscendBetween() = %v, want [ts 2]", between)
+	}
+
+	var first []Nano64
+	s.Ascend(func(id Nano64) bool {
+		first = append(first, id)
+		return false
+	})
+	if len(first) != 1 || first[0].GetTimestamp() != 1 {
+		t.Errorf("Ascend() with early stop = %v, want [ts 1]", first)
+	}
+
+	if !s.Remove(ids[1]) || s.Remove(ids[1]) || s.Contains(ids[1]) {
+		t.Error("Remove() did not delete exactly once")
+	}
+	if got := s.IDs(); len(got) != 2 || !IDsSorted(got) {
+		t.Errorf("IDs() = %v, want 2 sorted IDs", got)
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[string]
+	m.Set(New(2<<timestampShift), "b")
+	m.Set(New(1<<timestampShift), "a")
+	m.Set(New(3<<timestampShift), "c")
+	m.Set(New(2<<timestampShift), "B")
+
+	if m.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", m.Len())
+	}
+	if v, ok := m.Get(New(2 << timestampShift)); !ok || v != "B" {
+		t.Errorf("Get() = %q, %v, want B", v, ok)
+	}
+	if _, ok := m.Get(New(9 << timestampShift)); ok {
+		t.Error("Get() of missing key ok = true")
+	}
+	if got, want := m.String(), "map[00000000001-00000:a 00000000002-00000:B 00000000003-00000:c]"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	var vals []string
+	m.AscendBetween(New(2<<timestampShift), New(^uint64(0)), func(_ Nano64, v string) bool {
+		vals = append(vals, v)
+		return true
+	})
+	if strings.Join(vals, "") != "Bc" {
+		t.Errorf("AscendBetween() values = %v, want [B c]", vals)
+	}
+
+	if !m.Delete(New(1<<timestampShift)) || m.Delete(New(1<<timestampShift)) {
+		t.Error("Delete() did not delete exactly once")
+	}
+	if keys := m.Keys(); len(keys) != 2 || keys[0].GetTimestamp() != 2 {
+		t.Errorf("Keys() = %v, want [ts 2, ts 3]", keys)
+	}
+}
+
+func TestCalendarBounds(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	check := func(name string, lo, hi Nano64, start, end time.Time) {
+		t.Helper()
+		if lo.GetTimestamp() != start.UnixMilli() || lo.GetRandom() != 0 {
+			t.Errorf("%s lo = %s, want min ID at %v", name, lo.ToHex(), start)
+		}
+		if hi.GetTimestamp() != end.UnixMilli()-1 || hi.GetRandom() != randomMask {
+			t.Errorf("%s hi = %s, want max ID just before %v", name, hi.ToHex(), end)
+		}
+	}
+
+	// US spring-forward day has 23 hours.
+	spring := time.Date(2025, 3, 9, 15, 0, 0, 0, ny)
+	lo, hi := DayBounds(spring)
+	check("DayBounds(spring)", lo, hi, time.Date(2025, 3, 9, 0, 0, 0, 0, ny), time.Date(2025, 3, 10, 0, 0, 0, 0, ny))
+	if got := hi.ToDate().Sub(lo.ToDate()); got != 23*time.Hour-time.Millisecond {
+		t.Errorf("spring-forward day spans %v, want 23h", got+time.Millisecond)
+	}
+
+	// US fall-back day has 25 hours.
+	lo, hi = DayBounds(time.Date(2025, 11, 2, 12, 0, 0, 0, ny))
+	if got := hi.ToDate().Sub(lo.ToDate()); got != 25*time.Hour-time.Millisecond {
+		t.Errorf("fall-back day spans %v, want 25h", got+time.Millisecond)
+	}
+
+	lo, hi = MonthBounds(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC))
+	check("MonthBounds", lo, hi, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	lo, hi = HourBounds(time.Date(2025, 1, 1, 10, 45, 30, 0, kolkata))
+	check("HourBounds(+05:30)", lo, hi, time.Date(2025, 1, 1, 10, 0, 0, 0, kolkata), time.Date(2025, 1, 1, 11, 0, 0, 0, kolkata))
+
+	lo, hi = HourBounds(time.Date(2025, 1, 1, 10, 45, 30, 0, time.UTC))
+	check("HourBounds(UTC)", lo, hi, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC))
+
+	id, _ := Generate(spring.UnixMilli(), nil)
+	lo, hi = DayBounds(spring)
+	if Compare(id, lo) < 0 || Compare(id, hi) > 0 {
+		t.Error("ID generated during the day is outside DayBounds")
+	}
+
+	lo, _ = DayBounds(time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC))
+	if !lo.IsNil() {
+		t.Errorf("DayBounds() before epoch lo = %s, want clamped to zero", lo.ToHex())
+	}
+}
+
+func TestNano64_AgeString(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	at := func(d time.Duration) Nano64 {
+		return New(uint64(now.Add(-d).UnixMilli()) << timestampShift)
+	}
+
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{500 * time.Millisecond, "just now"},
+		{45 * time.Second, "45s ago"},
+		{3 * time.Minute, "3m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{49 * time.Hour, "2d ago"},
+		{65 * 24 * time.Hour, "2mo ago"},
+		{800 * 24 * time.Hour, "2y ago"},
+		{-90 * time.Minute, "in 1h"},
+	}
+	for _, tt := range tests {
+		if got := at(tt.age).AgeString(now); got != tt.want {
+			t.Errorf("AgeString() for %v = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+
+	german := func(count int64, unit AgeUnit, future bool) string {
+		if unit == AgeHours && !future {
+			return fmt.Sprintf("vor %d Std.", count)
+		}
+		return EnglishAge(count, unit, future)
+	}
+	if got := at(3*time.Hour).AgeStringWith(now, german); got != "vor 3 Std." {
+		t.Errorf("AgeStringWith() = %q, want %q", got, "vor 3 Std.")
+	}
+}
+
+func TestCapacityHelpers(t *testing.T) {
+	if got := CapacityPerMillisecond(); got != 1048576 {
+		t.Errorf("CapacityPerMillisecond() = %d, want 1048576", got)
+	}
+	if got := TheoreticalMaxRate(); got != 1048576000 {
+		t.Errorf("TheoreticalMaxRate() = %v, want 1048576000", got)
+	}
+
+	tests := []struct {
+		rate float64
+		want float64
+	}{
+		{0, 0},
+		{-5, 0},
+		{500, 0},
+		{1000, 0},
+		{145_000, 145 * 144 / 2 / 1048576.0 * 1000},
+	}
+	for _, tt := range tests {
+		if got := EstimateCollisionsAtRate(tt.rate); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimateCollisionsAtRate(%v) = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+	if perMs := EstimateCollisionsAtRate(145_000) / 1000; perMs < 0.009 || perMs > 0.011 {
+		t.Errorf("collisions per ms at 145k/s = %v, want about 1%%", perMs)
+	}
+}
+
+func TestRNGInRange(t *testing.T) {
+	if _, err := RNGInRange(0); err == nil {
+		t.Error("RNGInRange(0) error = nil, want error")
+	}
+	for i := 0; i < 100; i++ {
+		if v, err := RNGInRange(1); err != nil || v != 0 {
+			t.Fatalf("RNGInRange(1) = %d, %v, want 0", v, err)
+		}
+	}
+
+	// With max = 3/4 of the range, modulo would double the weight of the
+	// lowest quarter; rejection sampling keeps the halves balanced.
+	const max = 3 << 30
+	low := 0
+	const draws = 20000
+	for i := 0; i < draws; i++ {
+		v, err := RNGInRange(max)
+		if err != nil {
+			t.Fatalf("RNGInRange() error = %v", err)
+		}
+		if v >= max {
+			t.Fatalf("RNGInRange() = %d, want < %d", v, max)
+		}
+		if v < max/2 {
+			low++
+		}
+	}
+	if frac := float64(low) / draws; frac < 0.47 || frac > 0.53 {
+		t.Errorf("fraction in lower half = %.3f, want about 0.5", frac)
+	}
+}
+
+func TestAnchoredClock(t *testing.T) {
+	clock := AnchoredClock()
+
+	first := clock()
+	if diff := time.Now().UnixMilli() - first; diff < 0 || diff > 1000 {
+		t.Errorf("AnchoredClock() = %d, off from wall clock by %dms", first, diff)
+	}
+
+	prev := first
+	for i := 0; i < 1000; i++ {
+		now := clock()
+		if now < prev {
+			t.Fatalf("AnchoredClock() went backwards: %d < %d", now, prev)
+		}
+		prev = now
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if elapsed := clock() - first; elapsed < 20 {
+		t.Errorf("AnchoredClock() advanced %dms over a 20ms sleep", elapsed)
+	}
+
+	// It can serve as the package default clock.
+	restore := SetDefaultClock(clock)
+	defer restore()
+	id, err := GenerateDefault()
+	if err != nil || id.GetTimestamp() < first {
+		t.Errorf("GenerateDefault() with anchored clock = %s, %v", id.ToHex(), err)
+	}
+}
+
+func TestCompatSnowflakeGenerator(t *testing.T) {
+	if _, err := CompatSnowflakeGenerator(256); err == nil {
+		t.Error("CompatSnowflakeGenerator(256) error = nil, want error")
+	}
+	if _, err := CompatSnowflakeGenerator(-1); err == nil {
+		t.Error("CompatSnowflakeGenerator(-1) error = nil, want error")
+	}
+
+	g, err := CompatSnowflakeGenerator(0xAB)
+	if err != nil {
+		t.Fatalf("CompatSnowflakeGenerator() error = %v", err)
+	}
+	if g.NodeID() != 0xAB {
+		t.Errorf("NodeID() = %d, want %d", g.NodeID(), 0xAB)
+	}
+
+	const ts = int64(1760000000000)
+	var prev Nano64
+	for i := 0; i < 5000; i++ {
+		id, err := g.GenerateAt(ts)
+		if err != nil {
+			t.Fatalf("GenerateAt() error = %v", err)
+		}
+		if i > 0 && Compare(id, prev) <= 0 {
+			t.Fatalf("GenerateAt() not increasing at %d: %s <= %s", i, id.ToHex(), prev.ToHex())
+		}
+		if id.SnowflakeNode() != 0xAB {
+			t.Fatalf("SnowflakeNode() = %d, want %d", id.SnowflakeNode(), 0xAB)
+		}
+		if want := i % 4096; id.SnowflakeSequence() != want {
+			t.Fatalf("SnowflakeSequence() = %d, want %d", id.SnowflakeSequence(), want)
+		}
+		if want := ts + int64(i/4096); id.GetTimestamp() != want {
+			t.Fatalf("GetTimestamp() = %d, want %d", id.GetTimestamp(), want)
+		}
+		prev = id
+	}
+
+	// A clock stepping backwards is clamped.
+	id, err := g.GenerateAt(ts - 1000)
+	if err != nil || Compare(id, prev) <= 0 {
+		t.Errorf("GenerateAt(earlier) = %s, %v; want after %s", id.ToHex(), err, prev.ToHex())
+	}
+
+	// Legacy readers see a positive Snowflake with the 4 ms tick as its timestamp.
+	sf := id.Snowflake()
+	if sf <= 0 {
+		t.Errorf("Snowflake() = %d, want positive", sf)
+	}
+	if got := sf >> 22; got != id.GetTimestamp()>>2 {
+		t.Errorf("Snowflake timestamp = %d, want %d", got, id.GetTimestamp()>>2)
+	}
+	if got := sf & 0xFFF; int(got) != id.SnowflakeSequence() {
+		t.Errorf("Snowflake sequence = %d, want %d", got, id.SnowflakeSequence())
+	}
+}
+
+func TestReadHexIDs(t *testing.T) {
+	a, b := New(0x199C01B66595861C), New(0x199C01B66595861D)
+	input := "199C01B6659-5861C\r\n\n  199c01b66595861d \nnot-an-id\n" + b.ToHex()
+
+	var ids []Nano64
+	var errs []error
+	for id, err := range ReadHexIDs(strings.NewReader(input), '\n') {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if want := []Nano64{a, b, b}; !slices.Equal(ids, want) {
+		t.Errorf("ReadHexIDs() ids = %v, want %v", ids, want)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "record 4") {
+		t.Errorf("ReadHexIDs() errs = %v, want one error for record 4", errs)
+	}
+
+	// Comma separated, stopping early.
+	n := 0
+	for range ReadHexIDs(strings.NewReader(a.ToHex()+","+b.ToHex()+","+a.ToHex()), ',') {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("ReadHexIDs() yielded %d after break, want 2", n)
+	}
+
+	// An oversized record ends iteration with an error.
+	var last error
+	count := 0
+	for _, err := range ReadHexIDs(strings.NewReader(strings.Repeat("A", 1000)+"\n"+a.ToHex()), '\n') {
+		count++
+		last = err
+	}
+	if count != 1 || last == nil {
+		t.Errorf("ReadHexIDs(oversized) yielded %d values, last error %v", count, last)
+	}
+}
+
+func TestCompactSlice(t *testing.T) {
+	const base = int64(1760000000000)
+	var ids []Nano64
+	for i := 0; i < 20000; i++ {
+		id, _ := Generate(base+int64(i%30000)/2, nil)
+		ids = append(ids, id)
+	}
+	ids = append(ids, ids[0], ids[1]) // duplicates collapse
+
+	s := Compact(ids)
+	want := slices.Clone(ids)
+	SortIDs(want)
+	want = slices.Compact(want)
+	if !slices.Equal(s.IDs(), want) {
+		t.Fatal("Compact().IDs() does not match sorted, de-duplicated input")
+	}
+	if s.Len() != len(want) || s.At(0) != want[0] {
+		t.Errorf("Len() = %d, At(0) = %s", s.Len(), s.At(0).ToHex())
+	}
+	if i, ok := s.Index(want[100]); !ok || i != 100 {
+		t.Errorf("Index() = %d, %v; want 100, true", i, ok)
+	}
+	if s.Contains(New(want[0].value - 1)) {
+		t.Error("Contains() = true for a missing ID")
+	}
+
+	c := s.Compress()
+	if c.Len() != s.Len() {
+		t.Fatalf("Compress().Len() = %d, want %d", c.Len(), s.Len())
+	}
+	for i, id := range want {
+		if !c.Contains(id) || c.At(i) != id {
+			t.Fatalf("CompressedSlice mismatch at %d", i)
+		}
+	}
+	if c.Contains(New(want[len(want)-1].value + 1)) {
+		t.Error("CompressedSlice.Contains() = true for a missing ID")
+	}
+	if !slices.Equal(c.Decompress().IDs(), want) {
+		t.Error("Decompress() did not round-trip")
+	}
+	if c.SizeBytes() >= s.SizeBytes()*6/10 {
+		t.Errorf("compressed size %d not about half of %d", c.SizeBytes(), s.SizeBytes())
+	}
+
+	n := 0
+	c.Ascend(func(Nano64) bool { n++; return n < 5 })
+	if n != 5 {
+		t.Errorf("Ascend() visited %d after stopping, want 5", n)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(string) (Nano64, error)
+		input    string
+		offset   int
+		expected string
+		message  string
+	}{
+		{"FromHex bad digit", FromHex, "199C01B6659-5861G", 16, "hex digit",
+			`invalid ID "199C01B6659-5861G": unexpected 'G' at position 16, expected hex digit`},
+		{"FromHex prefixed", FromHex, "0x199C0-1B66595861Z", 18, "hex digit", ""},
+		{"FromHex short", FromHex, "199C01B6659", -1, "16 hex digits",
+			`invalid ID "199C01B6659": expected 16 hex digits`},
+		{"Parse bad digit", Parse, "199C01B6659-586!C", 15, "hex digit", ""},
+		{"Parse double dash", Parse, "199C--01B66595861C", 5, "hex digit", ""},
+		{"Parse truncated", Parse, "199C01B6659-58", 14, "16 hex digits",
+			`invalid ID "199C01B6659-58": unexpected end of input at position 14, expected 16 hex digits`},
+		{"Parse too long", Parse, "199C01B6659-5861C0", 17, "end of input after 16 hex digits", ""},
+		{"strict lowercase", func(s string) (Nano64, error) { return ParseWithOptions(s, ParseOptions{}) },
+			"199c01B6659-5861C", 3, "uppercase hex digit", ""},
+		{"strict undashed", func(s string) (Nano64, error) { return ParseWithOptions(s, ParseOptions{}) },
+			"199C01B66595861C", -1, "'-' between timestamp and random parts", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.input)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error = %v (%T), want *ParseError", err, err)
+			}
+			if pe.Input != tt.input || pe.Offset != tt.offset || pe.Expected != tt.expected {
+				t.Errorf("ParseError = %+v, want offset %d expected %q", *pe, tt.offset, tt.expected)
+			}
+			if tt.message != "" && pe.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", pe.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	id := New(0x199C01B66595861C)
+
+	anon := Anonymize(id, key)
+	if anon.GetTimestamp() != id.GetTimestamp() {
+		t.Errorf("Anonymize() timestamp = %d, want %d", anon.GetTimestamp(), id.GetTimestamp())
+	}
+	if anon == id {
+		t.Error("Anonymize() returned the original ID")
+	}
+	if again := Anonymize(id, key); again != anon {
+		t.Errorf("Anonymize() not deterministic: %s vs %s", again.ToHex(), anon.ToHex())
+	}
+	if other := Anonymize(id, []byte("another key")); other == anon {
+		t.Error("Anonymize() with a different key gave the same pseudonym")
+	}
+	if neighbour := Anonymize(New(id.value+1), key); neighbour.GetRandom() == anon.GetRandom() {
+		t.Error("Anonymize() of adjacent IDs share a random field")
+	}
+	if got := Anonymize(Nil, key); !got.IsNil() {
+		t.Errorf("Anonymize(Nil) = %s, want Nil", got.ToHex())
+	}
+}
+
+func TestGeneralizeForExport(t *testing.T) {
+	at := time.Date(2025, 10, 7, 19, 17, 25, 209e6, time.UTC)
+	id, _ := Generate(at.UnixMilli(), nil)
+
+	tests := []struct {
+		granularity time.Duration
+		want        time.Time
+	}{
+		{0, at},
+		{time.Second, time.Date(2025, 10, 7, 19, 17, 25, 0, time.UTC)},
+		{time.Hour, time.Date(2025, 10, 7, 19, 0, 0, 0, time.UTC)},
+		{24 * time.Hour, time.Date(2025, 10, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := GeneralizeForExport(id, tt.granularity)
+		if got.GetRandom() != 0 {
+			t.Errorf("GeneralizeForExport(%v) random = %d, want 0", tt.granularity, got.GetRandom())
+		}
+		if !got.ToDate().Equal(tt.want) {
+			t.Errorf("GeneralizeForExport(%v) = %v, want %v", tt.granularity, got.ToDate(), tt.want)
+		}
+	}
+
+	other, _ := Generate(at.Add(10*time.Minute).UnixMilli(), nil)
+	ids := []Nano64{id, other}
+	out := GeneralizeAllForExport(ids, time.Hour)
+	if len(out) != 2 || out[0] != out[1] {
+		t.Errorf("GeneralizeAllForExport() = %v, want two equal IDs", out)
+	}
+	if ids[0] != id {
+		t.Error("GeneralizeAllForExport() modified its input")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	at := func(ms int64, r uint64) Nano64 { return New(uint64(ms)<<timestampShift | r) }
+	const base = int64(1760000400000) // 2025-10-09T09:00:00Z
+	a := []Nano64{at(base, 1), at(base, 2), at(base+10, 1), at(base+10, 1), at(base+3_600_000, 5)}
+	b := []Nano64{at(base, 2), at(base+10, 1), at(base+20, 7), at(base+7_200_000, 1)}
+
+	onlyA, onlyB := Diff(a, b)
+	if want := []Nano64{at(base, 1), at(base+10, 1), at(base+3_600_000, 5)}; !slices.Equal(onlyA, want) {
+		t.Errorf("Diff() onlyA = %v, want %v", onlyA, want)
+	}
+	if want := []Nano64{at(base+20, 7), at(base+7_200_000, 1)}; !slices.Equal(onlyB, want) {
+		t.Errorf("Diff() onlyB = %v, want %v", onlyB, want)
+	}
+	if x, y := Diff(a, a); len(x) != 0 || len(y) != 0 {
+		t.Errorf("Diff(a, a) = %v, %v; want empty", x, y)
+	}
+
+	report := SummarizeDiff(onlyA, onlyB, time.Hour)
+	if report.OnlyA != 3 || report.OnlyB != 2 {
+		t.Errorf("SummarizeDiff() totals = %d, %d; want 3, 2", report.OnlyA, report.OnlyB)
+	}
+	want := []DiffBucket{
+		{Start: time.UnixMilli(base).UTC(), OnlyA: 2, OnlyB: 1},
+		{Start: time.UnixMilli(base + 3_600_000).UTC(), OnlyA: 1},
+		{Start: time.UnixMilli(base + 7_200_000).UTC(), OnlyB: 1},
+	}
+	if !slices.Equal(report.Buckets, want) {
+		t.Errorf("SummarizeDiff() buckets = %+v, want %+v", report.Buckets, want)
+	}
+	if !strings.Contains(report.String(), "2025-10-09T10:00:00Z  A:1  B:0") {
+		t.Errorf("String() =\n%s", report.String())
+	}
+}
+
+func TestBookmark(t *testing.T) {
+	a, b := New(0x199C01B66595861C), New(0x199C01B66595861D)
+
+	var start Bookmark
+	if !start.Includes(a) || start.Includes(Nil) {
+		t.Error("zero Bookmark should include every non-nil ID")
+	}
+
+	after := BookmarkAfter(a)
+	if after.Includes(a) || !after.Includes(b) {
+		t.Error("BookmarkAfter() should exclude its ID and include later ones")
+	}
+	at := BookmarkAt(a)
+	if !at.Includes(a) || at.Includes(New(a.value-1)) {
+		t.Error("BookmarkAt() should include its ID and exclude earlier ones")
+	}
+	if got := at.Advance(b); got != BookmarkAfter(b) {
+		t.Errorf("Advance() = %v, want %v", got, BookmarkAfter(b))
+	}
+	if got := BookmarkAfter(b).Advance(a); got != BookmarkAfter(b) {
+		t.Errorf("Advance() moved backwards to %v", got)
+	}
+
+	if got := after.Where("id", "$1"); got != "id > $1" {
+		t.Errorf("Where() = %q", got)
+	}
+	if got := at.Where("events.id", "?"); got != "events.id >= ?" {
+		t.Errorf("Where() = %q", got)
+	}
+
+	for _, bm := range []Bookmark{after, at, {}} {
+		parsed, err := ParseBookmark(bm.String())
+		if err != nil || parsed != bm {
+			t.Errorf("ParseBookmark(%q) = %v, %v", bm.String(), parsed, err)
+		}
+	}
+	if at.String() != "at:199C01B6659-5861C" {
+		t.Errorf("String() = %q", at.String())
+	}
+	for _, bad := range []string{"199C01B6659-5861C", ">199C01B6659-5861C", "after:", "at:nothex"} {
+		if _, err := ParseBookmark(bad); err == nil {
+			t.Errorf("ParseBookmark(%q) error = nil, want error", bad)
+		}
+	}
+
+	data, err := json.Marshal(struct{ Progress Bookmark }{at})
+	if err != nil || string(data) != `{"Progress":"at:199C01B6659-5861C"}` {
+		t.Errorf("json.Marshal() = %s, %v", data, err)
+	}
+	var decoded struct{ Progress Bookmark }
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Progress != at {
+		t.Errorf("json.Unmarshal() = %v, %v", decoded.Progress, err)
+	}
+
+	v, _ := after.Value()
+	var scanned Bookmark
+	if err := scanned.Scan(v); err != nil || scanned != after {
+		t.Errorf("Scan(Value()) = %v, %v", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Scan(int) error = nil, want error")
+	}
+}
+
+func TestPressureMeter(t *testing.T) {
+	var m pressureMeter
+	half := CapacityPerMillisecond() / 2
+	for ms := int64(1); ms <= 50; ms++ {
+		for i := 0; i < half; i++ {
+			m.record(ms)
+		}
+	}
+	if got := m.value(51); math.Abs(got-0.5) > 0.01 {
+		t.Errorf("value() under steady half load = %.3f, want 0.5", got)
+	}
+	if got := m.value(100); got > 0.01 {
+		t.Errorf("value() after 50 idle ms = %.4f, want near 0", got)
+	}
+}
+
+func TestMonotonicPressure(t *testing.T) {
+	monotonic.reset()
+
+	const ts = int64(1760000000000)
+	restore := SetDefaultClock(func() int64 { return ts })
+	defer restore()
+	// Start the sequence at 0 so it cannot wrap into the next millisecond.
+	restoreRNG := SetDefaultRNG(func(int) (uint32, error) { return 0, nil })
+	defer restoreRNG()
+
+	if got := MonotonicPressure(); got != 0 {
+		t.Errorf("MonotonicPressure() idle = %v, want 0", got)
+	}
+	for i := 0; i < 10000; i++ {
+		if _, err := GenerateMonotonicDefault(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := pressureAlpha * 10000 / float64(CapacityPerMillisecond())
+	if got := MonotonicPressure(); math.Abs(got-want) > 1e-9 {
+		t.Errorf("MonotonicPressure() = %v, want %v", got, want)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a := New(0x199C01B66595861C)
+	b := New(0x199C01B665E5861C)
+	if got := DistanceIDs(a, b); got != 5<<RandomBits {
+		t.Errorf("DistanceIDs(a, b) = %d, want %d", got, 5<<RandomBits)
+	}
+	if got := DistanceIDs(b, a); got != 5<<RandomBits {
+		t.Errorf("DistanceIDs(b, a) = %d, want %d (no wrap-around)", got, 5<<RandomBits)
+	}
+	if got := DistanceIDs(Nil, New(math.MaxUint64)); got != math.MaxUint64 {
+		t.Errorf("DistanceIDs(Nil, max) = %d", got)
+	}
+	if got := TimeDistance(a, b); got != 5*time.Millisecond {
+		t.Errorf("TimeDistance(a, b) = %v, want 5ms", got)
+	}
+	if got := TimeDistance(b, a); got != -5*time.Millisecond {
+		t.Errorf("TimeDistance(b, a) = %v, want -5ms", got)
+	}
+	if got := TimeDistance(Nil, New(^uint64(0))); got != math.MaxInt64 {
+		t.Errorf("TimeDistance(Nil, max) = %v, want saturated at MaxInt64", got)
+	}
+	if got := TimeDistance(New(^uint64(0)), Nil); got != math.MinInt64 {
+		t.Errorf("TimeDistance(max, Nil) = %v, want saturated at MinInt64", got)
+	}
+}
+
+// googleStyleUUID mimics github.com/google/uuid.UUID, a named [16]byte with MarshalBinary.
+type googleStyleUUID [16]byte
+
+func (u googleStyleUUID) MarshalBinary() ([]byte, error) { return u[:], nil }
+
+func TestScanFlexible(t *testing.T) {
+	want := New(0x199C01B66595861C)
+	u := want.UUIDv8()
+
+	inputs := []interface{}{
+		want.ToBytes(),
+		int64(want.value),
+		u[:],
+		u,
+		googleStyleUUID(u),
+		want.UUIDString(),
+		[]byte(want.UUIDString()),
+		strings.ReplaceAll(want.UUIDString(), "-", ""),
+		want.ToHex(),
+		[]byte(want.ToHex()),
+		[]byte("199C01B66595861C"),
+		[]byte("199c01b66595861c"),
+	}
+	for _, in := range inputs {
+		var got Nano64
+		if err := ScanFlexible(&got).Scan(in); err != nil {
+			t.Errorf("Scan(%T %v) error = %v", in, in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Scan(%T) = %s, want %s", in, got.ToHex(), want.ToHex())
+		}
+	}
+
+	random := [16]byte{0: 0xAB, 6: 0x4F, 8: 0xBF, 15: 1}
+	for _, bad := range []interface{}{random[:], "not-an-id", 3.14} {
+		var got Nano64
+		if err := ScanFlexible(&got).Scan(bad); err == nil {
+			t.Errorf("Scan(%T %v) error = nil, want error", bad, bad)
+		}
+	}
+
+	// Works against a real driver with a mixed column.
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	if _, err := db.Exec(`CREATE TABLE mixed (id BLOB)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO mixed VALUES (?), (?)`, want.ToBytes(), u[:]); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query(`SELECT id FROM mixed`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var got Nano64
+		if err := rows.Scan(ScanFlexible(&got)); err != nil || got != want {
+			t.Errorf("rows.Scan(ScanFlexible) = %s, %v", got.ToHex(), err)
+		}
+	}
+}
+
+func TestDualFormat(t *testing.T) {
+	id := New(0x199C01B66595861C)
+
+	data, err := json.Marshal(DualFormat{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"199C01B6659-5861C","id_int":1845351830215034396}`; string(data) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", data, want)
+	}
+
+	custom, err := json.Marshal(DualFormat{ID: id, HexField: "user_id", IntField: "legacy_user_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"user_id":"199C01B6659-5861C","legacy_user_id":1845351830215034396}`; string(custom) != want {
+		t.Errorf("MarshalJSON() custom = %s, want %s", custom, want)
+	}
+	if _, err := json.Marshal(DualFormat{ID: id, HexField: "x", IntField: "x"}); err == nil {
+		t.Error("MarshalJSON() with equal field names error = nil, want error")
+	}
+
+	for _, in := range []string{
+		string(data),
+		`{"id":"199C01B6659-5861C"}`,
+		`{"id_int":1845351830215034396}`,
+		`{"id_int":"1845351830215034396"}`,
+	} {
+		var got DualFormat
+		if err := json.Unmarshal([]byte(in), &got); err != nil || got.ID != id {
+			t.Errorf("Unmarshal(%s) = %s, %v", in, got.ID.ToHex(), err)
+		}
+	}
+
+	got := DualFormat{HexField: "user_id", IntField: "legacy_user_id"}
+	if err := json.Unmarshal([]byte(`{"legacy_user_id":1845351830215034396}`), &got); err != nil || got.ID != id {
+		t.Errorf("Unmarshal() custom = %s, %v", got.ID.ToHex(), err)
+	}
+
+	for _, bad := range []string{
+		`{}`,
+		`{"id":"199C01B6659-5861C","id_int":1}`,
+		`{"id_int":-1}`,
+		`{"id":"zzz"}`,
+		`[]`,
+	} {
+		var got DualFormat
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("Unmarshal(%s) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestBase32(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	enc := id.ToBase32()
+	if len(enc) != Base32Length {
+		t.Fatalf("ToBase32() = %q, want %d chars", enc, Base32Length)
+	}
+	if got, err := FromBase32(enc); err != nil || got != id {
+		t.Errorf("FromBase32(%q) = %s, %v", enc, got.ToHex(), err)
+	}
+	if got := Nil.ToBase32(); got != "0000000000000" {
+		t.Errorf("Nil.ToBase32() = %q", got)
+	}
+	if got := New(math.MaxUint64).ToBase32(); got != "FZZZZZZZZZZZZ" {
+		t.Errorf("max.ToBase32() = %q", got)
+	}
+
+	// Fixed width keeps lexicographic order equal to time order.
+	prev := New(0).ToBase32()
+	for _, v := range []uint64{1, 31, 32, 1 << 40, 1<<63 - 1, 1 << 63} {
+		cur := New(v).ToBase32()
+		if cur <= prev {
+			t.Errorf("ToBase32(%d) = %q does not sort after %q", v, cur, prev)
+		}
+		prev = cur
+	}
+
+	for _, bad := range []string{"", "000000000000", "G000000000000", "000000000000I", "00000000000000"} {
+		if _, err := FromBase32(bad); err == nil {
+			t.Errorf("FromBase32(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestPatternsAndIsValidHex(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		id, _ := GenerateDefault()
+		if !HexRegexp.MatchString(id.ToHex()) || !IsValidHex(id.ToHex()) {
+			t.Fatalf("%s does not validate", id.ToHex())
+		}
+		if !Base32Regexp.MatchString(id.ToBase32()) {
+			t.Fatalf("%s does not match Base32Pattern", id.ToBase32())
+		}
+	}
+	if !Base32Regexp.MatchString(New(math.MaxUint64).ToBase32()) {
+		t.Error("max ID does not match Base32Pattern")
+	}
+
+	for _, s := range []string{"", "199C01B66595861C", "199c01b6659-5861c", "199C01B6659-5861G", "199C01B665-95861C", "199C01B6659-5861C0", "0x199C01B6659-5861C"} {
+		if got, want := IsValidHex(s), HexRegexp.MatchString(s); got != want {
+			t.Errorf("IsValidHex(%q) = %v, HexRegexp = %v", s, got, want)
+		}
+	}
+	if n := testing.AllocsPerRun(100, func() { IsValidHex("199C01B6659-5861C") }); n != 0 {
+		t.Errorf("IsValidHex() allocs = %v, want 0", n)
+	}
+}
+
+func TestGenerator_IsolatedMonotonicState(t *testing.T) {
+	const ts = int64(1760000000000)
+	// A zero RNG starts each sequence at 0 so it cannot wrap into the next millisecond.
+	zero := WithRNG(func(int) (uint32, error) { return 0, nil })
+	a := NewGenerator(WithClock(func() int64 { return ts }), zero)
+	b := NewGenerator(WithClock(func() int64 { return ts }), zero)
+
+	// Advance the package-level sequence far ahead; generators are unaffected.
+	if _, err := GenerateMonotonic(ts+1_000_000, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var prev Nano64
+	for i := 0; i < 1000; i++ {
+		id, err := a.GenerateMonotonicNow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.GetTimestamp() != ts {
+			t.Fatalf("GenerateMonotonicNow() timestamp = %d, want %d", id.GetTimestamp(), ts)
+		}
+		if i > 0 && Compare(id, prev) <= 0 {
+			t.Fatalf("GenerateMonotonicNow() not increasing: %s <= %s", id.ToHex(), prev.ToHex())
+		}
+		prev = id
+	}
+	if id, _ := b.GenerateMonotonicNow(); id.GetTimestamp() != ts {
+		t.Errorf("second generator shares state: timestamp %d", id.GetTimestamp())
+	}
+
+	// A clock step backwards is clamped per generator.
+	if id, err := a.GenerateMonotonic(ts - 500); err != nil || Compare(id, prev) <= 0 {
+		t.Errorf("GenerateMonotonic(earlier) = %s, %v; want after %s", id.ToHex(), err, prev.ToHex())
+	}
+	if _, err := a.GenerateMonotonic(-1); err == nil {
+		t.Error("GenerateMonotonic(-1) error = nil, want error")
+	}
+	if p := a.Pressure(); p <= 0 || p > 1 {
+		t.Errorf("Pressure() = %v, want in (0, 1]", p)
+	}
+}
+
+func TestGenerator_ClockRNGAndHooks(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	rng := func(bits int) (uint32, error) { return uint32(src.Int63()) & (1<<bits - 1), nil }
+	g := NewGenerator(WithClock(func() int64 { return 42_000 }), WithRNG(rng))
+
+	var calls atomic.Int32
+	restore := SetHooks(&Hooks{OnGenerate: func(GenerateTrace) { calls.Add(1) }})
+	defer restore()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id, err := g.GenerateNow()
+				if err != nil || id.GetTimestamp() != 42_000 {
+					t.Errorf("GenerateNow() = %s, %v", id.ToHex(), err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 800 {
+		t.Errorf("OnGenerate called %d times, want 800", calls.Load())
+	}
+
+	// Without options a generator follows the package defaults.
+	restoreClock := SetDefaultClock(func() int64 { return 7_000 })
+	defer restoreClock()
+	if id, err := NewGenerator().GenerateNow(); err != nil || id.GetTimestamp() != 7_000 {
+		t.Errorf("default generator GenerateNow() = %s, %v", id.ToHex(), err)
+	}
+}
+
+func TestGenerator_GenerateAsync(t *testing.T) {
+	g := NewGenerator(WithClock(func() int64 { return 42_000 }))
+
+	results := make(chan Nano64, 16)
+	for i := 0; i < cap(results); i++ {
+		g.GenerateAsync(func(id Nano64, err error) {
+			if err != nil {
+				t.Errorf("GenerateAsync() error = %v", err)
+			}
+			results <- id
+		})
+	}
+	seen := make(map[Nano64]bool)
+	for i := 0; i < cap(results); i++ {
+		id := <-results
+		if id.GetTimestamp() != 42_000 {
+			t.Errorf("GenerateAsync() timestamp = %d, want 42000", id.GetTimestamp())
+		}
+		seen[id] = true
+	}
+	if len(seen) < cap(results)-1 {
+		t.Errorf("GenerateAsync() produced %d distinct IDs out of %d", len(seen), cap(results))
+	}
+
+	errs := make(chan error, 1)
+	NewGenerator(WithClock(func() int64 { return -1 })).GenerateAsync(func(_ Nano64, err error) {
+		errs <- err
+	})
+	if err := <-errs; err == nil {
+		t.Error("GenerateAsync() with a negative clock error = nil, want error")
+	}
+}
+
+func TestGeneratorFromManifest(t *testing.T) {
+	g, err := GeneratorFromManifest(manifestJSON(t, CurrentManifest()))
+	if err != nil {
+		t.Fatalf("GeneratorFromManifest(CurrentManifest()) error = %v", err)
+	}
+	data, err := g.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	m, err := ParseManifest(data)
+	if err != nil {
+		t.Fatalf("ParseManifest(Manifest()) error = %v", err)
+	}
+	if m.Fingerprint() != SchemeFingerprint() {
+		t.Error("Manifest() does not match the package scheme")
+	}
+
+	custom := CurrentManifest()
+	custom.Layout.Epoch = 1700000000000
+	g, err = GeneratorFromManifest(manifestJSON(t, custom))
+	if err != nil {
+		t.Fatalf("GeneratorFromManifest() with a custom epoch error = %v", err)
+	}
+	if g.Epoch() != custom.Layout.Epoch {
+		t.Errorf("Epoch() = %d, want %d", g.Epoch(), custom.Layout.Epoch)
+	}
+	if g.manifest().Fingerprint() != custom.Fingerprint() {
+		t.Error("Manifest() does not carry the manifest epoch")
+	}
+
+	other := CurrentManifest()
+	other.Layout.NodeBits = 8
+	if _, err := GeneratorFromManifest(manifestJSON(t, other)); err == nil {
+		t.Error("GeneratorFromManifest() with node bits but no node ID error = nil, want error")
+	}
+	if _, err := GeneratorFromManifest(manifestJSON(t, other), WithNodeID(3, 6)); err == nil {
+		t.Error("GeneratorFromManifest() with mismatched node bits error = nil, want error")
+	}
+	g, err = GeneratorFromManifest(manifestJSON(t, other), WithNodeID(3, 8))
+	if err != nil {
+		t.Fatalf("GeneratorFromManifest() with node ID error = %v", err)
+	}
+	if g.manifest().Fingerprint() != other.Fingerprint() {
+		t.Error("Manifest() does not carry the node bits")
+	}
+
+	negative := CurrentManifest()
+	negative.Layout.Epoch = -1
+	if _, err := GeneratorFromManifest(manifestJSON(t, negative)); err == nil {
+		t.Error("GeneratorFromManifest() with a negative epoch error = nil, want error")
+	}
+	if _, err := GeneratorFromManifest([]byte("{")); err == nil {
+		t.Error("GeneratorFromManifest() with invalid JSON error = nil, want error")
+	}
+}
+
+// manifestJSON encodes m for GeneratorFromManifest.
+func manifestJSON(t *testing.T, m Manifest) []byte {
+	t.Helper()
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return data
+}
+
+func TestGenerator_NodeID(t *testing.T) {
+	zero := func(int) (uint32, error) { return 0, nil }
+	ones := func(bits int) (uint32, error) { return 1<<bits - 1, nil }
+	a := NewGenerator(WithNodeID(5, 6), WithClock(func() int64 { return 1000 }), WithRNG(ones))
+	b := NewGenerator(WithNodeID(6, 6), WithClock(func() int64 { return 1000 }), WithRNG(ones))
+
+	for _, g := range []*Generator{a, b} {
+		id, err := g.GenerateNow()
+		if err != nil {
+			t.Fatalf("GenerateNow() error = %v", err)
+		}
+		if id.GetNodeID(6) != g.nodeID {
+			t.Errorf("GetNodeID(6) = %d, want %d", id.GetNodeID(6), g.nodeID)
+		}
+		if id.GetRandom()&(1<<14-1) != 1<<14-1 {
+			t.Errorf("GetRandom() = %#x, want random bits below the node preserved", id.GetRandom())
+		}
+	}
+
+	// The per-ms sequence wraps within the 14 sequence bits without touching the node.
+	g := NewGenerator(WithNodeID(5, 6), WithClock(func() int64 { return 1000 }), WithRNG(zero))
+	var prev Nano64
+	for i := 0; i < 1<<14+1; i++ {
+		id, err := g.GenerateMonotonicNow()
+		if err != nil {
+			t.Fatalf("GenerateMonotonicNow() error = %v", err)
+		}
+		if id.GetNodeID(6) != 5 {
+			t.Fatalf("GetNodeID(6) = %d after %d IDs, want 5", id.GetNodeID(6), i)
+		}
+		if i > 0 && Compare(id, prev) <= 0 {
+			t.Fatalf("IDs not increasing: %s then %s", prev.ToHex(), id.ToHex())
+		}
+		prev = id
+	}
+	if prev.GetTimestamp() != 1001 {
+		t.Errorf("GetTimestamp() = %d after sequence wrap, want 1001", prev.GetTimestamp())
+	}
+
+	for _, tt := range []struct {
+		id   uint32
+		bits int
+	}{{0, 0}, {0, RandomBits}, {4, 2}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithNodeID(%d, %d) did not panic", tt.id, tt.bits)
+				}
+			}()
+			WithNodeID(tt.id, tt.bits)
+		}()
+	}
+}
+
+func TestGenerator_OverflowWait(t *testing.T) {
+	// The clock follows real time from 1000 ms, so waiting makes it advance.
+	start := time.Now()
+	clock := func() int64 { return 1000 + time.Since(start).Milliseconds() }
+	zero := func(int) (uint32, error) { return 0, nil }
+
+	// Four node bits leave 2^16 IDs per ms, so the sequence wraps quickly.
+	g := NewGenerator(WithClock(clock), WithRNG(zero), WithNodeID(1, 4), WithOverflowPolicy(OverflowWait))
+	if g.manifest().Policies.Overflow != OverflowWait {
+		t.Errorf("Manifest().Policies.Overflow = %q, want %q", g.manifest().Policies.Overflow, OverflowWait)
+	}
+
+	var prev Nano64
+	for i := 0; i < 3<<16; i++ {
+		id, err := g.GenerateMonotonicNow()
+		if err != nil {
+			t.Fatalf("GenerateMonotonicNow() error = %v", err)
+		}
+		if i > 0 && Compare(id, prev) <= 0 {
+			t.Fatalf("IDs not increasing: %s then %s", prev.ToHex(), id.ToHex())
+		}
+		if now := clock(); id.GetTimestamp() > now {
+			t.Fatalf("ID timestamp %d is ahead of the clock %d", id.GetTimestamp(), now)
+		}
+		prev = id
+	}
+
+	// OverflowAdvance borrows the next millisecond of a stopped clock instead.
+	g = NewGenerator(WithClock(func() int64 { return 1000 }), WithRNG(zero), WithNodeID(1, 4))
+	for i := 0; i <= 1<<16; i++ {
+		if prev, _ = g.GenerateMonotonicNow(); prev.GetTimestamp() > 1000 {
+			break
+		}
+	}
+	if prev.GetTimestamp() != 1001 {
+		t.Errorf("OverflowAdvance timestamp = %d, want 1001", prev.GetTimestamp())
+	}
+
+	m := CurrentManifest()
+	m.Policies.Overflow = OverflowWait
+	if g, err := GeneratorFromManifest(manifestJSON(t, m)); err != nil || g.overflow != OverflowWait {
+		t.Errorf("GeneratorFromManifest() error = %v, want a generator with overflow %q", err, OverflowWait)
+	}
+	m.Policies.Overflow = "explode"
+	if _, err := GeneratorFromManifest(manifestJSON(t, m)); err == nil {
+		t.Error("GeneratorFromManifest() with unknown overflow policy error = nil, want error")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("WithOverflowPolicy(\"explode\") did not panic")
+		}
+	}()
+	WithOverflowPolicy("explode")
+}
+
+func TestGenerator_StrictMonotonic(t *testing.T) {
+	g := NewGenerator(WithStrictMonotonic(5 * time.Millisecond))
+	if g.manifest().Policies.ClockRegression != ClockRegressionError {
+		t.Errorf("Manifest().Policies.ClockRegression = %q, want %q", g.manifest().Policies.ClockRegression, ClockRegressionError)
+	}
+
+	first, err := g.GenerateMonotonic(10_000)
+	if err != nil {
+		t.Fatalf("GenerateMonotonic() error = %v", err)
+	}
+	// Within tolerance the timestamp is clamped as usual.
+	id, err := g.GenerateMonotonic(9_995)
+	if err != nil {
+		t.Fatalf("GenerateMonotonic() within tolerance error = %v", err)
+	}
+	if id.GetTimestamp() != 10_000 || Compare(id, first) <= 0 {
+		t.Errorf("GenerateMonotonic() within tolerance = %s, want after %s at 10000", id.ToHex(), first.ToHex())
+	}
+	// Beyond it the regression is reported and the state is untouched.
+	if _, err := g.GenerateMonotonic(9_994); !errors.Is(err, ErrClockRegression) {
+		t.Errorf("GenerateMonotonic() beyond tolerance error = %v, want ErrClockRegression", err)
+	}
+	next, err := g.GenerateMonotonic(10_000)
+	if err != nil || next.Uint64Value() != id.Uint64Value()+1 {
+		t.Errorf("GenerateMonotonic() after regression = %s, %v; want %s + 1", next.ToHex(), err, id.ToHex())
+	}
+
+	// The default generator clamps silently.
+	lenient := NewGenerator()
+	if _, err := lenient.GenerateMonotonic(10_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lenient.GenerateMonotonic(1_000); err != nil {
+		t.Errorf("default GenerateMonotonic() with regression error = %v", err)
+	}
+
+	m := CurrentManifest()
+	m.Policies.ClockRegression = ClockRegressionError
+	g, err = GeneratorFromManifest(manifestJSON(t, m))
+	if err != nil {
+		t.Fatalf("GeneratorFromManifest() error = %v", err)
+	}
+	if _, err := g.GenerateMonotonic(10_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GenerateMonotonic(9_999); !errors.Is(err, ErrClockRegression) {
+		t.Errorf("manifest strict GenerateMonotonic() error = %v, want ErrClockRegression", err)
+	}
+	m.Policies.ClockRegression = "ignore"
+	if _, err := GeneratorFromManifest(manifestJSON(t, m)); err == nil {
+		t.Error("GeneratorFromManifest() with unknown clock regression policy error = nil, want error")
+	}
+}
+
+func TestGeneratorEpoch(t *testing.T) {
+	const epoch = int64(1700000000000)
+	g := NewGenerator(WithEpoch(epoch), WithClock(func() int64 { return epoch + 5000 }))
+
+	ts := epoch + 1234
+	id, err := g.Generate(ts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if id.GetTimestamp() != 1234 {
+		t.Errorf("GetTimestamp() = %d, want raw offset 1234", id.GetTimestamp())
+	}
+	if g.Timestamp(id) != ts {
+		t.Errorf("Timestamp() = %d, want %d", g.Timestamp(id), ts)
+	}
+	if !g.ToDate(id).Equal(time.UnixMilli(ts)) {
+		t.Errorf("ToDate() = %v, want %v", g.ToDate(id), time.UnixMilli(ts))
+	}
+
+	mono, err := g.GenerateMonotonicNow()
+	if err != nil {
+		t.Fatalf("GenerateMonotonicNow() error = %v", err)
+	}
+	if g.Timestamp(mono) != epoch+5000 {
+		t.Errorf("Timestamp(monotonic) = %d, want %d", g.Timestamp(mono), epoch+5000)
+	}
+
+	if _, err := g.Generate(epoch - 1); err == nil {
+		t.Error("Generate() before the epoch error = nil, want error")
+	}
+	if _, err := g.GenerateMonotonic(epoch - 1); err == nil {
+		t.Error("GenerateMonotonic() before the epoch error = nil, want error")
+	}
+
+	// The custom epoch extends the usable range past the Unix-epoch limit.
+	beyond := int64(maxTimestamp) + 1
+	if _, err := g.Generate(beyond); err != nil {
+		t.Errorf("Generate(%d) error = %v, want nil with custom epoch", beyond, err)
+	}
+	if _, err := Generate(beyond, nil); err == nil {
+		t.Errorf("Generate(%d) without epoch error = nil, want error", beyond)
+	}
+}
+
+func TestSetDefaultEpoch(t *testing.T) {
+	const epoch = int64(1700000000000)
+	restore := SetDefaultEpoch(epoch)
+	defer restore()
+	monotonic.reset()
+	t.Cleanup(monotonic.reset)
+
+	if DefaultEpoch() != epoch {
+		t.Fatalf("DefaultEpoch() = %d, want %d", DefaultEpoch(), epoch)
+	}
+	ts := epoch + 1234
+	id, err := Generate(ts, nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if id.rawTimestamp() != 1234 {
+		t.Errorf("raw timestamp = %d, want offset 1234", id.rawTimestamp())
+	}
+	if id.GetTimestamp() != ts || !id.ToDate().Equal(time.UnixMilli(ts)) {
+		t.Errorf("GetTimestamp() = %d, ToDate() = %v; want %d", id.GetTimestamp(), id.ToDate(), ts)
+	}
+	mono, err := GenerateMonotonic(ts, nil)
+	if err != nil || mono.GetTimestamp() != ts {
+		t.Errorf("GenerateMonotonic() = %d, %v; want timestamp %d", mono.GetTimestamp(), err, ts)
+	}
+	if _, err := Generate(epoch-1, nil); err == nil {
+		t.Error("Generate() before the default epoch error = nil, want error")
+	}
+	if _, err := GenerateMonotonic(epoch-1, nil); err == nil {
+		t.Error("GenerateMonotonic() before the default epoch error = nil, want error")
+	}
+
+	// Generators inherit the default epoch unless WithEpoch overrides it.
+	g := NewGenerator()
+	if g.Epoch() != epoch {
+		t.Errorf("NewGenerator().Epoch() = %d, want %d", g.Epoch(), epoch)
+	}
+	if gid, _ := g.Generate(ts); gid.GetTimestamp() != ts || g.Timestamp(gid) != ts {
+		t.Errorf("generator ID timestamp = %d / %d, want %d", gid.GetTimestamp(), g.Timestamp(gid), ts)
+	}
+	if NewGenerator(WithEpoch(0)).Epoch() != 0 {
+		t.Error("WithEpoch(0) did not override the default epoch")
+	}
+
+	if lo, hi := DayBounds(id.ToDate()); Compare(lo, id) > 0 || Compare(id, hi) > 0 {
+		t.Errorf("DayBounds() = [%s, %s], want to contain %s", lo.ToHex(), hi.ToHex(), id.ToHex())
+	}
+	plan, err := PartitionPlan(time.UnixMilli(epoch), time.UnixMilli(epoch+4000), 4)
+	if err != nil || plan.Locate(id) != 1 {
+		t.Errorf("PartitionPlan().Locate() = %d, %v; want 1", plan.Locate(id), err)
+	}
+	if CurrentManifest().Layout.Epoch != epoch {
+		t.Errorf("CurrentManifest() epoch = %d, want %d", CurrentManifest().Layout.Epoch, epoch)
+	}
+	if got := ExhaustionTime().UnixMilli(); got != epoch+maxTimestamp {
+		t.Errorf("ExhaustionTime() = %d, want %d", got, epoch+maxTimestamp)
+	}
+
+	restoreNeg := SetDefaultEpoch(-5)
+	if DefaultEpoch() != 0 {
+		t.Errorf("SetDefaultEpoch(-5) gave %d, want 0", DefaultEpoch())
+	}
+	restoreNeg()
+}
+
+func TestConstantTimeEquals(t *testing.T) {
+	a := New(0x199C01B66595861C)
+	tests := []struct {
+		b    Nano64
+		want bool
+	}{
+		{a, true},
+		{New(0x199C01B66595861D), false},
+		{New(0x099C01B66595861C), false},
+		{New(0x199C01B76595861C), false},
+		{Nil, false},
+	}
+	for _, tt := range tests {
+		if got := ConstantTimeEquals(a, tt.b); got != tt.want {
+			t.Errorf("ConstantTimeEquals(%s, %s) = %v, want %v", a.ToHex(), tt.b.ToHex(), got, tt.want)
+		}
+	}
+	if !ConstantTimeEquals(Nil, Nil) {
+		t.Error("ConstantTimeEquals(Nil, Nil) = false")
+	}
+	if EntropyBits() != 20 {
+		t.Errorf("EntropyBits() = %d, want 20", EntropyBits())
+	}
+}
+
+func TestWithDecryptCache(t *testing.T) {
+	config, err := NewEncryptedIDConfig(make([]byte, 32), nil, nil, WithDecryptCache(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hits, misses atomic.Int32
+	restore := SetHooks(&Hooks{OnDecrypt: func(tr DecryptTrace) {
+		if tr.CacheHit {
+			hits.Add(1)
+		} else {
+			misses.Add(1)
+		}
+	}})
+	defer restore()
+
+	var payloads [][]byte
+	var ids []Nano64
+	for i := 0; i < 3; i++ {
+		enc, err := config.GenerateEncryptedNow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads = append(payloads, enc.ToEncryptedBytes())
+		ids = append(ids, enc.ID)
+	}
+
+	decrypt := func(i int) {
+		t.Helper()
+		dec, err := config.FromEncryptedBytes(payloads[i])
+		if err != nil || dec.ID != ids[i] || !bytes.Equal(dec.ToEncryptedBytes(), payloads[i]) {
+			t.Fatalf("FromEncryptedBytes(%d) = %v, %v", i, dec, err)
+		}
+	}
+	decrypt(0) // miss
+	decrypt(0) // hit
+	decrypt(1) // miss
+	decrypt(2) // miss, evicts 0
+	decrypt(1) // hit
+	decrypt(0) // miss
+	if h, m := config.DecryptCacheStats(); h != 2 || m != 4 {
+		t.Errorf("DecryptCacheStats() = %d hits, %d misses; want 2, 4", h, m)
+	}
+	if hits.Load() != 2 || misses.Load() != 4 {
+		t.Errorf("OnDecrypt saw %d hits, %d misses; want 2, 4", hits.Load(), misses.Load())
+	}
+
+	// Tampered payloads are never served from the cache.
+	tampered := bytes.Clone(payloads[0])
+	tampered[PayloadLength-1] ^= 1
+	if _, err := config.FromEncryptedBytes(tampered); err == nil {
+		t.Error("FromEncryptedBytes(tampered) error = nil, want error")
+	}
+
+	// Mutating the caller's buffer after decryption does not affect the cache.
+	buf := bytes.Clone(payloads[1])
+	if _, err := config.FromEncryptedBytes(buf); err != nil {
+		t.Fatal(err)
+	}
+	buf[0] ^= 1
+	if _, err := config.FromEncryptedBytes(buf); err == nil {
+		t.Error("modified payload decrypted from cache")
+	}
+
+	// Verify neither touches the cache nor reports through hooks.
+	h, m := config.DecryptCacheStats()
+	seen := hits.Load() + misses.Load()
+	if err := config.Verify(payloads[2]); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := config.Verify(tampered); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Verify(tampered) error = %v, want ErrAuthFailed", err)
+	}
+	if h2, m2 := config.DecryptCacheStats(); h2 != h || m2 != m || hits.Load()+misses.Load() != seen {
+		t.Error("Verify() used the decrypt cache or fired OnDecrypt")
+	}
+	if config.cache.items[[PayloadLength]byte(payloads[2])] != nil {
+		t.Error("Verify() cached the verified ID")
+	}
+
+	// An expired request is still served from the cache, but not decrypted.
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if dec, err := config.FromEncryptedBytesContext(expired, payloads[0]); err != nil || dec.ID != ids[0] {
+		t.Errorf("FromEncryptedBytesContext(expired, cached) = %v, %v; want %s", dec, err, ids[0].ToHex())
+	}
+	if _, err := config.FromEncryptedHexContext(expired, Hex.FromBytes(payloads[2])); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FromEncryptedHexContext(expired, uncached) error = %v, want context.DeadlineExceeded", err)
+	}
+
+	plain, _ := NewEncryptedIDConfig(make([]byte, 32), nil, nil)
+	if h, m := plain.DecryptCacheStats(); h != 0 || m != 0 {
+		t.Errorf("DecryptCacheStats() without cache = %d, %d", h, m)
+	}
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: error" }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestRetryOnConflict(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "conflict.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE items (id BLOB PRIMARY KEY)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	// The RNG repeats 7 twice before moving on, so the first two attempts collide.
+	draws := []uint32{7, 7, 7, 8}
+	var mu sync.Mutex
+	rng := func(int) (uint32, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		v := draws[0]
+		if len(draws) > 1 {
+			draws = draws[1:]
+		}
+		return v, nil
+	}
+	gen := NewGenerator(WithClock(func() int64 { return 1_000 }), WithRNG(rng))
+	insert := func(id Nano64) error {
+		_, err := db.Exec(`INSERT INTO items (id) VALUES (?)`, id)
+		return err
+	}
+
+	first, err := RetryOnConflict(insert, gen, 1)
+	if err != nil || first.GetRandom() != 7 {
+		t.Fatalf("RetryOnConflict() = %s, %v", first.ToHex(), err)
+	}
+	id, err := RetryOnConflict(insert, gen, 3)
+	if err != nil {
+		t.Fatalf("RetryOnConflict() error = %v", err)
+	}
+	if id.GetRandom() != 8 {
+		t.Errorf("RetryOnConflict() = %s, want the third generated ID", id.ToHex())
+	}
+
+	calls := 0
+	_, err = RetryOnConflict(func(Nano64) error { calls++; return insert(first) }, gen, 2)
+	if err == nil || !IsUniqueViolation(err) || calls != 2 {
+		t.Errorf("RetryOnConflict() exhausted = %v after %d calls, want a conflict after 2", err, calls)
+	}
+
+	boom := errors.New("boom")
+	calls = 0
+	if _, err := RetryOnConflict(func(Nano64) error { calls++; return boom }, nil, 5); !errors.Is(err, boom) || calls != 1 {
+		t.Errorf("RetryOnConflict() = %v after %d calls, want boom after 1", err, calls)
+	}
+	if _, err := RetryOnConflict(insert, gen, 0); err == nil {
+		t.Error("RetryOnConflict() with zero attempts error = nil, want error")
+	}
+
+	// A custom classifier replaces the default.
+	restore := SetConflictClassifier(func(err error) bool { return errors.Is(err, boom) })
+	calls = 0
+	_, err = RetryOnConflict(func(Nano64) error { calls++; return boom }, nil, 3)
+	restore()
+	if calls != 3 || !errors.Is(err, boom) {
+		t.Errorf("custom classifier: %d calls, err = %v, want 3 calls", calls, err)
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("connection refused"), false},
+		{sqlStateError("23505"), true},
+		{sqlStateError("23503"), false},
+		{fmt.Errorf("insert: %w", sqlStateError("23505")), true},
+		{errors.New(`ERROR: duplicate key value violates unique constraint "items_pkey"`), true},
+		{errors.New("Error 1062: Duplicate entry '1' for key 'PRIMARY'"), true},
+	}
+	for _, tt := range tests {
+		if got := IsUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("IsUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+type failingWriter struct{ after int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.after <= 0 {
+		return 0, errors.New("write failed")
+	}
+	w.after--
+	return len(p), nil
+}
+
+func TestMarshalIDList(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 215, 216, 5000} {
+		ids := make([]Nano64, n)
+		for i := range ids {
+			ids[i] = New(uint64(i)*0x9E3779B97F4A7C15 + 1)
+		}
+		want, err := json.Marshal(ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			want = []byte("[]")
+		}
+
+		got, err := MarshalIDList(ids)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("MarshalIDList(%d IDs) = %.60s, %v; want %.60s", n, got, err, want)
+		}
+		if len(got) != cap(got) {
+			t.Errorf("MarshalIDList(%d IDs) len %d != cap %d", n, len(got), cap(got))
+		}
+
+		var buf bytes.Buffer
+		if err := EncodeIDList(&buf, ids); err != nil || !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("EncodeIDList(%d IDs) = %.60s, %v; want %.60s", n, buf.Bytes(), err, want)
+		}
+	}
+
+	if got, _ := MarshalIDList(nil); string(got) != "[]" {
+		t.Errorf("MarshalIDList(nil) = %s, want []", got)
+	}
+
+	ids := make([]Nano64, 1000)
+	for _, after := range []int{0, 1} {
+		if err := EncodeIDList(&failingWriter{after: after}, ids); err == nil {
+			t.Errorf("EncodeIDList() with a writer failing after %d writes error = nil", after)
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { _, _ = MarshalIDList(ids) }); allocs != 1 {
+		t.Errorf("MarshalIDList() allocs = %v, want 1", allocs)
+	}
+}
+
+func BenchmarkMarshalIDList(b *testing.B) {
+	ids := make([]Nano64, 10_000)
+	for i := range ids {
+		ids[i] = New(uint64(i) * 0x9E3779B97F4A7C15)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalIDList(ids); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept []string
+		want   Format
+	}{
+		{nil, HexFormat},
+		{[]string{"int"}, IntFormat},
+		{[]string{"BASE32"}, Base32Format},
+		{[]string{"uuid", "base32"}, Base32Format},
+		{[]string{"int;q=0.5", "base32;q=0.9"}, Base32Format},
+		{[]string{"base32, int"}, Base32Format},
+		{[]string{"int;q=0.2, hex;q=0.8"}, HexFormat},
+		{[]string{"base32;q=0", "int;q=0.1"}, IntFormat},
+		{[]string{"base32;q=0"}, HexFormat},
+		{[]string{"int;q=bogus"}, HexFormat},
+		{[]string{"uuid;q=1"}, HexFormat},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.accept); got != tt.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tt.accept, got, tt.want)
+		}
+	}
+	if s := Format(9).String(); s != "Format(9)" {
+		t.Errorf("Format(9).String() = %q", s)
+	}
+}
+
+func TestFormattedID_JSON(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{HexFormat, `"199C01B6659-5861C"`},
+		{Base32Format, `"` + id.ToBase32() + `"`},
+		{IntFormat, `1845351830215034396`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(FormattedID{ID: id, Format: tt.format})
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Marshal(%s) = %s, %v; want %s", tt.format, data, err, tt.want)
+		}
+		got := FormattedID{Format: tt.format}
+		if err := json.Unmarshal(data, &got); err != nil || got.ID != id || got.Format != tt.format {
+			t.Errorf("Unmarshal(%s) = %+v, %v", data, got, err)
+		}
+	}
+	if _, err := json.Marshal(FormattedID{ID: id, Format: Format(9)}); err == nil {
+		t.Error("Marshal() with unknown format error = nil, want error")
+	}
+
+	// Any format decodes the hex and integer forms.
+	got := FormattedID{Format: Base32Format}
+	if err := json.Unmarshal([]byte(`"199C01B6659-5861C"`), &got); err != nil || got.ID != id {
+		t.Errorf("Unmarshal(hex into base32) = %s, %v", got.ID.ToHex(), err)
+	}
+	if err := json.Unmarshal([]byte(`"0000000000!00"`), &got); err == nil {
+		t.Error("Unmarshal(invalid base32) error = nil, want error")
+	}
+}
+
+func TestGenerator_DriftPolicy(t *testing.T) {
+	var seen []Drift
+	record := func(action DriftAction) DriftPolicy {
+		return func(d Drift) DriftAction {
+			seen = append(seen, d)
+			return action
+		}
+	}
+
+	g := NewGenerator(WithDriftPolicy(10*time.Millisecond, time.Second, record(DriftIgnore)))
+	for _, ts := range []int64{10_000, 9_995, 10_500, 9_000, 20_000} {
+		if _, err := g.Generate(ts); err != nil {
+			t.Fatalf("Generate(%d) error = %v", ts, err)
+		}
+	}
+	want := []Drift{
+		{Last: 10_500, Now: 9_000, Jump: -1500 * time.Millisecond},
+		{Last: 9_000, Now: 20_000, Jump: 11 * time.Second},
+	}
+	if !slices.Equal(seen, want) {
+		t.Errorf("DriftPolicy saw %+v, want %+v", seen, want)
+	}
+
+	g = NewGenerator(WithDriftPolicy(10*time.Millisecond, time.Second, ClampDrift))
+	for _, step := range []struct{ ts, want int64 }{{10_000, 10_000}, {5_000, 9_990}, {50_000, 10_990}} {
+		id, err := g.Generate(step.ts)
+		if err != nil || id.GetTimestamp() != step.want {
+			t.Errorf("clamped Generate(%d) = %d, %v; want %d", step.ts, id.GetTimestamp(), err, step.want)
+		}
+	}
+
+	g = NewGenerator(WithDriftPolicy(10*time.Millisecond, 0, ErrorDrift))
+	if _, err := g.GenerateMonotonic(10_000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GenerateMonotonic(1_000_000); err != nil {
+		t.Errorf("GenerateMonotonic() with forward checking disabled error = %v", err)
+	}
+	if _, err := g.GenerateMonotonic(10_000); !errors.Is(err, ErrClockDrift) {
+		t.Errorf("GenerateMonotonic() after a backwards jump error = %v, want ErrClockDrift", err)
+	}
+
+	// Only the call that observes a jump fails; the generator then recovers.
+	g = NewGenerator(WithDriftPolicy(0, time.Second, ErrorDrift))
+	for _, step := range []struct {
+		ts      int64
+		wantErr bool
+	}{{10_000, false}, {60_000, true}, {60_001, false}, {60_002, false}} {
+		if _, err := g.GenerateMonotonic(step.ts); errors.Is(err, ErrClockDrift) != step.wantErr {
+			t.Errorf("GenerateMonotonic(%d) error = %v, want drift error %v", step.ts, err, step.wantErr)
+		}
+	}
+
+	// The clock jumps back 20 ms, then runs in real time until it recovers.
+	start := time.Now()
+	var jumped atomic.Bool
+	clock := func() int64 {
+		ms := 100_000 + time.Since(start).Milliseconds()
+		if jumped.Load() {
+			ms -= 20
+		}
+		return ms
+	}
+	g = NewGenerator(WithClock(clock), WithDriftPolicy(5*time.Millisecond, 0, WaitDrift))
+	before, err := g.GenerateNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jumped.Store(true)
+	after, err := g.GenerateNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.GetTimestamp() < before.GetTimestamp() {
+		t.Errorf("WaitDrift generated %d after %d", after.GetTimestamp(), before.GetTimestamp())
+	}
+}
+
+// checkDigitEncoding is a legacy-style encoding: "L", the decimal value and a
+// digit-sum check digit.
+type checkDigitEncoding struct{}
+
+func (checkDigitEncoding) Name() string { return "test-checkdigit" }
+
+func (checkDigitEncoding) Encode(id Nano64) string {
+	digits := strconv.FormatUint(id.Uint64Value(), 10)
+	return "L" + digits + strconv.Itoa(digitSum(digits)%10)
+}
+
+func (checkDigitEncoding) Decode(s string) (Nano64, error) {
+	if len(s) < 3 || s[0] != 'L' {
+		return Nil, errors.New("missing L prefix")
+	}
+	digits, check := s[1:len(s)-1], s[len(s)-1:]
+	if strconv.Itoa(digitSum(digits)%10) != check {
+		return Nil, errors.New("bad check digit")
+	}
+	v, err := strconv.ParseUint(digits, 10, 64)
+	return New(v), err
+}
+
+func digitSum(s string) int {
+	sum := 0
+	for _, c := range s {
+		sum += int(c - '0')
+	}
+	return sum
+}
+
+func TestEncodingRegistry(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	for _, name := range []string{"hex", "base32", "base58", "base62", "base64url"} {
+		e, ok := LookupEncoding(name)
+		if !ok {
+			t.Fatalf("LookupEncoding(%q) not found", name)
+		}
+		if got, err := e.Decode(e.Encode(id)); err != nil || got != id {
+			t.Errorf("%s round trip = %s, %v", name, got.ToHex(), err)
+		}
+	}
+
+	enc := checkDigitEncoding{}
+	text := enc.Encode(id)
+	if _, err := Parse(text); err == nil {
+		t.Fatalf("Parse(%q) before registration error = nil, want error", text)
+	}
+
+	if err := RegisterEncoding(enc); err != nil {
+		t.Fatalf("RegisterEncoding() error = %v", err)
+	}
+	t.Cleanup(func() {
+		encodingsMu.Lock()
+		defer encodingsMu.Unlock()
+		delete(encodings, enc.Name())
+		custom = slices.DeleteFunc(custom, func(e Encoding) bool { return e.Name() == enc.Name() })
+	})
+
+	if err := RegisterEncoding(enc); err == nil {
+		t.Error("RegisterEncoding() duplicate error = nil, want error")
+	}
+	if err := RegisterEncoding(builtinEncoding{name: "base32"}); err == nil {
+		t.Error("RegisterEncoding() shadowing a built-in error = nil, want error")
+	}
+	if err := RegisterEncoding(builtinEncoding{}); err == nil {
+		t.Error("RegisterEncoding() with empty name error = nil, want error")
+	}
+	if !slices.Contains(EncodingNames(), enc.Name()) {
+		t.Errorf("EncodingNames() = %v, missing %s", EncodingNames(), enc.Name())
+	}
+
+	if got, err := Parse(text); err != nil || got != id {
+		t.Errorf("Parse(%q) = %s, %v; want %s", text, got.ToHex(), err, id.ToHex())
+	}
+	// Hex still wins, and failures still report the hex error.
+	if got, err := Parse(id.ToHex()); err != nil || got != id {
+		t.Errorf("Parse(hex) = %s, %v", got.ToHex(), err)
+	}
+	bad := text[:len(text)-1] + "0"
+	if bad == text {
+		bad = text[:len(text)-1] + "1"
+	}
+	var perr *ParseError
+	if _, err := Parse(bad); !errors.As(err, &perr) {
+		t.Errorf("Parse(%q) error = %v, want *ParseError", bad, err)
+	}
+	if _, _, err := ParseDetailed(text); err == nil {
+		t.Error("ParseDetailed() accepted a registered encoding, want hex only")
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	var clock atomic.Int64
+	clock.Store(1_700_000_000_000)
+	gen := NewGenerator(WithClock(clock.Load))
+	iss := NewIssuer(gen, Quota{Limit: 3, Window: time.Second})
+	iss.SetQuota("big", Quota{Limit: 5})
+
+	var traces []IssueTrace
+	restore := SetHooks(&Hooks{OnIssue: func(tr IssueTrace) { traces = append(traces, tr) }})
+	defer restore()
+
+	var last Nano64
+	for i := 0; i < 3; i++ {
+		id, err := iss.Issue("small")
+		if err != nil {
+			t.Fatalf("Issue(small) #%d error = %v", i, err)
+		}
+		if i > 0 && Compare(id, last) <= 0 {
+			t.Errorf("Issue() IDs not increasing: %s then %s", last, id)
+		}
+		last = id
+	}
+	if _, err := iss.Issue("small"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("4th Issue(small) error = %v, want ErrQuotaExceeded", err)
+	}
+	if got := traces[len(traces)-1]; got.Tenant != "small" || !errors.Is(got.Err, ErrQuotaExceeded) || got.Usage.Denied != 1 {
+		t.Errorf("last trace = %+v", got)
+	}
+
+	// The next window resets Issued but keeps the totals.
+	clock.Add(1000)
+	if _, err := iss.Issue("small"); err != nil {
+		t.Fatalf("Issue(small) in next window error = %v", err)
+	}
+	if u := iss.Usage("small"); u.Issued != 1 || u.Total != 4 || u.Denied != 1 {
+		t.Errorf("Usage(small) = %+v, want issued 1, total 4, denied 1", u)
+	}
+
+	// A lifetime quota never resets.
+	for i := 0; i < 5; i++ {
+		if _, err := iss.Issue("big"); err != nil {
+			t.Fatalf("Issue(big) #%d error = %v", i, err)
+		}
+	}
+	clock.Add(time.Hour.Milliseconds())
+	if _, err := iss.Issue("big"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Issue(big) after lifetime quota error = %v, want ErrQuotaExceeded", err)
+	}
+	iss.ClearQuota("big")
+	if _, err := iss.Issue("big"); err != nil {
+		t.Errorf("Issue(big) after ClearQuota error = %v", err)
+	}
+
+	if got := iss.Tenants(); len(got) != 2 || got["big"].Total != 6 || got["small"].Quota.Limit != 3 {
+		t.Errorf("Tenants() = %+v", got)
+	}
+	if u := iss.Usage("unknown"); u.Issued != 0 || u.Quota.Limit != 3 {
+		t.Errorf("Usage(unknown) = %+v", u)
+	}
+	if len(traces) != 12 {
+		t.Errorf("OnIssue called %d times, want 12", len(traces))
+	}
+}
+
+func TestIssuer_ConcurrentQuota(t *testing.T) {
+	iss := NewIssuer(NewGenerator(WithFastRNG()), Quota{Limit: 500})
+	var issued, denied atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, err := iss.Issue("tenant"); err == nil {
+					issued.Add(1)
+				} else if errors.Is(err, ErrQuotaExceeded) {
+					denied.Add(1)
+				} else {
+					t.Errorf("Issue() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if issued.Load() != 500 || denied.Load() != 300 {
+		t.Errorf("issued %d, denied %d; want 500 and 300", issued.Load(), denied.Load())
+	}
+}
+
+func TestValidateAfterRestore(t *testing.T) {
+	last := New(uint64(1_735_689_600_000)<<timestampShift | 1)
+	if err := ValidateAfterRestore(last, last.ToDate()); err != nil {
+		t.Errorf("clock at the last ID: error = %v", err)
+	}
+	if err := ValidateAfterRestore(last, last.ToDate().Add(time.Hour)); err != nil {
+		t.Errorf("clock ahead: error = %v", err)
+	}
+	err := ValidateAfterRestore(last, last.ToDate().Add(-90*time.Second))
+	if !errors.Is(err, ErrClockRegression) || !strings.Contains(err.Error(), "1m30s behind") {
+		t.Errorf("clock behind: error = %v, want ErrClockRegression mentioning the lag", err)
+	}
+	if err := ValidateAfterRestore(Nil, time.UnixMilli(0)); err != nil {
+		t.Errorf("nil ID: error = %v", err)
+	}
+}
+
+func TestGenerator_WithFloor(t *testing.T) {
+	const clockNow = 1_700_000_000_000
+	floor := New(uint64(clockNow+5000)<<timestampShift | 0x00100)
+	clock := func() int64 { return clockNow }
+
+	gen := NewGenerator(WithClock(clock), WithFloor(floor))
+	prev := floor
+	for i := 0; i < 3; i++ {
+		id, err := gen.GenerateMonotonicNow()
+		if err != nil {
+			t.Fatalf("GenerateMonotonicNow() error = %v", err)
+		}
+		if Compare(id, prev) <= 0 {
+			t.Fatalf("monotonic ID %s not above %s", id.ToHex(), prev.ToHex())
+		}
+		prev = id
+	}
+	if prev.GetTimestamp() != clockNow+5000 {
+		t.Errorf("monotonic IDs should continue in the floor's ms, got timestamp %d", prev.GetTimestamp())
+	}
+
+	if _, err := gen.GenerateNow(); !errors.Is(err, ErrClockRegression) {
+		t.Errorf("GenerateNow() below floor error = %v, want ErrClockRegression", err)
+	}
+	if _, err := gen.Generate(clockNow + 6000); err != nil {
+		t.Errorf("Generate() above floor error = %v", err)
+	}
+
+	strict := NewGenerator(WithClock(clock), WithFloor(floor), WithStrictMonotonic(time.Second))
+	if _, err := strict.GenerateMonotonicNow(); !errors.Is(err, ErrClockRegression) {
+		t.Errorf("strict GenerateMonotonicNow() below floor error = %v, want ErrClockRegression", err)
+	}
+
+	// With a node ID, a floor issued by another node may carry higher node
+	// bits, so generation moves to the next millisecond.
+	node := NewGenerator(WithClock(clock), WithNodeID(1, 4), WithFloor(floor))
+	id, err := node.GenerateMonotonicNow()
+	if err != nil || Compare(id, floor) <= 0 || id.GetTimestamp() != clockNow+5001 {
+		t.Errorf("node GenerateMonotonicNow() = %s, %v; want an ID in the ms after the floor", id.ToHex(), err)
+	}
+
+	// A floor issued by the same node continues its sequence.
+	same := NewGenerator(WithClock(clock), WithNodeID(0, 4), WithFloor(floor))
+	id, err = same.GenerateMonotonicNow()
+	if err != nil || Compare(id, floor) <= 0 || id.GetTimestamp() != clockNow+5000 {
+		t.Errorf("same-node GenerateMonotonicNow() = %s, %v; want an ID in the floor's ms", id.ToHex(), err)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	const unix = 1_735_689_600_000  // 2025-01-01
+	const epoch = 1_704_067_200_000 // 2024-01-01
+	std := DefaultLayout()
+	custom := std
+	custom.Epoch = epoch
+	id := New(uint64(unix)<<timestampShift | 0xABCDE)
+
+	// Epoch change round-trips and keeps the Unix timestamp.
+	moved, err := Rebase(id, std, custom)
+	if err != nil {
+		t.Fatalf("Rebase(std -> custom epoch) error = %v", err)
+	}
+	if moved.GetTimestamp() != unix-epoch || moved.GetRandom() != 0xABCDE {
+		t.Errorf("rebased = %s, want offset %d and random unchanged", moved.ToHex(), unix-epoch)
+	}
+	if back, err := Rebase(moved, custom, std); err != nil || back != id {
+		t.Errorf("Rebase back = %s, %v; want %s", back.ToHex(), err, id.ToHex())
+	}
+
+	// Node IDs move between node-bit widths separately from the sequence.
+	node4, node8 := std, std
+	node4.NodeBits, node8.NodeBits = 4, 8
+	withNode := New(uint64(unix)<<timestampShift | 0x3<<16 | 0x0042)
+	widened, err := Rebase(withNode, node4, node8)
+	if err != nil || widened.GetNodeID(8) != 3 || widened.GetRandom()&0xFFF != 0x42 {
+		t.Errorf("Rebase(node4 -> node8) = %s, %v; want node 3, sequence 0x42", widened.ToHex(), err)
+	}
+	if _, err := Rebase(New(uint64(unix)<<timestampShift|0xFFFFF), node4, node8); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("sequence too wide: error = %v, want ErrPrecisionLoss", err)
+	}
+
+	// Narrower random fields and out-of-range timestamps are refused.
+	wideTs := Layout{TimestampBits: 48, RandomBits: 16}
+	if _, err := Rebase(id, std, wideTs); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("random too wide: error = %v, want ErrPrecisionLoss", err)
+	}
+	small := New(uint64(unix)<<timestampShift | 0x0FFFF)
+	if got, err := Rebase(small, std, wideTs); err != nil || got.Uint64Value() != uint64(unix)<<16|0xFFFF {
+		t.Errorf("Rebase(std -> 48/16) = %x, %v", got.Uint64Value(), err)
+	}
+	future := custom
+	future.Epoch = unix + 1
+	if _, err := Rebase(id, std, future); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("before target epoch: error = %v, want ErrPrecisionLoss", err)
+	}
+	if _, err := Rebase(New(^uint64(0)), custom, std); !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("timestamp overflow: error = %v, want ErrPrecisionLoss", err)
+	}
+
+	if _, err := Rebase(id, Layout{TimestampBits: 40, RandomBits: 20}, std); err == nil {
+		t.Error("Rebase() with a layout not summing to 64 bits should fail")
+	}
+	if got, err := Rebase(Nil, std, custom); err != nil || got != Nil {
+		t.Errorf("Rebase(Nil) = %v, %v", got, err)
+	}
+}
+
+func TestShuffleStable(t *testing.T) {
+	ids := make([]Nano64, 50)
+	for i := range ids {
+		ids[i] = New(uint64(1_735_689_600_000+i/10)<<timestampShift | uint64(i))
+	}
+
+	a := slices.Clone(ids)
+	ShuffleStable(a, 42)
+	if IDsSorted(a) {
+		t.Error("ShuffleStable() left the IDs in time order")
+	}
+
+	// Independent of the input order.
+	b := slices.Clone(ids)
+	slices.Reverse(b)
+	ShuffleStable(b, 42)
+	if !slices.Equal(a, b) {
+		t.Error("ShuffleStable() depends on the input order")
+	}
+
+	// Removing items keeps the others in the same relative order.
+	sub := slices.Clone(ids[10:30])
+	ShuffleStable(sub, 42)
+	want := slices.DeleteFunc(slices.Clone(a), func(id Nano64) bool { return !slices.Contains(sub, id) })
+	if !slices.Equal(sub, want) {
+		t.Error("ShuffleStable() of a subset reordered the remaining IDs")
+	}
+
+	c := slices.Clone(ids)
+	ShuffleStable(c, 43)
+	if slices.Equal(a, c) {
+		t.Error("different seeds produced the same order")
+	}
+
+	x, y := slices.Clone(ids), slices.Clone(ids)
+	OrderByHash(x, "user-1")
+	OrderByHash(y, "user-1")
+	z := slices.Clone(ids)
+	OrderByHash(z, "user-2")
+	if !slices.Equal(x, y) || slices.Equal(x, z) {
+		t.Error("OrderByHash() should be stable per salt and differ between salts")
+	}
+}
+
+func TestEntityValidation(t *testing.T) {
+	launch := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	order := EntityType{Name: "test-order", Since: launch}
+	if err := RegisterEntity(order); err != nil {
+		t.Fatalf("RegisterEntity() error = %v", err)
+	}
+	t.Cleanup(func() {
+		entitiesMu.Lock()
+		defer entitiesMu.Unlock()
+		delete(entities, order.Name)
+	})
+	if err := RegisterEntity(order); err == nil {
+		t.Error("RegisterEntity() of a duplicate should fail")
+	}
+	if err := RegisterEntity(EntityType{}); err == nil {
+		t.Error("RegisterEntity() without a name should fail")
+	}
+	if !slices.Contains(EntityNames(), "test-order") {
+		t.Errorf("EntityNames() = %v, want test-order", EntityNames())
+	}
+
+	fresh := New(uint64(launch.UnixMilli()+1000)<<timestampShift | 1)
+	old := New(uint64(launch.AddDate(-1, 0, 0).UnixMilli())<<timestampShift | 1)
+
+	if err := ValidateEntity("test-order", fresh); err != nil {
+		t.Errorf("ValidateEntity(fresh) error = %v", err)
+	}
+	if err := ValidateEntity("test-order", New(uint64(launch.UnixMilli())<<timestampShift)); err != nil {
+		t.Errorf("ValidateEntity(at launch) error = %v", err)
+	}
+	err := ValidateEntity("test-order", old)
+	if !errors.Is(err, ErrPredatesEntity) || !strings.Contains(err.Error(), "2023-06-01") {
+		t.Errorf("ValidateEntity(old) error = %v, want ErrPredatesEntity naming the ID's date", err)
+	}
+	if err := ValidateEntity("missing", fresh); err == nil {
+		t.Error("ValidateEntity() of an unknown entity should fail")
+	}
+
+	if id, err := ParseEntity("test-order", fresh.ToHex()); err != nil || id != fresh {
+		t.Errorf("ParseEntity(fresh) = %v, %v", id, err)
+	}
+	if _, err := ParseEntity("test-order", old.ToHex()); !errors.Is(err, ErrPredatesEntity) {
+		t.Errorf("ParseEntity(old) error = %v, want ErrPredatesEntity", err)
+	}
+	var pe *ParseError
+	if _, err := ParseEntity("test-order", "garbage"); !errors.As(err, &pe) {
+		t.Errorf("ParseEntity(garbage) error = %v, want *ParseError", err)
+	}
+
+	if err := (EntityType{Name: "any"}).Validate(old); err != nil {
+		t.Errorf("Validate() without Since error = %v", err)
+	}
+}
+
+func TestGenerator_WithReserved(t *testing.T) {
+	const ts = 1_700_000_000_000
+	reserved := New(uint64(ts)<<timestampShift | 8)
+	gen := NewGenerator(
+		WithRNG(func(bits int) (uint32, error) { return 7, nil }),
+		WithReserved(func(id Nano64) bool { return id == reserved }))
+
+	first, err := gen.GenerateMonotonic(ts)
+	if err != nil || first.GetRandom() != 7 {
+		t.Fatalf("GenerateMonotonic() = %s, %v", first.ToHex(), err)
+	}
+	next, err := gen.GenerateMonotonic(ts)
+	if err != nil || next.GetRandom() != 9 {
+		t.Errorf("GenerateMonotonic() = %s, %v; want the reserved sequence 8 skipped", next.ToHex(), err)
+	}
+
+	all := NewGenerator(WithReserved(func(Nano64) bool { return true }))
+	if _, err := all.GenerateNow(); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("GenerateNow() with everything reserved error = %v", err)
+	}
+}
+
+func TestNewEncoding(t *testing.T) {
+	id := New(0x199C01B66595861C)
+
+	// Matches the built-in Base62 for the same alphabet.
+	b62, err := NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+	if err != nil {
+		t.Fatalf("NewEncoding() error = %v", err)
+	}
+	if got := b62.Encode(id); got != id.ToBase62() {
+		t.Errorf("Encode() = %s, want %s", got, id.ToBase62())
+	}
+
+	// A non-power-of-two legacy alphabet, variable and fixed width.
+	legacy, err := NewEncoding("23456789BCDFGHJKMNPQRSTVWXZ")
+	if err != nil {
+		t.Fatalf("NewEncoding(legacy) error = %v", err)
+	}
+	legacy = legacy.WithName("legacy")
+	fixed := legacy.FixedWidth()
+	r := rand.New(rand.NewSource(27))
+	for i := 0; i < 1000; i++ {
+		v := New(r.Uint64() >> r.Intn(64))
+		for _, e := range []*AlphabetEncoding{legacy, fixed} {
+			got, err := e.Decode(e.Encode(v))
+			if err != nil || got != v {
+				t.Fatalf("%s round trip of %s = %s, %v", e.Name(), v.ToHex(), got.ToHex(), err)
+			}
+		}
+		if s := fixed.Encode(v); len(s) != fixed.Width() {
+			t.Fatalf("FixedWidth Encode() = %q, want %d chars", s, fixed.Width())
+		}
+	}
+	if legacy.Name() != "legacy" || legacy.Alphabet() != "23456789BCDFGHJKMNPQRSTVWXZ" || legacy.Width() != 14 {
+		t.Errorf("Name/Alphabet/Width = %q, %q, %d", legacy.Name(), legacy.Alphabet(), legacy.Width())
+	}
+
+	// Fixed width sorts by time for an ascending alphabet.
+	older, newer := New(26), New(27)
+	if fixed.Encode(older) >= fixed.Encode(newer) {
+		t.Errorf("FixedWidth encodings not sortable: %s >= %s", fixed.Encode(older), fixed.Encode(newer))
+	}
+
+	for _, bad := range []string{"", "22", "2A", "2" + strings.Repeat("Z", 20)} {
+		if _, err := legacy.Decode(bad); err == nil {
+			t.Errorf("legacy.Decode(%q) error = nil, want error", bad)
+		}
+	}
+	if _, err := fixed.Decode("2"); err == nil {
+		t.Error("FixedWidth Decode() of a short input should fail")
+	}
+
+	// Binary works too.
+	bin, _ := NewEncoding("01")
+	if got := bin.Encode(New(5)); got != "101" {
+		t.Errorf("binary Encode(5) = %s", got)
+	}
+
+	for _, alphabet := range []string{"", "0", "0120", "01 ", "01\x80", strings.Repeat("x", 95)} {
+		if _, err := NewEncoding(alphabet); err == nil {
+			t.Errorf("NewEncoding(%q) error = nil, want error", alphabet)
+		}
+	}
+
+	// Registrable once named.
+	var _ Encoding = legacy
+}
+
+func TestNormalizeHex(t *testing.T) {
+	const want = "199C01B6659-5861C"
+	for _, in := range []string{
+		want,
+		"199c01b6659-5861c",
+		"199C01B66595861C",
+		"199c01b66595861c",
+		"199c01B6659-5861C",
+		"0x199c01b66595861c",
+		"199C-01B6-6595-861C",
+	} {
+		got, err := NormalizeHex(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeHex(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "199C01B6659-5861", "199C01B6659--5861C", "199C01B6659-5861G", "199c01b66595861g"} {
+		if _, err := NormalizeHex(in); err == nil {
+			t.Errorf("NormalizeHex(%q) error = nil, want error", in)
+		}
+	}
+	if allocs := testing.AllocsPerRun(100, func() { _, _ = NormalizeHex(want) }); allocs != 0 {
+		t.Errorf("NormalizeHex(canonical) allocs = %v, want 0", allocs)
+	}
+}
+
+func BenchmarkNormalizeHex(b *testing.B) {
+	for _, in := range []string{"199C01B6659-5861C", "199c01b66595861c", "199c01b6659-5861c"} {
+		b.Run(in, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NormalizeHex(in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestHexChecked(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	s := id.ToHexChecked()
+	if len(s) != CheckedHexLength || s[:17] != id.ToHex() {
+		t.Fatalf("ToHexChecked() = %q", s)
+	}
+	for _, in := range []string{s, strings.ToLower(s), strings.ReplaceAll(s, "-", "")} {
+		if got, err := FromHexChecked(in); err != nil || got != id {
+			t.Errorf("FromHexChecked(%q) = %s, %v", in, got.ToHex(), err)
+		}
+	}
+
+	r := rand.New(rand.NewSource(94))
+	for i := 0; i < 1000; i++ {
+		v := New(r.Uint64())
+		s := []byte(v.ToHexChecked())
+		if got, err := FromHexChecked(string(s)); err != nil || got != v {
+			t.Fatalf("round trip of %s = %s, %v", v.ToHex(), got.ToHex(), err)
+		}
+
+		// Every single-character substitution is caught.
+		pos := r.Intn(len(s))
+		if s[pos] == '-' {
+			continue
+		}
+		typo := slices.Clone(s)
+		for typo[pos] == s[pos] {
+			typo[pos] = hexDigitsUpper[r.Intn(16)]
+		}
+		if _, err := FromHexChecked(string(typo)); !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("FromHexChecked(%q) error = %v, want ErrChecksumMismatch", typo, err)
+		}
+	}
+
+	// Swapping adjacent digits is caught unless they are 0 and F (Luhn's blind spot).
+	swapped := []byte(s)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if _, err := FromHexChecked(string(swapped)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("FromHexChecked(%q) error = %v, want ErrChecksumMismatch", swapped, err)
+	}
+
+	var perr *ParseError
+	for _, in := range []string{"", "1", s[:17], s[:17] + "G", s[:16] + "G" + s[17:]} {
+		if _, err := FromHexChecked(in); !errors.As(err, &perr) {
+			t.Errorf("FromHexChecked(%q) error = %v, want *ParseError", in, err)
+		}
+	}
+}
+
+func TestVerifyStorageOrder(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "order.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.Exec(`CREATE TABLE ids (bin BLOB, num INTEGER, txt TEXT COLLATE NOCASE)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	// A low ID, a high-bit ID (negative as int64) and IDs whose hex differs only in case.
+	for _, id := range []Nano64{New(0x199C01B66595861C), New(0x199C01B6659A861C), New(1 << 63), New(0x0000000000000AB1)} {
+		hex := id.ToHex()
+		if id.value == 0x199C01B6659A861C {
+			hex = strings.ToLower(hex)
+		}
+		if _, err := db.Exec(`INSERT INTO ids VALUES (?, ?, ?)`, id, int64(id.value), hex); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO ids VALUES (NULL, NULL, NULL)`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	if err := VerifyStorageOrder(ctx, db, "ids", "bin", 0); err != nil {
+		t.Errorf("VerifyStorageOrder(bin) error = %v", err)
+	}
+	// Signed integers put the high-bit ID first.
+	if err := VerifyStorageOrder(ctx, db, "ids", "num", 10); !errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("VerifyStorageOrder(num) error = %v, want ErrOrderMismatch", err)
+	}
+	if err := VerifyStorageOrder(ctx, db, "main.ids", "txt", 10); err != nil {
+		t.Errorf("VerifyStorageOrder(txt) error = %v", err)
+	}
+
+	// Text under a case-sensitive collation sorts lowercase after uppercase.
+	if _, err := db.Exec(`CREATE TABLE hexids (id TEXT COLLATE BINARY)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for _, s := range []string{"199c01b6659-5861c", "199C01B665A-5861C"} {
+		if _, err := db.Exec(`INSERT INTO hexids VALUES (?)`, s); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	if err := VerifyStorageOrder(ctx, db, "hexids", "id", 10); !errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("VerifyStorageOrder(hexids) error = %v, want ErrOrderMismatch", err)
+	}
+
+	for _, name := range []string{"", "ids; DROP TABLE ids", "1ids", "ids.", ".ids", "a..b", `"ids"`} {
+		if err := VerifyStorageOrder(ctx, db, name, "bin", 10); err == nil {
+			t.Errorf("VerifyStorageOrder(table %q) error = nil, want error", name)
+		}
+	}
+	if err := VerifyStorageOrder(ctx, db, "missing", "bin", 10); err == nil || errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("VerifyStorageOrder(missing) error = %v, want query error", err)
+	}
+}
+
+func TestPrefixed(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	s := FormatPrefixed("user", id)
+	if s != "user_1K701PSJSB1GW" {
+		t.Fatalf("FormatPrefixed() = %q", s)
+	}
+	for _, in := range []string{s, "sk_live_" + id.ToBase32(), "a1_" + id.ToBase32()} {
+		p, err := ParsePrefixed(in)
+		if err != nil || p.ID != id || p.String() != in {
+			t.Errorf("ParsePrefixed(%q) = %+v, %v", in, p, err)
+		}
+	}
+	for _, in := range []string{"", "user", "_" + id.ToBase32(), "User_" + id.ToBase32(), "user__" + id.ToBase32(),
+		"1user_" + id.ToBase32(), "user_" + id.ToHex(), "user_" + strings.ToLower(id.ToBase32()), strings.Repeat("a", 33) + "_" + id.ToBase32()} {
+		if _, err := ParsePrefixed(in); err == nil {
+			t.Errorf("ParsePrefixed(%q) error = nil, want error", in)
+		}
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("FormatPrefixed() with an invalid prefix should panic")
+			}
+		}()
+		FormatPrefixed("User", id)
+	}()
+
+	// JSON round trip through the text marshalers.
+	type body struct{ Owner PrefixedID }
+	data, err := json.Marshal(body{PrefixedID{Prefix: "user", ID: id}})
+	if err != nil || string(data) != `{"Owner":"user_1K701PSJSB1GW"}` {
+		t.Fatalf("json.Marshal() = %s, %v", data, err)
+	}
+	var got body
+	if err := json.Unmarshal(data, &got); err != nil || got.Owner.ID != id || got.Owner.Prefix != "user" {
+		t.Errorf("json.Unmarshal() = %+v, %v", got, err)
+	}
+	if _, err := json.Marshal(PrefixedID{ID: id}); err == nil {
+		t.Error("json.Marshal() without a prefix should fail")
+	}
+
+	// Once a prefix is registered, unknown prefixes are rejected and known
+	// ones are validated against their entity.
+	launch := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	order := EntityType{Name: "test-prefixed-order", Since: launch, Prefix: "ord"}
+	if err := RegisterEntity(order); err != nil {
+		t.Fatalf("RegisterEntity() error = %v", err)
+	}
+	t.Cleanup(func() {
+		entitiesMu.Lock()
+		defer entitiesMu.Unlock()
+		delete(entities, order.Name)
+	})
+	if err := RegisterEntity(EntityType{Name: "test-prefixed-other", Prefix: "ord"}); err == nil {
+		t.Error("RegisterEntity() with a taken prefix should fail")
+	}
+	if err := RegisterEntity(EntityType{Name: "test-prefixed-bad", Prefix: "Ord"}); err == nil {
+		t.Error("RegisterEntity() with an invalid prefix should fail")
+	}
+	if e, ok := LookupPrefix("ord"); !ok || e.Name != order.Name {
+		t.Errorf("LookupPrefix(ord) = %+v, %v", e, ok)
+	}
+	if _, ok := LookupPrefix(""); ok {
+		t.Error("LookupPrefix(\"\") should find nothing")
+	}
+
+	if _, err := ParsePrefixed(s); !errors.Is(err, ErrUnknownPrefix) {
+		t.Errorf("ParsePrefixed(user) error = %v, want ErrUnknownPrefix", err)
+	}
+	if _, err := ParsePrefixed(FormatPrefixed("ord", id)); !errors.Is(err, ErrPredatesEntity) {
+		t.Errorf("ParsePrefixed(old ord) error = %v, want ErrPredatesEntity", err)
+	}
+	fresh, err := Generate(launch.UnixMilli()+1, nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if p, err := ParsePrefixed(FormatPrefixed("ord", fresh)); err != nil || p.ID != fresh {
+		t.Errorf("ParsePrefixed(new ord) = %+v, %v", p, err)
+	}
+}
+
+func TestMustHelpers(t *testing.T) {
+	if got := MustParse("199c01b6659-5861c"); got != New(0x199C01B66595861C) {
+		t.Errorf("MustParse() = %s", got.ToHex())
+	}
+	before := time.Now().UnixMilli()
+	if id := MustGenerate(); id.GetTimestamp() < before {
+		t.Errorf("MustGenerate() timestamp = %d, want >= %d", id.GetTimestamp(), before)
+	}
+
+	defer func() {
+		r := recover()
+		if msg, _ := r.(string); !strings.HasPrefix(msg, "nano64: ") || !strings.Contains(msg, "not-an-id") {
+			t.Errorf("MustParse(invalid) panic = %v", r)
+		}
+	}()
+	MustParse("not-an-id")
+	t.Error("MustParse(invalid) did not panic")
+}
+
+func TestProfile(t *testing.T) {
+	id := New(0x199C01B66595861C)
+	type row struct {
+		profile Profile
+		text    string
+		json    string
+		sql     any
+	}
+	for _, tt := range []row{
+		{ProfileWeb, "199C01B6659-5861C", `"199C01B6659-5861C"`, id.ToBytes()},
+		{Profile{}, "199C01B6659-5861C", `"199C01B6659-5861C"`, id.ToBytes()},
+		{ProfileAnalytics, "1K701PSJSB1GW", `"1K701PSJSB1GW"`, "1K701PSJSB1GW"},
+		{ProfileLegacyInt, "1845351830215034396", `1845351830215034396`, int64(1845351830215034396)},
+	} {
+		p := tt.profile.Wrap(id)
+		if got := p.String(); got != tt.text {
+			t.Errorf("%s: String() = %q, want %q", tt.profile.Name, got, tt.text)
+		}
+		data, err := json.Marshal(p)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("%s: json.Marshal() = %s, %v, want %s", tt.profile.Name, data, err, tt.json)
+		}
+		v, err := p.Value()
+		if err != nil || fmt.Sprint(v) != fmt.Sprint(tt.sql) {
+			t.Errorf("%s: Value() = %v, %v, want %v", tt.profile.Name, v, err, tt.sql)
+		}
+
+		dec := ProfiledID{Profile: tt.profile}
+		if err := json.Unmarshal(data, &dec); err != nil || dec.ID != id {
+			t.Errorf("%s: json.Unmarshal() = %s, %v", tt.profile.Name, dec.ID.ToHex(), err)
+		}
+		dec = ProfiledID{Profile: tt.profile}
+		if err := dec.UnmarshalText([]byte(tt.text)); err != nil || dec.ID != id {
+			t.Errorf("%s: UnmarshalText() = %s, %v", tt.profile.Name, dec.ID.ToHex(), err)
+		}
+		dec = ProfiledID{Profile: tt.profile}
+		if err := dec.Scan(v); err != nil || dec.ID != id {
+			t.Errorf("%s: Scan(%v) = %s, %v", tt.profile.Name, v, dec.ID.ToHex(), err)
+		}
+	}
+
+	// Text columns may come back as bytes.
+	var scanned ProfiledID
+	scanned.Profile = ProfileAnalytics
+	if err := scanned.Scan([]byte("1K701PSJSB1GW")); err != nil || scanned.ID != id {
+		t.Errorf("Scan([]byte) = %s, %v", scanned.ID.ToHex(), err)
+	}
+	if err := scanned.Scan("not-an-id"); err == nil {
+		t.Error("Scan(invalid) error = nil, want error")
+	}
+	if _, err := ProfileLegacyInt.Parse("1e3"); err == nil {
+		t.Error("legacy-int Parse(1e3) error = nil, want error")
+	}
+
+	// Round trip through a database in each SQL mode.
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "profile.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE ids (web BLOB, analytics TEXT, legacy INTEGER)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO ids VALUES (?, ?, ?)`,
+		ProfileWeb.Wrap(id), ProfileAnalytics.Wrap(id), ProfileLegacyInt.Wrap(id)); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	web, analytics, legacy := ProfiledID{Profile: ProfileWeb}, ProfiledID{Profile: ProfileAnalytics}, ProfiledID{Profile: ProfileLegacyInt}
+	if err := db.QueryRow(`SELECT web, analytics, legacy FROM ids`).Scan(&web, &analytics, &legacy); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if web.ID != id || analytics.ID != id || legacy.ID != id {
+		t.Errorf("database round trip = %s, %s, %s", web.ID.ToHex(), analytics.ID.ToHex(), legacy.ID.ToHex())
+	}
+
+	// Registry.
+	if got := ProfileNames(); !slices.Equal(got, []string{"analytics", "legacy-int", "web"}) {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+	if p, ok := LookupProfile("analytics"); !ok || p.SQL != SQLText {
+		t.Errorf("LookupProfile(analytics) = %+v, %v", p, ok)
+	}
+	b62, _ := LookupEncoding("base62")
+	custom := Profile{Name: "test-short", Text: b62, JSON: HexFormat}
+	if err := RegisterProfile(custom); err != nil {
+		t.Fatalf("RegisterProfile() error = %v", err)
+	}
+	t.Cleanup(func() {
+		profilesMu.Lock()
+		defer profilesMu.Unlock()
+		delete(profiles, custom.Name)
+	})
+	if p, ok := LookupProfile("test-short"); !ok || p.Format(id) != id.ToBase62() {
+		t.Errorf("LookupProfile(test-short) = %+v, %v", p, ok)
+	}
+	for _, bad := range []Profile{{}, custom, ProfileWeb, {Name: "test-bad-json", JSON: Format(9)}, {Name: "test-bad-sql", SQL: SQLMode(9)}} {
+		if err := RegisterProfile(bad); err == nil {
+			t.Errorf("RegisterProfile(%+v) error = nil, want error", bad)
+		}
+	}
+	if _, err := (Profile{SQL: SQLMode(9)}).Value(id); err == nil {
+		t.Error("Value() with an unknown SQL mode should fail")
+	}
+	if SQLText.String() != "text" || SQLMode(9).String() != "SQLMode(9)" {
+		t.Errorf("SQLMode.String() = %q, %q", SQLText.String(), SQLMode(9).String())
+	}
+
+	// Attached to a generator.
+	gen := NewGenerator(WithProfile(ProfileAnalytics))
+	if gen.Profile().Name != "analytics" || NewGenerator().Profile().Name != "" {
+		t.Errorf("Generator.Profile() = %+v", gen.Profile())
+	}
+	generated, err := gen.GenerateMonotonicNow()
+	if err != nil {
+		t.Fatalf("GenerateMonotonicNow() error = %v", err)
+	}
+	if got := gen.Profile().Wrap(generated).String(); got != generated.ToBase32() {
+		t.Errorf("Wrap().String() = %q, want %q", got, generated.ToBase32())
+	}
+}
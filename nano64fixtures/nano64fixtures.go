@@ -0,0 +1,77 @@
+// Package nano64fixtures provides deterministic, well-known IDs for tests.
+//
+// Downstream test suites can share these canonical fixtures instead of
+// generating their own, so golden files, snapshot tests and examples agree on
+// the same values across projects. Every fixture is built with FixedAt and its
+// text forms are pinned by this package's tests, so they never change.
+package nano64fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// ReferenceTime is the timestamp of Reference and MaxRandom: 2025-01-01T00:00:00Z.
+var ReferenceTime = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Fixture is a named, well-known ID with its stable text forms.
+type Fixture struct {
+	// Name identifies the scenario, e.g. "epoch".
+	Name string
+
+	// ID is the fixture value.
+	ID nano64.Nano64
+
+	// Hex is ID.ToHex().
+	Hex string
+
+	// Base32 is ID.ToBase32().
+	Base32 string
+}
+
+var (
+	// Epoch is the smallest non-nil ID: the Unix epoch with random field 1.
+	Epoch = FixedAt(time.UnixMilli(0), 1)
+
+	// Reference is an ordinary present-day ID at ReferenceTime with random field 1.
+	Reference = FixedAt(ReferenceTime, 1)
+
+	// MaxRandom is at ReferenceTime with every random bit set, the last ID
+	// of its millisecond.
+	MaxRandom = FixedAt(ReferenceTime, 1<<nano64.RandomBits-1)
+
+	// FarFuture is the first ID of the last representable millisecond
+	// (2527-06-23T06:20:44.415Z).
+	FarFuture = FixedAt(time.UnixMilli(1<<nano64.TimestampBits-1), 0)
+
+	// Max is the largest ID, with every bit set.
+	Max = FixedAt(time.UnixMilli(1<<nano64.TimestampBits-1), 1<<nano64.RandomBits-1)
+)
+
+// All returns every fixture in ascending ID order, for table-driven tests.
+func All() []Fixture {
+	return []Fixture{
+		{Name: "epoch", ID: Epoch, Hex: "00000000000-00001", Base32: "0000000000001"},
+		{Name: "reference", ID: Reference, Hex: "1941F297C00-00001", Base32: "1JGFJJZ000001"},
+		{Name: "max random", ID: MaxRandom, Hex: "1941F297C00-FFFFF", Base32: "1JGFJJZ00ZZZZ"},
+		{Name: "far future", ID: FarFuture, Hex: "FFFFFFFFFFF-00000", Base32: "FZZZZZZZZ0000"},
+		{Name: "max", ID: Max, Hex: "FFFFFFFFFFF-FFFFF", Base32: "FZZZZZZZZZZZZ"},
+	}
+}
+
+// FixedAt returns the ID with t's Unix millisecond timestamp and seq as its
+// random field, so the same arguments always give the same ID. Sequential
+// seq values at one t give IDs in ascending order. Panics if t is outside
+// the 44-bit timestamp range or seq does not fit in RandomBits bits.
+func FixedAt(t time.Time, seq uint32) nano64.Nano64 {
+	if seq >= 1<<nano64.RandomBits {
+		panic(fmt.Sprintf("nano64fixtures: seq %d does not fit in %d bits", seq, nano64.RandomBits))
+	}
+	id, err := nano64.Generate(t.UnixMilli(), func(int) (uint32, error) { return seq, nil })
+	if err != nil {
+		panic(fmt.Sprintf("nano64fixtures: %v", err))
+	}
+	return id
+}
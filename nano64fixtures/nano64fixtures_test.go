@@ -0,0 +1,55 @@
+package nano64fixtures
+
+import (
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func TestAllStableForms(t *testing.T) {
+	fixtures := All()
+	for i, f := range fixtures {
+		if got := f.ID.ToHex(); got != f.Hex {
+			t.Errorf("%s: ToHex() = %s, want %s", f.Name, got, f.Hex)
+		}
+		if got := f.ID.ToBase32(); got != f.Base32 {
+			t.Errorf("%s: ToBase32() = %s, want %s", f.Name, got, f.Base32)
+		}
+		if i > 0 && nano64.Compare(fixtures[i-1].ID, f.ID) >= 0 {
+			t.Errorf("%s is not after %s", f.Name, fixtures[i-1].Name)
+		}
+	}
+	if Max.Uint64Value() != 1<<64-1 {
+		t.Errorf("Max = %#x, want all bits set", Max.Uint64Value())
+	}
+}
+
+func TestFixedAt(t *testing.T) {
+	at := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	a, b := FixedAt(at, 7), FixedAt(at, 8)
+	if a != FixedAt(at, 7) {
+		t.Error("FixedAt() is not deterministic")
+	}
+	if !a.ToDate().Equal(at) || a.GetRandom() != 7 {
+		t.Errorf("FixedAt() = %v/%d, want %v/7", a.ToDate(), a.GetRandom(), at)
+	}
+	if nano64.Compare(a, b) >= 0 {
+		t.Error("FixedAt() with increasing seq is not ascending")
+	}
+
+	for name, fn := range map[string]func(){
+		"seq overflow":    func() { FixedAt(at, 1<<nano64.RandomBits) },
+		"before epoch":    func() { FixedAt(time.UnixMilli(-1), 0) },
+		"after max range": func() { FixedAt(time.UnixMilli(1<<nano64.TimestampBits), 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("FixedAt() with %s did not panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,118 @@
+package nano64
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format is a wire representation of an ID, chosen per client with Negotiate
+// and applied by FormattedID.
+type Format int
+
+const (
+	// HexFormat is the canonical dashed hex string produced by ToHex, and the default.
+	HexFormat Format = iota
+
+	// Base32Format is the fixed-width Crockford string produced by ToBase32.
+	Base32Format
+
+	// IntFormat is the unsigned 64-bit value as a JSON number. Values above
+	// 2^53 lose precision in JavaScript clients.
+	IntFormat
+)
+
+// String returns the name Negotiate matches: "hex", "base32" or "int".
+func (f Format) String() string {
+	switch f {
+	case HexFormat:
+		return "hex"
+	case Base32Format:
+		return "base32"
+	case IntFormat:
+		return "int"
+	}
+	return "Format(" + strconv.Itoa(int(f)) + ")"
+}
+
+// formatsByName maps the names accepted by Negotiate to formats.
+var formatsByName = map[string]Format{
+	"hex":    HexFormat,
+	"base32": Base32Format,
+	"int":    IntFormat,
+}
+
+// Negotiate picks the ID format a client prefers from Accept-style
+// preferences such as []string{"base32;q=0.9", "int;q=0.5"}. Entries may
+// also hold several comma-separated preferences, so a raw header value can
+// be passed as is. The highest q-value wins (default 1), with earlier entries
+// winning ties. Unknown names and q=0 entries are ignored, and HexFormat is
+// returned when nothing matches, so servers can roll out new formats while
+// older clients keep receiving hex.
+func Negotiate(accept []string) Format {
+	best, bestQ := HexFormat, 0.0
+	for _, entry := range accept {
+		for _, pref := range strings.Split(entry, ",") {
+			name, params, _ := strings.Cut(pref, ";")
+			format, ok := formatsByName[strings.ToLower(strings.TrimSpace(name))]
+			if !ok {
+				continue
+			}
+			q := 1.0
+			for _, param := range strings.Split(params, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if key == "q" {
+					v, err := strconv.ParseFloat(value, 64)
+					if err != nil || v < 0 || v > 1 {
+						v = 0
+					}
+					q = v
+				}
+			}
+			if q > bestQ {
+				best, bestQ = format, q
+			}
+		}
+	}
+	return best
+}
+
+// FormattedID wraps an ID so it marshals to JSON in the given Format, e.g.
+// with the format returned by Negotiate for the current request.
+type FormattedID struct {
+	ID     Nano64
+	Format Format
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f FormattedID) MarshalJSON() ([]byte, error) {
+	switch f.Format {
+	case HexFormat:
+		return appendQuotedHex(make([]byte, 0, idListElementLength), f.ID), nil
+	case Base32Format:
+		return json.Marshal(f.ID.ToBase32())
+	case IntFormat:
+		return strconv.AppendUint(nil, f.ID.value, 10), nil
+	}
+	return nil, fmt.Errorf("unknown ID format %s", f.Format)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts every
+// form Nano64.UnmarshalJSON does, plus Base32 strings when the receiver's
+// Format is Base32Format, so set the format before decoding. Format is not
+// changed.
+func (f *FormattedID) UnmarshalJSON(data []byte) error {
+	if f.Format == Base32Format {
+		var text string
+		if err := json.Unmarshal(data, &text); err == nil && len(text) == Base32Length {
+			id, err := FromBase32(text)
+			if err != nil {
+				return err
+			}
+			f.ID = id
+			return nil
+		}
+	}
+	return f.ID.UnmarshalJSON(data)
+}
@@ -0,0 +1,175 @@
+package nano64
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OrderedSet is a set of IDs kept in time order, backed by a sorted slice.
+// Iteration and String output are always oldest-first, which makes it handy for
+// deterministic tests and small in-memory indexes. Lookups are O(log n);
+// inserts and deletes are O(n). The zero value is ready to use.
+// OrderedSet is not safe for concurrent use.
+type OrderedSet struct {
+	ids []Nano64
+}
+
+// searchIDs returns the position of id in the sorted ids and whether it is present.
+func searchIDs(ids []Nano64, id Nano64) (int, bool) {
+	return slices.BinarySearchFunc(ids, id, Compare)
+}
+
+// Add inserts id and reports whether it was not already present.
+func (s *OrderedSet) Add(id Nano64) bool {
+	i, found := searchIDs(s.ids, id)
+	if found {
+		return false
+	}
+	s.ids = slices.Insert(s.ids, i, id)
+	return true
+}
+
+// Remove deletes id and reports whether it was present.
+func (s *OrderedSet) Remove(id Nano64) bool {
+	i, found := searchIDs(s.ids, id)
+	if !found {
+		return false
+	}
+	s.ids = slices.Delete(s.ids, i, i+1)
+	return true
+}
+
+// Contains reports whether id is in the set.
+func (s *OrderedSet) Contains(id Nano64) bool {
+	_, found := searchIDs(s.ids, id)
+	return found
+}
+
+// Len returns the number of IDs in the set.
+func (s *OrderedSet) Len() int {
+	return len(s.ids)
+}
+
+// IDs returns a copy of the IDs, oldest first.
+func (s *OrderedSet) IDs() []Nano64 {
+	return slices.Clone(s.ids)
+}
+
+// Ascend calls fn for every ID, oldest first, until fn returns false.
+func (s *OrderedSet) Ascend(fn func(id Nano64) bool) {
+	for _, id := range s.ids {
+		if !fn(id) {
+			return
+		}
+	}
+}
+
+// AscendBetween calls fn for every ID in [lo, hi), oldest first, until fn returns false.
+func (s *OrderedSet) AscendBetween(lo, hi Nano64, fn func(id Nano64) bool) {
+	start, _ := searchIDs(s.ids, lo)
+	for _, id := range s.ids[start:] {
+		if id.value >= hi.value || !fn(id) {
+			return
+		}
+	}
+}
+
+// String lists the IDs in time order, e.g. "[199C01B6659-5861C 199C01B665A-00001]".
+func (s *OrderedSet) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, id := range s.ids {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(id.ToHex())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// OrderedMap maps IDs to values and iterates in time order, backed by a sorted
+// slice. Like OrderedSet it suits small indexes and tests; inserts and deletes
+// are O(n). The zero value is ready to use. OrderedMap is not safe for
+// concurrent use.
+type OrderedMap[V any] struct {
+	keys   []Nano64
+	values []V
+}
+
+// Set stores v under id, replacing any previous value.
+func (m *OrderedMap[V]) Set(id Nano64, v V) {
+	i, found := searchIDs(m.keys, id)
+	if found {
+		m.values[i] = v
+		return
+	}
+	m.keys = slices.Insert(m.keys, i, id)
+	m.values = slices.Insert(m.values, i, v)
+}
+
+// Get returns the value stored under id.
+func (m *OrderedMap[V]) Get(id Nano64) (V, bool) {
+	i, found := searchIDs(m.keys, id)
+	if !found {
+		var zero V
+		return zero, false
+	}
+	return m.values[i], true
+}
+
+// Delete removes id and reports whether it was present.
+func (m *OrderedMap[V]) Delete(id Nano64) bool {
+	i, found := searchIDs(m.keys, id)
+	if !found {
+		return false
+	}
+	m.keys = slices.Delete(m.keys, i, i+1)
+	m.values = slices.Delete(m.values, i, i+1)
+	return true
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[V]) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns a copy of the keys, oldest first.
+func (m *OrderedMap[V]) Keys() []Nano64 {
+	return slices.Clone(m.keys)
+}
+
+// Ascend calls fn for every entry, oldest first, until fn returns false.
+func (m *OrderedMap[V]) Ascend(fn func(id Nano64, v V) bool) {
+	for i, id := range m.keys {
+		if !fn(id, m.values[i]) {
+			return
+		}
+	}
+}
+
+// AscendBetween calls fn for every entry with a key in [lo, hi), oldest first,
+// until fn returns false.
+func (m *OrderedMap[V]) AscendBetween(lo, hi Nano64, fn func(id Nano64, v V) bool) {
+	start, _ := searchIDs(m.keys, lo)
+	for i := start; i < len(m.keys); i++ {
+		if m.keys[i].value >= hi.value || !fn(m.keys[i], m.values[i]) {
+			return
+		}
+	}
+}
+
+// String lists the entries in time order, e.g. "map[199C01B6659-5861C:a]".
+func (m *OrderedMap[V]) String() string {
+	var b strings.Builder
+	b.WriteString("map[")
+	for i, id := range m.keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%s:%v", id.ToHex(), m.values[i])
+	}
+	b.WriteByte(']')
+	return b.String()
+}
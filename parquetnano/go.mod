@@ -0,0 +1,18 @@
+module go.codycody31.dev/nano64/parquetnano
+
+go 1.23.0
+
+require (
+	github.com/parquet-go/parquet-go v0.25.1
+	go.codycody31.dev/nano64 v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/andybalholm/brotli v1.2.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.22 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)
+
+replace go.codycody31.dev/nano64 => ../
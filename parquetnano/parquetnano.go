@@ -0,0 +1,168 @@
+// Package parquetnano helps write Nano64 ID columns with parquet-go so that
+// query engines can prune row groups and pages by time range.
+//
+// Two physical layouts preserve ID order in column statistics:
+//
+//   - Int64: INT64 with the unsigned INTEGER(64) logical type. A plain signed
+//     INT64 would order IDs with the top bit set before all others and corrupt
+//     min/max statistics; declare the Go field as uint64 (never int64).
+//   - FixedLenByteArray: FIXED_LEN_BYTE_ARRAY(8) holding big-endian bytes,
+//     compared bytewise. Declare the Go field as [8]byte.
+//
+// NewWriter sorts rows by the ID column within each row group and records
+// that ordering in the sorting_columns metadata, with page statistics enabled
+// so readers can skip pages as well as row groups.
+package parquetnano
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"go.codycody31.dev/nano64"
+)
+
+// Layout selects the Parquet physical type used for an ID column.
+type Layout int
+
+const (
+	// Int64 stores IDs as INT64 annotated INTEGER(64, unsigned).
+	Int64 Layout = iota
+
+	// FixedLenByteArray stores IDs as FIXED_LEN_BYTE_ARRAY(8), big-endian.
+	FixedLenByteArray
+)
+
+// MetadataKey is the file key-value metadata key listing Nano64 columns.
+const MetadataKey = "nano64.columns"
+
+// String returns the layout name recorded in file metadata.
+func (l Layout) String() string {
+	if l == FixedLenByteArray {
+		return "fixed_len_byte_array"
+	}
+	return "int64"
+}
+
+// Node returns the schema node for an ID column in the given layout.
+func Node(layout Layout) parquet.Node {
+	if layout == FixedLenByteArray {
+		return parquet.Leaf(parquet.FixedLenByteArrayType(8))
+	}
+	return parquet.Uint(64)
+}
+
+// Int64Field converts an ID to the value stored in an Int64 column (a uint64 struct field).
+func Int64Field(id nano64.Nano64) uint64 {
+	return id.Uint64Value()
+}
+
+// FixedField converts an ID to the value stored in a FixedLenByteArray column (an [8]byte struct field).
+func FixedField(id nano64.Nano64) [8]byte {
+	var b [8]byte
+	copy(b[:], id.ToBytes())
+	return b
+}
+
+// FromFixedField converts a FixedLenByteArray column value back to an ID.
+func FromFixedField(b [8]byte) nano64.Nano64 {
+	return nano64.New(binary.BigEndian.Uint64(b[:]))
+}
+
+// SortRowCount is the number of rows NewWriter sorts in memory at a time.
+const SortRowCount = 64 * 1024
+
+// NewWriter returns a writer that sorts rows by the ID column and stamps the
+// metadata produced by WriterOptions. Additional options are applied after
+// those defaults.
+func NewWriter[T any](output io.Writer, column string, layout Layout, options ...parquet.WriterOption) *parquet.SortingWriter[T] {
+	opts := append(WriterOptions(column, layout), options...)
+	return parquet.NewSortingWriter[T](output, SortRowCount, opts...)
+}
+
+// WriterOptions returns writer options that declare the ID column as the
+// ascending sort key, enable page-level statistics, and record the column
+// layout in the file's key-value metadata under MetadataKey.
+//
+// The sort key is only metadata: parquet.GenericWriter does not reorder rows,
+// so callers using it directly must write rows already sorted by ID. NewWriter
+// does the sorting.
+//
+// column is the dotted path of the ID column, e.g. "id" or "event.id".
+func WriterOptions(column string, layout Layout) []parquet.WriterOption {
+	path := strings.Split(column, ".")
+	return []parquet.WriterOption{
+		parquet.SortingWriterConfig(
+			parquet.SortingColumns(parquet.Ascending(path...)),
+		),
+		parquet.DataPageStatistics(true),
+		parquet.KeyValueMetadata(MetadataKey, column+":"+layout.String()),
+	}
+}
+
+// TimeRange returns the smallest and largest IDs whose embedded timestamp falls within [from, to].
+// These are the bounds to compare against column statistics for a time-range predicate.
+func TimeRange(from, to time.Time) (lo, hi nano64.Nano64) {
+	epoch := nano64.DefaultEpoch()
+	return boundAt(from.UnixMilli()-epoch, 0), boundAt(to.UnixMilli()-epoch, 1<<nano64.RandomBits-1)
+}
+
+func boundAt(ms int64, random uint64) nano64.Nano64 {
+	if ms < 0 {
+		return nano64.New(0)
+	}
+	if ms >= 1<<nano64.TimestampBits {
+		return nano64.New(^uint64(0))
+	}
+	return nano64.New(uint64(ms)<<nano64.RandomBits | random)
+}
+
+// RowGroupMayContain reports whether the row group may hold IDs created within [from, to],
+// based on the column index (page statistics) of the given ID column.
+// It returns true when no statistics are available, since the row group cannot be ruled out.
+func RowGroupMayContain(rg parquet.RowGroup, column string, from, to time.Time) (bool, error) {
+	leaf, ok := rg.Schema().Lookup(strings.Split(column, ".")...)
+	if !ok {
+		return false, fmt.Errorf("column %q not found in schema", column)
+	}
+
+	index, err := rg.ColumnChunks()[leaf.ColumnIndex].ColumnIndex()
+	if err != nil {
+		return true, nil
+	}
+
+	lo, hi := TimeRange(from, to)
+	for page := 0; page < index.NumPages(); page++ {
+		if index.NullPage(page) {
+			continue
+		}
+		pageMin, err := valueToID(index.MinValue(page))
+		if err != nil {
+			return false, err
+		}
+		pageMax, err := valueToID(index.MaxValue(page))
+		if err != nil {
+			return false, err
+		}
+		if nano64.Compare(pageMax, lo) >= 0 && nano64.Compare(pageMin, hi) <= 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// valueToID converts a column statistic value to an ID.
+func valueToID(v parquet.Value) (nano64.Nano64, error) {
+	switch v.Kind() {
+	case parquet.Int64:
+		return nano64.New(v.Uint64()), nil
+	case parquet.FixedLenByteArray, parquet.ByteArray:
+		return nano64.FromBytes(v.ByteArray())
+	default:
+		return nano64.Nano64{}, fmt.Errorf("unsupported Parquet kind %s for Nano64", v.Kind())
+	}
+}
@@ -0,0 +1,181 @@
+package parquetnano
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"go.codycody31.dev/nano64"
+)
+
+type int64Row struct {
+	ID   uint64 `parquet:"id"`
+	Name string `parquet:"name"`
+}
+
+type fixedRow struct {
+	ID   [8]byte `parquet:"id"`
+	Name string  `parquet:"name"`
+}
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func idAt(t *testing.T, offset time.Duration, random uint32) nano64.Nano64 {
+	t.Helper()
+	id, err := nano64.Generate(base.Add(offset).UnixMilli(), func(int) (uint32, error) { return random, nil })
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	return id
+}
+
+func writeInt64File(t *testing.T, ids []nano64.Nano64) *parquet.File {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter[int64Row](&buf, "id", Int64)
+	rows := make([]int64Row, len(ids))
+	for i, id := range ids {
+		rows[i] = int64Row{ID: Int64Field(id), Name: id.ToHex()}
+	}
+	if _, err := w.Write(rows); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	return f
+}
+
+func TestWriterOptions_SortingAndMetadata(t *testing.T) {
+	// Written out of order, including an ID with the top bit set.
+	ids := []nano64.Nano64{
+		idAt(t, time.Hour, 1),
+		nano64.New(1<<63 | 5),
+		idAt(t, 0, 7),
+		idAt(t, time.Minute, 3),
+	}
+	f := writeInt64File(t, ids)
+
+	if v, ok := f.Lookup(MetadataKey); !ok || v != "id:int64" {
+		t.Errorf("metadata %s = %q, %v; want %q", MetadataKey, v, ok, "id:int64")
+	}
+
+	groups := f.RowGroups()
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 row group, got %d", len(groups))
+	}
+	sorting := groups[0].SortingColumns()
+	if len(sorting) != 1 || sorting[0].Path()[0] != "id" || sorting[0].Descending() {
+		t.Errorf("SortingColumns() = %v, want ascending(id)", sorting)
+	}
+
+	rows := make([]int64Row, len(ids))
+	n, _ := parquet.NewGenericRowGroupReader[int64Row](groups[0]).Read(rows)
+	if n != len(ids) {
+		t.Fatalf("read %d rows, want %d", n, len(ids))
+	}
+	for i := 1; i < n; i++ {
+		if rows[i-1].ID > rows[i].ID {
+			t.Errorf("rows not sorted by unsigned ID at %d: %d > %d", i, rows[i-1].ID, rows[i].ID)
+		}
+	}
+	if rows[n-1].ID != 1<<63|5 {
+		t.Errorf("top-bit ID should sort last, got %d", rows[n-1].ID)
+	}
+}
+
+func TestRowGroupMayContain(t *testing.T) {
+	ids := []nano64.Nano64{
+		idAt(t, 0, 1),
+		idAt(t, time.Minute, 2),
+		idAt(t, time.Hour, 3),
+	}
+	f := writeInt64File(t, ids)
+	rg := f.RowGroups()[0]
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     bool
+	}{
+		{"covers all", base.Add(-time.Hour), base.Add(2 * time.Hour), true},
+		{"exact first", base, base, true},
+		{"inside range", base.Add(30 * time.Second), base.Add(2 * time.Minute), true},
+		{"before", base.Add(-2 * time.Hour), base.Add(-time.Hour), false},
+		{"after", base.Add(2 * time.Hour), base.Add(3 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RowGroupMayContain(rg, "id", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("RowGroupMayContain() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RowGroupMayContain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RowGroupMayContain(rg, "missing", base, base); err == nil {
+		t.Error("RowGroupMayContain(missing column) error = nil, want error")
+	}
+}
+
+func TestFixedLenByteArray(t *testing.T) {
+	ids := []nano64.Nano64{idAt(t, time.Hour, 1), idAt(t, 0, 2)}
+
+	var buf bytes.Buffer
+	w := NewWriter[fixedRow](&buf, "id", FixedLenByteArray)
+	rows := []fixedRow{{ID: FixedField(ids[0])}, {ID: FixedField(ids[1])}}
+	if _, err := w.Write(rows); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if v, _ := f.Lookup(MetadataKey); v != "id:fixed_len_byte_array" {
+		t.Errorf("metadata = %q, want %q", v, "id:fixed_len_byte_array")
+	}
+
+	leaf, ok := f.Schema().Lookup("id")
+	if !ok || leaf.Node.Type().Kind() != parquet.FixedLenByteArray {
+		t.Fatalf("id column is not FIXED_LEN_BYTE_ARRAY")
+	}
+
+	got, err := RowGroupMayContain(f.RowGroups()[0], "id", base.Add(time.Hour), base.Add(time.Hour))
+	if err != nil || !got {
+		t.Errorf("RowGroupMayContain() = %v, %v; want true", got, err)
+	}
+	got, err = RowGroupMayContain(f.RowGroups()[0], "id", base.Add(2*time.Hour), base.Add(3*time.Hour))
+	if err != nil || got {
+		t.Errorf("RowGroupMayContain() = %v, %v; want false", got, err)
+	}
+
+	if back := FromFixedField(FixedField(ids[0])); !back.Equals(ids[0]) {
+		t.Errorf("FromFixedField() = %d, want %d", back.Uint64Value(), ids[0].Uint64Value())
+	}
+}
+
+func TestNode(t *testing.T) {
+	if k := Node(Int64).Type().Kind(); k != parquet.Int64 {
+		t.Errorf("Node(Int64) kind = %s, want INT64", k)
+	}
+	if lt := Node(Int64).Type().LogicalType(); lt == nil || lt.Integer == nil || lt.Integer.IsSigned {
+		t.Errorf("Node(Int64) must be annotated as unsigned integer")
+	}
+	if k := Node(FixedLenByteArray).Type().Kind(); k != parquet.FixedLenByteArray {
+		t.Errorf("Node(FixedLenByteArray) kind = %s, want FIXED_LEN_BYTE_ARRAY", k)
+	}
+}
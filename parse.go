@@ -0,0 +1,295 @@
+package nano64
+
+import "fmt"
+
+// ParseOptions controls which textual forms ParseWithOptions accepts.
+// The zero value accepts only the canonical form produced by ToHex
+// ("XXXXXXXXXXX-XXXXX", uppercase) with no timestamp limit.
+type ParseOptions struct {
+	// AllowPrefix accepts a leading "0x" or "0X".
+	AllowPrefix bool
+
+	// AllowLowercase accepts lowercase hex digits.
+	AllowLowercase bool
+
+	// AllowUndashed accepts the plain 16-digit form without the dash.
+	AllowUndashed bool
+
+	// AllowGrouping accepts single dashes between any two digits, such as
+	// the 4-4-4-4 grouping produced by FormatHex(GroupQuads).
+	AllowGrouping bool
+
+	// MaxTimestamp rejects IDs whose timestamp is after this Unix millisecond.
+	// Zero disables the check.
+	MaxTimestamp int64
+}
+
+// LenientParseOptions accepts every form FromHex accepts, with no timestamp limit.
+var LenientParseOptions = ParseOptions{
+	AllowPrefix:    true,
+	AllowLowercase: true,
+	AllowUndashed:  true,
+	AllowGrouping:  true,
+}
+
+// Parse parses a hex ID using LenientParseOptions: any FormatHex grouping,
+// either case, and an optional 0x prefix. Unlike FromHex, dashes must sit
+// between digits and may not repeat. Input that is not hex is tried against
+// the encodings added with RegisterEncoding, in registration order; if none
+// accepts it, the hex *ParseError is returned.
+func Parse(s string) (Nano64, error) {
+	id, err := ParseWithOptions(s, LenientParseOptions)
+	if err != nil {
+		if decoded, ok := decodeRegistered(s); ok {
+			return decoded, nil
+		}
+	}
+	return id, err
+}
+
+// MustParse is like Parse but panics if s cannot be parsed. It simplifies
+// tests, fixtures and package-level variables such as
+//
+//	var systemUser = nano64.MustParse("199C01B6659-5861C")
+func MustParse(s string) Nano64 {
+	id, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("nano64: %v", err))
+	}
+	return id
+}
+
+// ParseWithOptions parses a hex ID, accepting only the forms enabled in opts and
+// rejecting IDs whose timestamp exceeds opts.MaxTimestamp.
+func ParseWithOptions(s string, opts ParseOptions) (Nano64, error) {
+	i := 0
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		if !opts.AllowPrefix {
+			return Nano64{}, &ParseError{Input: s, Offset: 1, Expected: "hex digit (0x prefix not allowed)"}
+		}
+		i = 2
+	}
+
+	var value uint64
+	digits := 0
+	dashed := false
+	lastDash := -1
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '-' {
+			canonical := digits == 11 && !dashed
+			grouped := opts.AllowGrouping && digits > 0 && lastDash != i-1 && i+1 < len(s)
+			if !canonical && !grouped {
+				return Nano64{}, &ParseError{Input: s, Offset: i, Expected: "hex digit"}
+			}
+			dashed = true
+			lastDash = i
+			continue
+		}
+		d, ok := hexDigit(c, opts.AllowLowercase)
+		if !ok {
+			expected := "hex digit"
+			if !opts.AllowLowercase {
+				expected = "uppercase hex digit"
+			}
+			return Nano64{}, &ParseError{Input: s, Offset: i, Expected: expected}
+		}
+		if digits == 16 {
+			return Nano64{}, &ParseError{Input: s, Offset: i, Expected: "end of input after 16 hex digits"}
+		}
+		value = value<<4 | uint64(d)
+		digits++
+	}
+
+	if digits != 16 {
+		return Nano64{}, &ParseError{Input: s, Offset: len(s), Expected: "16 hex digits"}
+	}
+	if !dashed && !opts.AllowUndashed {
+		return Nano64{}, &ParseError{Input: s, Offset: -1, Expected: "'-' between timestamp and random parts"}
+	}
+
+	id := Nano64{value: value}
+	if opts.MaxTimestamp > 0 && id.GetTimestamp() > opts.MaxTimestamp {
+		return Nano64{}, &ParseError{Input: s, Offset: -1, Expected: fmt.Sprintf("timestamp at most %d, got %d", opts.MaxTimestamp, id.GetTimestamp())}
+	}
+	return id, nil
+}
+
+// hexDigit decodes one hex character, optionally accepting lowercase.
+func hexDigit(c byte, allowLower bool) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	case allowLower && c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	}
+	return 0, false
+}
+
+// LetterCase describes the case of the hex letters in a parsed ID.
+type LetterCase int
+
+const (
+	// CaseNone means the ID contained only decimal digits.
+	CaseNone LetterCase = iota
+
+	// CaseUpper means every hex letter was uppercase.
+	CaseUpper
+
+	// CaseLower means every hex letter was lowercase.
+	CaseLower
+
+	// CaseMixed means both cases appeared.
+	CaseMixed
+)
+
+// String returns the case name.
+func (c LetterCase) String() string {
+	switch c {
+	case CaseUpper:
+		return "upper"
+	case CaseLower:
+		return "lower"
+	case CaseMixed:
+		return "mixed"
+	}
+	return "none"
+}
+
+// ParseInfo describes how an ID was written by the client.
+type ParseInfo struct {
+	// Grouping is the detected dash grouping. Meaningless if Irregular is true.
+	Grouping GroupOption
+
+	// Irregular is true when dashes matched no GroupOption.
+	Irregular bool
+
+	// Case is the case of the hex letters.
+	Case LetterCase
+
+	// Prefix is true when the input started with "0x" or "0X".
+	Prefix bool
+
+	// Normalizations lists the changes needed to reach the canonical form,
+	// e.g. "strip prefix", "uppercase", "regroup". Empty for canonical input.
+	Normalizations []string
+}
+
+// Canonical reports whether the input was already in the canonical ToHex form.
+func (i ParseInfo) Canonical() bool {
+	return len(i.Normalizations) == 0
+}
+
+// ParseDetailed parses hex like Parse, without trying registered encodings, and
+// also reports how the input was formatted, so gateways can log client
+// formatting and enforce canonicalization policies.
+func ParseDetailed(s string) (Nano64, ParseInfo, error) {
+	id, err := ParseWithOptions(s, LenientParseOptions)
+	if err != nil {
+		return Nano64{}, ParseInfo{}, err
+	}
+
+	var info ParseInfo
+	body := s
+	if len(body) >= 2 && body[0] == '0' && (body[1] == 'x' || body[1] == 'X') {
+		info.Prefix = true
+		body = body[2:]
+	}
+
+	var dashes []int
+	upper, lower := false, false
+	digits := 0
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '-':
+			dashes = append(dashes, digits)
+			continue
+		case c >= 'A' && c <= 'F':
+			upper = true
+		case c >= 'a' && c <= 'f':
+			lower = true
+		}
+		digits++
+	}
+
+	switch {
+	case upper && lower:
+		info.Case = CaseMixed
+	case upper:
+		info.Case = CaseUpper
+	case lower:
+		info.Case = CaseLower
+	}
+
+	switch {
+	case len(dashes) == 0:
+		info.Grouping = GroupNone
+	case len(dashes) == 1 && dashes[0] == 11:
+		info.Grouping = GroupCanonical
+	case len(dashes) == 3 && dashes[0] == 4 && dashes[1] == 8 && dashes[2] == 12:
+		info.Grouping = GroupQuads
+	default:
+		info.Irregular = true
+	}
+
+	if info.Prefix {
+		info.Normalizations = append(info.Normalizations, "strip prefix")
+	}
+	if info.Case == CaseLower || info.Case == CaseMixed {
+		info.Normalizations = append(info.Normalizations, "uppercase")
+	}
+	if info.Irregular || info.Grouping != GroupCanonical {
+		info.Normalizations = append(info.Normalizations, "regroup")
+	}
+	return id, info, nil
+}
+
+// NormalizeHex returns the canonical ToHex form ("XXXXXXXXXXX-XXXXX",
+// uppercase) of any input Parse accepts, for building cache keys and the like
+// from request strings. Input that is already canonical is returned as is
+// without allocating. Hex in either case, dashed at position 11 or undashed,
+// is rewritten directly without decoding; other forms go through Parse.
+func NormalizeHex(s string) (string, error) {
+	if IsValidHex(s) {
+		return s, nil
+	}
+	if out, ok := rewriteHex(s); ok {
+		return out, nil
+	}
+	id, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return id.ToHex(), nil
+}
+
+// rewriteHex uppercases 16 hex digits, optionally dashed after the 11th, into
+// the canonical form. It reports false for anything else.
+func rewriteHex(s string) (string, bool) {
+	dashed := len(s) == 17 && s[11] == '-'
+	if len(s) != 16 && !dashed {
+		return "", false
+	}
+	var buf [17]byte
+	buf[11] = '-'
+	for i, j := 0, 0; i < len(s); i++ {
+		if dashed && i == 11 {
+			continue
+		}
+		c := s[i]
+		if _, ok := hexDigit(c, true); !ok {
+			return "", false
+		}
+		if c >= 'a' {
+			c -= 'a' - 'A'
+		}
+		if j == 11 {
+			j++
+		}
+		buf[j] = c
+		j++
+	}
+	return string(buf[:]), true
+}
@@ -0,0 +1,126 @@
+package nano64
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// Partition is one time slice of a PartitionPlan.
+type Partition struct {
+	// Name is a stable partition suffix ("p000", "p001", ...).
+	Name string
+
+	// Start and End bound the slice in time; End is exclusive.
+	Start, End time.Time
+
+	// From is the smallest ID in the slice; To is the smallest ID of the next
+	// slice and is exclusive.
+	From, To Nano64
+}
+
+// Plan splits an ID-keyed table into contiguous time ranges.
+type Plan struct {
+	Partitions []Partition
+}
+
+// PartitionPlan splits [start, end) into the given number of equal-duration
+// partitions and derives the boundary IDs from the times alone, so any service
+// can compute which partition an ID belongs to without a lookup table.
+func PartitionPlan(start, end time.Time, partitions int) (Plan, error) {
+	if partitions < 1 {
+		return Plan{}, fmt.Errorf("partitions must be at least 1, got %d", partitions)
+	}
+	epoch := DefaultEpoch()
+	from, to := start.UnixMilli()-epoch, end.UnixMilli()-epoch
+	if from < 0 || to > maxTimestamp {
+		return Plan{}, fmt.Errorf("time range outside 44-bit timestamp range")
+	}
+	if to-from < int64(partitions) {
+		return Plan{}, fmt.Errorf("time range %v is too short for %d partitions", end.Sub(start), partitions)
+	}
+
+	span := to - from
+	plan := Plan{Partitions: make([]Partition, partitions)}
+	for i := range plan.Partitions {
+		lo := from + scaleSpan(span, i, partitions)
+		hi := from + scaleSpan(span, i+1, partitions)
+		plan.Partitions[i] = Partition{
+			Name:  fmt.Sprintf("p%03d", i),
+			Start: time.UnixMilli(epoch + lo).UTC(),
+			End:   time.UnixMilli(epoch + hi).UTC(),
+			From:  minIDAt(lo),
+			To:    minIDAt(hi),
+		}
+	}
+	return plan, nil
+}
+
+// Boundaries returns the partitions+1 boundary IDs: the first partition's From
+// followed by every partition's To.
+func (p Plan) Boundaries() []Nano64 {
+	if len(p.Partitions) == 0 {
+		return nil
+	}
+	ids := make([]Nano64, 0, len(p.Partitions)+1)
+	ids = append(ids, p.Partitions[0].From)
+	for _, part := range p.Partitions {
+		ids = append(ids, part.To)
+	}
+	return ids
+}
+
+// Locate returns the index of the partition containing id, or -1 if id falls
+// outside the plan.
+func (p Plan) Locate(id Nano64) int {
+	for i, part := range p.Partitions {
+		if id.value >= part.From.value && id.value < part.To.value {
+			return i
+		}
+	}
+	return -1
+}
+
+// PostgresDDL returns one CREATE TABLE ... PARTITION OF statement per partition
+// for a parent table declared PARTITION BY RANGE on a BYTEA ID column.
+func (p Plan) PostgresDDL(table string) []string {
+	stmts := make([]string, len(p.Partitions))
+	for i, part := range p.Partitions {
+		stmts[i] = fmt.Sprintf(
+			`CREATE TABLE %s_%s PARTITION OF %s FOR VALUES FROM ('\x%s') TO ('\x%s');`,
+			table, part.Name, table, hex.EncodeToString(part.From.ToBytes()), hex.EncodeToString(part.To.ToBytes()))
+	}
+	return stmts
+}
+
+// VitessShards returns Vitess shard key ranges for a keyspace sharded by a
+// binary vindex on the 8-byte ID. The first shard is open below and the last
+// open above, so the ranges cover the whole keyspace.
+func (p Plan) VitessShards() []string {
+	shards := make([]string, len(p.Partitions))
+	for i, part := range p.Partitions {
+		lo, hi := hex.EncodeToString(part.From.ToBytes()), hex.EncodeToString(part.To.ToBytes())
+		if i == 0 {
+			lo = ""
+		}
+		if i == len(p.Partitions)-1 {
+			hi = ""
+		}
+		shards[i] = lo + "-" + hi
+	}
+	return shards
+}
+
+// scaleSpan returns span*i/n without overflowing: the product can exceed 64
+// bits, but for 0 <= i <= n the quotient cannot.
+func scaleSpan(span int64, i, n int) int64 {
+	hi, lo := bits.Mul64(uint64(span), uint64(i))
+	quo, _ := bits.Div64(hi, lo, uint64(n))
+	return int64(quo)
+}
+
+// minIDAt returns the smallest ID with the given millisecond timestamp.
+func minIDAt(ms int64) Nano64 {
+	return Nano64{value: uint64(ms) << timestampShift}
+}
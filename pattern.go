@@ -0,0 +1,70 @@
+package nano64
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// HexPattern matches the canonical text form produced by ToHex.
+	HexPattern = `^[0-9A-F]{11}-[0-9A-F]{5}$`
+
+	// Base32Pattern matches the form produced by ToBase32. The first character
+	// carries only the top four bits, so it is limited to 0-F.
+	Base32Pattern = `^[0-9A-F][0-9A-HJKMNP-TV-Z]{12}$`
+)
+
+var (
+	// HexRegexp is HexPattern compiled.
+	HexRegexp = regexp.MustCompile(HexPattern)
+
+	// Base32Regexp is Base32Pattern compiled.
+	Base32Regexp = regexp.MustCompile(Base32Pattern)
+)
+
+// IsValidHex reports whether s is in the canonical ToHex form, exactly as
+// HexPattern does, without allocating or running a regular expression.
+func IsValidHex(s string) bool {
+	if len(s) != 17 || s[11] != '-' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if i == 11 {
+			continue
+		}
+		if _, ok := hexDigit(s[i], false); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalExample is a valid canonical ID (2025-10-07T19:17:25.209Z).
+const canonicalExample = "199C01B6659-5861C"
+
+// Pattern returns the regular expression matching the canonical text form
+// produced by ToHex: 11 uppercase hex timestamp digits, a dash, and 5 uppercase
+// hex random digits. Every string it matches is accepted by FromHex.
+//
+// The expression uses only RE2 syntax shared by Go, CUE, Terraform's regex()
+// and JSON Schema, so config validation can reuse it verbatim, e.g. in Terraform:
+//
+//	validation {
+//	  condition = can(regex("^[0-9A-F]{11}-[0-9A-F]{5}$", var.tenant_id))
+//	}
+func Pattern() string {
+	return HexPattern
+}
+
+// Example returns a valid canonical ID string for documentation and schema examples.
+func Example() string {
+	return canonicalExample
+}
+
+// CUEDefinition returns a CUE definition constraining a string to the canonical
+// ID form, for inclusion in CUE schemas, e.g. CUEDefinition("Nano64") yields:
+//
+//	#Nano64: =~"^[0-9A-F]{11}-[0-9A-F]{5}$"
+func CUEDefinition(name string) string {
+	return fmt.Sprintf("#%s: =~%q", name, HexPattern)
+}
@@ -0,0 +1,107 @@
+package nano64
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxPrefixLength bounds the length of a type prefix.
+const maxPrefixLength = 32
+
+// PrefixedID is an ID with a type prefix, in the style of Stripe's "cus_..."
+// identifiers: FormatPrefixed("user", id) is "user_" followed by the 13-char
+// ToBase32 form, e.g. "user_1K701PSJSB1GW". The prefix tells people and logs
+// what an ID refers to at a glance, and since the Base32 part is fixed-width,
+// IDs of one type still sort by time.
+//
+// PrefixedID implements encoding.TextMarshaler and TextUnmarshaler, so it
+// can be used directly in JSON payloads.
+type PrefixedID struct {
+	Prefix string
+	ID     Nano64
+}
+
+// String returns FormatPrefixed(p.Prefix, p.ID).
+func (p PrefixedID) String() string {
+	return FormatPrefixed(p.Prefix, p.ID)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p PrefixedID) MarshalText() ([]byte, error) {
+	if !isValidPrefix(p.Prefix) {
+		return nil, fmt.Errorf("invalid prefix %q", p.Prefix)
+	}
+	return []byte(FormatPrefixed(p.Prefix, p.ID)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface using ParsePrefixed.
+func (p *PrefixedID) UnmarshalText(text []byte) error {
+	parsed, err := ParsePrefixed(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// FormatPrefixed returns prefix, an underscore and the ToBase32 form of id.
+// A prefix is 1-32 lowercase letters, digits and underscores, starting with
+// a letter and not ending with an underscore. FormatPrefixed panics if prefix
+// is invalid.
+func FormatPrefixed(prefix string, id Nano64) string {
+	if !isValidPrefix(prefix) {
+		panic(fmt.Sprintf("nano64: invalid prefix %q", prefix))
+	}
+	return prefix + "_" + id.ToBase32()
+}
+
+// ParsePrefixed parses an ID produced by FormatPrefixed into its prefix and
+// ID. The prefix ends at the last underscore, so prefixes such as "sk_live"
+// work.
+//
+// The entity registry is optional. If no registered EntityType has a Prefix,
+// any valid prefix is accepted. Once one does, a prefix no entity type uses
+// is rejected with an error wrapping ErrUnknownPrefix, and IDs with a known
+// prefix are validated against their entity type (see EntityType.Validate).
+func ParsePrefixed(s string) (PrefixedID, error) {
+	i := strings.LastIndexByte(s, '_')
+	if i < 0 {
+		return PrefixedID{}, fmt.Errorf("invalid prefixed ID %q: missing '_'", s)
+	}
+	prefix := s[:i]
+	if !isValidPrefix(prefix) {
+		return PrefixedID{}, fmt.Errorf("invalid prefixed ID %q: invalid prefix %q", s, prefix)
+	}
+	id, err := FromBase32(s[i+1:])
+	if err != nil {
+		return PrefixedID{}, fmt.Errorf("invalid prefixed ID %q: %w", s, err)
+	}
+	t, ok, inUse := prefixEntity(prefix)
+	switch {
+	case ok:
+		if err := t.Validate(id); err != nil {
+			return PrefixedID{}, err
+		}
+	case inUse:
+		return PrefixedID{}, fmt.Errorf("%w: %q", ErrUnknownPrefix, prefix)
+	}
+	return PrefixedID{Prefix: prefix, ID: id}, nil
+}
+
+// isValidPrefix reports whether prefix is 1-32 lowercase letters, digits and
+// underscores, starting with a letter and not ending with an underscore.
+func isValidPrefix(prefix string) bool {
+	if len(prefix) == 0 || len(prefix) > maxPrefixLength {
+		return false
+	}
+	if prefix[0] < 'a' || prefix[0] > 'z' || prefix[len(prefix)-1] == '_' {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package nano64
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// pressureAlpha is the EWMA weight of the newest millisecond, giving an
+// effective window of roughly the last ten milliseconds.
+const pressureAlpha = 0.2
+
+// pressureMeter tracks an exponentially weighted moving average of the
+// fraction of the per-ms random space consumed. It is safe for concurrent
+// use: counting within a millisecond is a single atomic add, and the mutex is
+// only taken when a new millisecond starts or the value is read. An ID
+// recorded while another goroutine rolls the millisecond over may be counted
+// in the wrong one, which is fine for a gauge.
+type pressureMeter struct {
+	ms    atomic.Int64 // millisecond currently being counted
+	count atomic.Int64 // IDs issued in ms
+
+	mu   sync.Mutex
+	ewma float64 // average up to, but excluding, ms
+
+	capacity int // IDs available per ms; zero means CapacityPerMillisecond()
+}
+
+// record counts one ID issued with timestamp ms.
+func (m *pressureMeter) record(ms int64) {
+	if ms > m.ms.Load() {
+		m.mu.Lock()
+		if ms > m.ms.Load() {
+			m.ewma = m.valueLocked(ms)
+			m.count.Store(0)
+			m.ms.Store(ms)
+		}
+		m.mu.Unlock()
+	}
+	m.count.Add(1)
+}
+
+// value returns the average as of the start of millisecond now, folding in
+// the partially counted millisecond and decaying over idle milliseconds.
+func (m *pressureMeter) value(now int64) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.valueLocked(now)
+}
+
+// valueLocked implements value; the caller holds m.mu.
+func (m *pressureMeter) valueLocked(now int64) float64 {
+	capacity := m.capacity
+	if capacity == 0 {
+		capacity = CapacityPerMillisecond()
+	}
+	frac := float64(m.count.Load()) / float64(capacity)
+	v := m.ewma*(1-pressureAlpha) + pressureAlpha*frac
+	if idle := now - m.ms.Load() - 1; idle > 0 {
+		v *= math.Pow(1-pressureAlpha, float64(idle))
+	}
+	return v
+}
+
+// reset clears all observations, keeping the capacity.
+func (m *pressureMeter) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ewma = 0
+	m.ms.Store(0)
+	m.count.Store(0)
+}
+
+// MonotonicPressure returns the fraction (0-1) of the per-millisecond random
+// space consumed by the package-level monotonic generator, averaged over
+// roughly the last ten milliseconds. Values approaching 1 mean generation is
+// about to borrow future milliseconds, so services can shed load or scale out
+// first.
+func MonotonicPressure() float64 {
+	return monotonic.pressure.value(now())
+}
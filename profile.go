@@ -0,0 +1,283 @@
+package nano64
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// SQLMode is how a Profile stores IDs in SQL columns.
+type SQLMode int
+
+const (
+	// SQLBytes stores the 8 big-endian bytes, like Nano64.Value, in a
+	// BYTEA/BLOB/BINARY(8) column. Byte order matches time order. The default.
+	SQLBytes SQLMode = iota
+
+	// SQLInt64 stores the value reinterpreted as a signed 64-bit integer for
+	// BIGINT columns. IDs with the top bit set sort before all others.
+	SQLInt64
+
+	// SQLText stores the profile's text form.
+	SQLText
+)
+
+// String returns "bytes", "int64" or "text".
+func (m SQLMode) String() string {
+	switch m {
+	case SQLBytes:
+		return "bytes"
+	case SQLInt64:
+		return "int64"
+	case SQLText:
+		return "text"
+	}
+	return "SQLMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// Profile bundles the text, JSON and SQL representations one subsystem uses,
+// so a codebase configures them together rather than one marshaling
+// dimension at a time. Pick a built-in profile or register your own, and
+// apply it with ProfiledID, or attach it to a Generator with WithProfile.
+// The zero Profile behaves like plain Nano64: hex text and JSON, bytes in SQL.
+type Profile struct {
+	// Name identifies the profile, e.g. "web". Names are case-sensitive.
+	Name string
+
+	// Text is the text encoding, used by Format, Parse and MarshalText.
+	// Nil means hex.
+	Text Encoding
+
+	// JSON is the JSON representation, as for FormattedID.
+	JSON Format
+
+	// SQL is the column representation.
+	SQL SQLMode
+}
+
+var (
+	// ProfileWeb suits public APIs: canonical hex everywhere a client sees
+	// the ID, and compact bytes in the database.
+	ProfileWeb = Profile{Name: "web"}
+
+	// ProfileAnalytics suits exports and warehouses: fixed-width Base32 in
+	// text, JSON and SQL, which sorts by time as a plain string and reads
+	// well in BI tools.
+	ProfileAnalytics = Profile{Name: "analytics", Text: base32Encoding, JSON: Base32Format, SQL: SQLText}
+
+	// ProfileLegacyInt suits systems built around integer IDs: decimal text,
+	// JSON numbers and BIGINT columns. JavaScript clients lose precision on
+	// values above 2^53.
+	ProfileLegacyInt = Profile{Name: "legacy-int", Text: decimalEncoding, JSON: IntFormat, SQL: SQLInt64}
+)
+
+var (
+	base32Encoding  = builtinEncoding{"base32", Nano64.ToBase32, FromBase32}
+	decimalEncoding = builtinEncoding{"decimal", Nano64.toDecimal, fromDecimal}
+)
+
+// toDecimal returns the unsigned value in base 10.
+func (n Nano64) toDecimal() string {
+	return strconv.FormatUint(n.value, 10)
+}
+
+// fromDecimal parses an unsigned base-10 value.
+func fromDecimal(s string) (Nano64, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("invalid decimal ID %q: %w", s, err)
+	}
+	return Nano64{value: v}, nil
+}
+
+// Format returns the text form of id.
+func (p Profile) Format(id Nano64) string {
+	if p.Text == nil {
+		return id.ToHex()
+	}
+	return p.Text.Encode(id)
+}
+
+// Parse parses the text form of an ID.
+func (p Profile) Parse(s string) (Nano64, error) {
+	if p.Text == nil {
+		return FromHex(s)
+	}
+	return p.Text.Decode(s)
+}
+
+// Value returns id as a driver.Value for the profile's SQLMode.
+func (p Profile) Value(id Nano64) (driver.Value, error) {
+	switch p.SQL {
+	case SQLBytes:
+		return id.Value()
+	case SQLInt64:
+		return int64(id.value), nil
+	case SQLText:
+		return p.Format(id), nil
+	}
+	return nil, fmt.Errorf("unknown SQL mode %s", p.SQL)
+}
+
+// Scan decodes a column value. It accepts whatever Nano64.Scan does, and
+// decodes strings, and byte slices other than 8 bytes, with Parse. In SQLText
+// mode every byte slice is treated as text.
+func (p Profile) Scan(src any) (Nano64, error) {
+	switch v := src.(type) {
+	case string:
+		return p.Parse(v)
+	case []byte:
+		if p.SQL == SQLText || len(v) != 8 {
+			return p.Parse(string(v))
+		}
+	}
+	var id Nano64
+	err := id.Scan(src)
+	return id, err
+}
+
+// Wrap returns id bound to the profile.
+func (p Profile) Wrap(id Nano64) ProfiledID {
+	return ProfiledID{ID: id, Profile: p}
+}
+
+// WithProfile attaches p to the generator, so a subsystem's IDs carry its
+// serialization settings: wrap generated IDs with g.Profile().Wrap(id). It
+// does not change how IDs are generated.
+func WithProfile(p Profile) GeneratorOption {
+	return func(g *Generator) {
+		g.profile = p
+	}
+}
+
+// Profile returns the profile attached with WithProfile, or the zero Profile.
+func (g *Generator) Profile() Profile {
+	return g.profile
+}
+
+// validate reports a Profile with an unknown JSON format or SQL mode.
+func (p Profile) validate() error {
+	if _, ok := formatsByName[p.JSON.String()]; !ok {
+		return fmt.Errorf("profile %q: unknown JSON format %s", p.Name, p.JSON)
+	}
+	if p.SQL < SQLBytes || p.SQL > SQLText {
+		return fmt.Errorf("profile %q: unknown SQL mode %s", p.Name, p.SQL)
+	}
+	return nil
+}
+
+// ProfiledID wraps an ID so it marshals to text, JSON and SQL as its Profile
+// says. Like FormattedID, set Profile before decoding into one; decoding
+// leaves it unchanged.
+type ProfiledID struct {
+	ID      Nano64
+	Profile Profile
+}
+
+// String returns the profile's text form.
+func (p ProfiledID) String() string {
+	return p.Profile.Format(p.ID)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p ProfiledID) MarshalText() ([]byte, error) {
+	return []byte(p.Profile.Format(p.ID)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *ProfiledID) UnmarshalText(text []byte) error {
+	id, err := p.Profile.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p ProfiledID) MarshalJSON() ([]byte, error) {
+	return FormattedID{ID: p.ID, Format: p.Profile.JSON}.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting what
+// FormattedID does for the profile's JSON format.
+func (p *ProfiledID) UnmarshalJSON(data []byte) error {
+	f := FormattedID{Format: p.Profile.JSON}
+	if err := f.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	p.ID = f.ID
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (p ProfiledID) Value() (driver.Value, error) {
+	return p.Profile.Value(p.ID)
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *ProfiledID) Scan(src any) error {
+	id, err := p.Profile.Scan(src)
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+var (
+	// profilesMu guards profiles.
+	profilesMu sync.RWMutex
+
+	// profiles holds every profile by name, built-in and registered.
+	profiles = map[string]Profile{}
+)
+
+func init() {
+	for _, p := range []Profile{ProfileWeb, ProfileAnalytics, ProfileLegacyInt} {
+		profiles[p.Name] = p
+	}
+}
+
+// RegisterProfile adds p to the registry, so subsystems can select it by
+// name from configuration with LookupProfile. Typically called from an init
+// function. Fails if the name is empty or already taken, including by the
+// built-in "web", "analytics" and "legacy-int" profiles, or if the JSON format
+// or SQL mode is unknown.
+func RegisterProfile(p Profile) error {
+	if p.Name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if err := p.validate(); err != nil {
+		return err
+	}
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	if _, ok := profiles[p.Name]; ok {
+		return fmt.Errorf("profile %q already registered", p.Name)
+	}
+	profiles[p.Name] = p
+	return nil
+}
+
+// LookupProfile returns the built-in or registered profile with the given name.
+func LookupProfile(name string) (Profile, bool) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// ProfileNames returns the names of all built-in and registered profiles, sorted.
+func ProfileNames() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package nano64
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Rebase converts id from one layout to another, so systems that change their
+// epoch, node bits or bit allocation can migrate stored IDs deterministically.
+// The timestamp keeps its Unix millisecond value and is re-expressed against
+// to.Epoch. When both layouts reserve node bits, the node ID and sequence are
+// carried over separately; otherwise the whole random field is. Any value that
+// does not fit the target layout fails with an error wrapping ErrPrecisionLoss
+// rather than being truncated. Unlike Layout.Validate, Rebase accepts any
+// split of 64 bits, so IDs can also be moved to and from layouts this package
+// does not generate. Nil rebases to Nil.
+func Rebase(id Nano64, from, to Layout) (Nano64, error) {
+	if err := from.checkSplit(); err != nil {
+		return Nil, fmt.Errorf("invalid source layout: %w", err)
+	}
+	if err := to.checkSplit(); err != nil {
+		return Nil, fmt.Errorf("invalid target layout: %w", err)
+	}
+	if id.IsNil() {
+		return Nil, nil
+	}
+
+	ts := int64(id.value >> from.RandomBits)
+	random := id.value & lowBits(from.RandomBits)
+	unix := ts + from.Epoch
+	if unix < to.Epoch {
+		return Nil, fmt.Errorf("%w: timestamp %d is before the target epoch %d", ErrPrecisionLoss, unix, to.Epoch)
+	}
+	newTs := uint64(unix - to.Epoch)
+	if bits.Len64(newTs) > to.TimestampBits {
+		return Nil, fmt.Errorf("%w: timestamp %d does not fit in %d bits after the target epoch %d",
+			ErrPrecisionLoss, unix, to.TimestampBits, to.Epoch)
+	}
+
+	var newRandom uint64
+	if from.NodeBits > 0 && to.NodeBits > 0 {
+		fromSeq, toSeq := from.RandomBits-from.NodeBits, to.RandomBits-to.NodeBits
+		node, seq := random>>fromSeq, random&lowBits(fromSeq)
+		if bits.Len64(node) > to.NodeBits {
+			return Nil, fmt.Errorf("%w: node ID %d does not fit in %d bits", ErrPrecisionLoss, node, to.NodeBits)
+		}
+		if bits.Len64(seq) > toSeq {
+			return Nil, fmt.Errorf("%w: sequence %d does not fit in %d bits", ErrPrecisionLoss, seq, toSeq)
+		}
+		newRandom = node<<toSeq | seq
+	} else {
+		if bits.Len64(random) > to.RandomBits {
+			return Nil, fmt.Errorf("%w: random field %d does not fit in %d bits", ErrPrecisionLoss, random, to.RandomBits)
+		}
+		newRandom = random
+	}
+	return Nano64{value: newTs<<to.RandomBits | newRandom}, nil
+}
+
+// checkSplit checks that the layout divides 64 bits between a timestamp and
+// a random field, with node bits inside the random field.
+func (l Layout) checkSplit() error {
+	if l.TimestampBits <= 0 || l.RandomBits <= 0 || l.TimestampBits+l.RandomBits != 64 {
+		return fmt.Errorf("timestamp and random bits must be positive and sum to 64, got %d + %d",
+			l.TimestampBits, l.RandomBits)
+	}
+	if l.NodeBits < 0 || l.NodeBits >= l.RandomBits {
+		return fmt.Errorf("node bits must be 0-%d, got %d", l.RandomBits-1, l.NodeBits)
+	}
+	if l.Epoch < 0 {
+		return fmt.Errorf("epoch cannot be negative: %d", l.Epoch)
+	}
+	return nil
+}
+
+// lowBits returns a mask of the low n bits (n < 64).
+func lowBits(n int) uint64 {
+	return uint64(1)<<n - 1
+}
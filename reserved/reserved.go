@@ -0,0 +1,121 @@
+// Package reserved loads lists of reserved IDs, such as migration markers,
+// seed data and other sentinel or system IDs, so products can keep generated
+// IDs from colliding with them.
+//
+// The format is plain text, one ID per line, in any form nano64.Parse
+// accepts, optionally followed by whitespace and a name. Blank lines and
+// lines starting with '#' are ignored:
+//
+//	# sentinel IDs
+//	00000000000-00001  system-user
+//	1941F297C00-00000  migration-2025-01
+//
+// Lists are small and read once, so they embed well with go:embed:
+//
+//	//go:embed reserved.txt
+//	var files embed.FS
+//
+//	list, err := reserved.Load(files, "reserved.txt")
+//	gen := nano64.NewGenerator(nano64.WithReserved(list.IsReserved))
+package reserved
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"go.codycody31.dev/nano64"
+)
+
+// ErrReserved is returned by List.Check for reserved IDs.
+var ErrReserved = errors.New("reserved ID")
+
+// List is a set of reserved IDs with their names. It is immutable after
+// loading and safe for concurrent use.
+type List struct {
+	names map[nano64.Nano64]string
+}
+
+// Load reads the list at path in fsys.
+func Load(fsys fs.FS, path string) (*List, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reserved list: %w", err)
+	}
+	defer f.Close()
+	list, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Parse reads a list from r. Duplicate IDs are an error, so two entries
+// cannot silently claim the same ID under different names.
+func Parse(r io.Reader) (*List, error) {
+	l := &List{names: make(map[nano64.Nano64]string)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(text)
+		id, err := nano64.Parse(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if prev, ok := l.names[id]; ok {
+			return nil, fmt.Errorf("line %d: %s already reserved as %q", line, id.ToHex(), prev)
+		}
+		l.names[id] = strings.Join(fields[1:], " ")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reserved list: %w", err)
+	}
+	return l, nil
+}
+
+// IsReserved reports whether id is on the list. Pass l.IsReserved to
+// nano64.WithReserved to have a generator skip reserved IDs.
+func (l *List) IsReserved(id nano64.Nano64) bool {
+	_, ok := l.names[id]
+	return ok
+}
+
+// Name returns the name given to a reserved ID, which may be empty.
+func (l *List) Name(id nano64.Nano64) (string, bool) {
+	name, ok := l.names[id]
+	return name, ok
+}
+
+// Check returns an error wrapping ErrReserved if id is on the list, for
+// flagging IDs that arrive from elsewhere, such as imports.
+func (l *List) Check(id nano64.Nano64) error {
+	name, ok := l.names[id]
+	if !ok {
+		return nil
+	}
+	if name == "" {
+		return fmt.Errorf("%w: %s", ErrReserved, id.ToHex())
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrReserved, id.ToHex(), name)
+}
+
+// Len returns the number of reserved IDs.
+func (l *List) Len() int {
+	return len(l.names)
+}
+
+// IDs returns the reserved IDs in ascending order.
+func (l *List) IDs() []nano64.Nano64 {
+	ids := make([]nano64.Nano64, 0, len(l.names))
+	for id := range l.names {
+		ids = append(ids, id)
+	}
+	nano64.SortIDs(ids)
+	return ids
+}
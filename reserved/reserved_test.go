@@ -0,0 +1,87 @@
+package reserved
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.codycody31.dev/nano64"
+)
+
+const list = `# sentinel IDs
+00000000000-00001  system-user
+
+1941F297C00-00000	migration 2025-01
+0x1941F297C0000002
+`
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{"reserved.txt": {Data: []byte(list)}}
+	l, err := Load(fsys, "reserved.txt")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if l.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", l.Len())
+	}
+
+	system, _ := nano64.Parse("00000000000-00001")
+	migration, _ := nano64.Parse("1941F297C00-00000")
+	unnamed, _ := nano64.Parse("1941F297C00-00002")
+	if !l.IsReserved(system) || !l.IsReserved(migration) || !l.IsReserved(unnamed) {
+		t.Error("IsReserved() = false for a listed ID")
+	}
+	if l.IsReserved(nano64.FromUint64(2)) {
+		t.Error("IsReserved() = true for an unlisted ID")
+	}
+	if name, ok := l.Name(migration); !ok || name != "migration 2025-01" {
+		t.Errorf("Name(migration) = %q, %v", name, ok)
+	}
+	if got := l.IDs(); len(got) != 3 || got[0] != system || got[2] != unnamed {
+		t.Errorf("IDs() = %v, want ascending order", got)
+	}
+
+	if err := l.Check(migration); !errors.Is(err, ErrReserved) || !strings.Contains(err.Error(), "migration 2025-01") {
+		t.Errorf("Check(migration) error = %v, want ErrReserved with the name", err)
+	}
+	if err := l.Check(nano64.FromUint64(2)); err != nil {
+		t.Errorf("Check(unlisted) error = %v", err)
+	}
+
+	if _, err := Load(fsys, "missing.txt"); err == nil {
+		t.Error("Load() of a missing file should fail")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{
+		"not-an-id\n",
+		"00000000000-00001 a\n00000000000-00001 b\n",
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Parse(%q) error = nil, want error", input)
+		}
+	}
+	if _, err := Parse(strings.NewReader("# a\nbad\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Parse() error = %v, want the line number", err)
+	}
+}
+
+func TestGeneratorSkipsReserved(t *testing.T) {
+	l, err := Parse(strings.NewReader("1941F297C00-00005 seed\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := int64(0x1941F297C00)
+	draws := []uint32{5, 6}
+	gen := nano64.NewGenerator(nano64.WithReserved(l.IsReserved), nano64.WithRNG(func(bits int) (uint32, error) {
+		v := draws[0]
+		draws = draws[1:]
+		return v, nil
+	}))
+	id, err := gen.Generate(ts)
+	if err != nil || id.GetRandom() != 6 {
+		t.Errorf("Generate() = %s, %v; want the reserved draw skipped", id.ToHex(), err)
+	}
+}
@@ -0,0 +1,70 @@
+package nano64
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateAfterRestore checks that the clock has not fallen behind the
+// newest ID found after restoring a backup. Call it at startup with the
+// highest ID in the restored data: if that ID's timestamp is after now, the
+// machine's clock lags the data and fresh IDs could sort before, or collide
+// with, existing ones. The error wraps ErrClockRegression. A nil
+// lastKnownID always passes. The check reads the raw timestamp, so pass
+// IDs from a custom-epoch generator through its ToDate yourself.
+func ValidateAfterRestore(lastKnownID Nano64, now time.Time) error {
+	if lastKnownID.IsNil() {
+		return nil
+	}
+	last := lastKnownID.ToDate()
+	if !last.After(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: clock %s is %v behind restored ID %s",
+		ErrClockRegression, now.UTC().Format(time.RFC3339Nano), last.Sub(now), lastKnownID.ToHex())
+}
+
+// WithFloor makes the generator refuse to produce IDs at or below floor,
+// typically the recovered high-water mark of a restored database. Monotonic
+// generation starts directly above the floor, borrowing future milliseconds
+// while the clock lags it as it would after any clock step back (or failing
+// with ErrClockRegression under WithStrictMonotonic). Generate returns an
+// error wrapping ErrClockRegression for any ID that would not exceed the floor.
+// A nil floor is ignored.
+func WithFloor(floor Nano64) GeneratorOption {
+	return func(g *Generator) {
+		g.floor = floor.value
+	}
+}
+
+// seedFloor positions the monotonic state at the floor so the next
+// monotonic ID exceeds it.
+func (g *Generator) seedFloor() {
+	if g.floor == 0 {
+		return
+	}
+	g.state.last.Store(g.statePosition(g.floor))
+}
+
+// statePosition packs the ID value v into the generator's monotonic state
+// layout, so the next monotonic ID exceeds v. An ID from another node may
+// carry higher node bits than ours, so for those the position skips to the
+// end of v's millisecond rather than reusing its sequence.
+func (g *Generator) statePosition(v uint64) uint64 {
+	seqBits := g.sequenceBits()
+	seqMask := uint64(1)<<seqBits - 1
+	seq := v & seqMask
+	if g.nodeBits > 0 && uint32(v&randomMask>>seqBits) != g.nodeID {
+		seq = seqMask
+	}
+	return v>>timestampShift<<seqBits | seq
+}
+
+// aboveFloor rejects id if it does not exceed the generator's floor.
+func (g *Generator) aboveFloor(id Nano64) (Nano64, error) {
+	if g.floor != 0 && id.value <= g.floor {
+		return Nano64{}, fmt.Errorf("%w: ID %s is not above the floor %s",
+			ErrClockRegression, id.ToHex(), Nano64{value: g.floor}.ToHex())
+	}
+	return id, nil
+}
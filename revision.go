@@ -0,0 +1,159 @@
+package nano64
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// RevisionIDLength is the binary size of a RevisionID: 8-byte ID + 2-byte revision.
+	RevisionIDLength = 10
+
+	// RevisionIDTextLength is the text size of a RevisionID: 17-char ID, ":r" and 5 decimal digits.
+	RevisionIDTextLength = 17 + 2 + 5
+)
+
+// RevisionID identifies one revision of an entity. Both encodings are fixed-width
+// and sort by entity first and revision second, so "same entity, newer revision"
+// is a simple range scan in document stores.
+type RevisionID struct {
+	ID       Nano64
+	Revision uint16
+}
+
+// Next returns the following revision of the same entity.
+// Returns an error once the 16-bit revision counter is exhausted.
+func (r RevisionID) Next() (RevisionID, error) {
+	if r.Revision == math.MaxUint16 {
+		return RevisionID{}, fmt.Errorf("revision overflow for %s", r.ID.ToHex())
+	}
+	return RevisionID{ID: r.ID, Revision: r.Revision + 1}, nil
+}
+
+// SameEntity reports whether both revisions belong to the same entity.
+func (r RevisionID) SameEntity(other RevisionID) bool {
+	return r.ID.Equals(other.ID)
+}
+
+// Compare orders by entity ID, then by revision. Returns -1, 0 or 1.
+func (r RevisionID) Compare(other RevisionID) int {
+	if c := Compare(r.ID, other.ID); c != 0 {
+		return c
+	}
+	if r.Revision < other.Revision {
+		return -1
+	} else if r.Revision > other.Revision {
+		return 1
+	}
+	return 0
+}
+
+// Equals checks that entity and revision both match.
+func (r RevisionID) Equals(other RevisionID) bool {
+	return r.Compare(other) == 0
+}
+
+// ToBytes returns the 10-byte big-endian encoding.
+func (r RevisionID) ToBytes() []byte {
+	b := make([]byte, RevisionIDLength)
+	binary.BigEndian.PutUint64(b[0:8], r.ID.value)
+	binary.BigEndian.PutUint16(b[8:], r.Revision)
+	return b
+}
+
+// FromRevisionBytes decodes a 10-byte big-endian RevisionID.
+func FromRevisionBytes(bytes []byte) (RevisionID, error) {
+	if len(bytes) != RevisionIDLength {
+		return RevisionID{}, fmt.Errorf("revision ID must be %d bytes, got %d", RevisionIDLength, len(bytes))
+	}
+	return RevisionID{
+		ID:       Nano64{value: binary.BigEndian.Uint64(bytes[0:8])},
+		Revision: binary.BigEndian.Uint16(bytes[8:]),
+	}, nil
+}
+
+// ToText returns the fixed-width 24-char text form, e.g. "199C01B6659-5861C:r00003".
+func (r RevisionID) ToText() string {
+	return fmt.Sprintf("%s:r%05d", r.ID.ToHex(), r.Revision)
+}
+
+// ParseRevisionID parses the text form produced by ToText.
+func ParseRevisionID(text string) (RevisionID, error) {
+	if len(text) != RevisionIDTextLength || text[17:19] != ":r" {
+		return RevisionID{}, fmt.Errorf("revision ID must be XXXXXXXXXXX-XXXXX:rNNNNN, got %q", text)
+	}
+	id, err := FromHex(text[:17])
+	if err != nil {
+		return RevisionID{}, fmt.Errorf("invalid revision ID: %w", err)
+	}
+	rev, err := strconv.ParseUint(text[19:], 10, 16)
+	if err != nil {
+		return RevisionID{}, fmt.Errorf("invalid revision: %w", err)
+	}
+	return RevisionID{ID: id, Revision: uint16(rev)}, nil
+}
+
+// String returns the text form.
+func (r RevisionID) String() string {
+	return r.ToText()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r RevisionID) MarshalText() ([]byte, error) {
+	return []byte(r.ToText()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (r *RevisionID) UnmarshalText(text []byte) error {
+	parsed, err := ParseRevisionID(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r RevisionID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToText())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *RevisionID) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("failed to unmarshal RevisionID: %w", err)
+	}
+	return r.UnmarshalText([]byte(text))
+}
+
+// Value implements the driver.Valuer interface, storing the 10-byte binary form.
+func (r RevisionID) Value() (driver.Value, error) {
+	return r.ToBytes(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Accepts the 10-byte binary form or the text form.
+func (r *RevisionID) Scan(value interface{}) error {
+	var (
+		parsed RevisionID
+		err    error
+	)
+	switch v := value.(type) {
+	case []byte:
+		parsed, err = FromRevisionBytes(v)
+	case string:
+		parsed, err = ParseRevisionID(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into RevisionID", value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan RevisionID: %w", err)
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,67 @@
+package nano64
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// Concurrency guarantees:
+//
+//   - DefaultRNG, FastRNG, DefaultClock and all package-level functions are
+//     safe for concurrent use.
+//   - A custom RNG or Clock passed to Generate, GenerateMonotonic or
+//     NewEncryptedIDConfig is called from whichever goroutine generates the ID,
+//     so it must be safe for concurrent use too. Closures over *math/rand.Rand
+//     are NOT; wrap them with RaceSafeRNG.
+//   - A Generator is safe for concurrent use, and WithRNG applies RaceSafeRNG
+//     itself (WithFastRNG skips the lock, as FastRNG is already safe); a
+//     custom Clock passed to WithClock must still be safe.
+
+// RaceSafeRNG wraps rng so that calls are serialized by a mutex, making RNGs
+// built on non-thread-safe sources (such as *math/rand.Rand) safe to share
+// between goroutines. Wrapping an already safe RNG is harmless but adds a lock.
+func RaceSafeRNG(rng RNG) RNG {
+	if rng == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(bits int) (uint32, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return rng(bits)
+	}
+}
+
+// chacha8Pool holds ChaCha8 generators for FastRNG, one per processor in the
+// common case, so concurrent callers neither share state nor take a lock.
+var chacha8Pool = sync.Pool{
+	New: func() any {
+		var seed [32]byte
+		for i := 0; i < len(seed); i += 8 {
+			binary.LittleEndian.PutUint64(seed[i:], rand.Uint64())
+		}
+		return rand.NewChaCha8(seed)
+	},
+}
+
+// FastRNG is a fast RNG built on math/rand/v2's ChaCha8 generator, for IDs
+// that only need to be unique, not unpredictable: internal row keys, trace
+// and job IDs. It is safe for concurrent use and never makes a system call.
+//
+// Tradeoff versus DefaultRNG: ChaCha8 output is statistically strong, but its
+// seeds come from the runtime's random source rather than being drawn from
+// crypto/rand for every ID, and the generator state lives in ordinary memory.
+// Do not use it for IDs that act as capabilities or must not be guessable
+// (share links, tokens, anything in a URL you do not authenticate); use
+// DefaultRNG or EncryptedIDConfig for those.
+func FastRNG(bits int) (uint32, error) {
+	if bits <= 0 || bits > 32 {
+		return 0, fmt.Errorf("bits must be 1-32, got %d", bits)
+	}
+	c := chacha8Pool.Get().(*rand.ChaCha8)
+	val := uint32(c.Uint64() >> 32)
+	chacha8Pool.Put(c)
+	return maskBits(val, bits), nil
+}
@@ -0,0 +1,157 @@
+package nano64
+
+import "fmt"
+
+// Wide-column stores such as HBase and Bigtable often build row keys from
+// separately placed fields. The helpers in this file produce the ID's parts
+// as big-endian byte groups so they can be placed without manual masking.
+
+// SplitBytes returns the timestamp as 6 big-endian bytes (top 4 bits zero) and
+// the random field as 3 big-endian bytes (top 4 bits zero). Each group sorts
+// bytewise like the value it holds.
+func (n Nano64) SplitBytes() (ts [6]byte, rand [3]byte) {
+	t := uint64(n.rawTimestamp())
+	for i := 5; i >= 0; i-- {
+		ts[i] = byte(t)
+		t >>= 8
+	}
+	r := n.GetRandom()
+	rand[0] = byte(r >> 16)
+	rand[1] = byte(r >> 8)
+	rand[2] = byte(r)
+	return ts, rand
+}
+
+// FromSplitBytes joins the groups produced by SplitBytes.
+// Returns an error if either group sets bits outside its field.
+func FromSplitBytes(ts [6]byte, rand [3]byte) (Nano64, error) {
+	var t uint64
+	for _, b := range ts {
+		t = t<<8 | uint64(b)
+	}
+	if t > maxTimestamp {
+		return Nano64{}, fmt.Errorf("timestamp exceeds 44-bit range: %d > %d", t, maxTimestamp)
+	}
+	r := uint64(rand[0])<<16 | uint64(rand[1])<<8 | uint64(rand[2])
+	if r > randomMask {
+		return Nano64{}, fmt.Errorf("random exceeds 20-bit range: %d", r)
+	}
+	return Nano64{value: t<<timestampShift | r}, nil
+}
+
+// Descending returns the bitwise complement of the ID. Complemented keys sort
+// newest-first, letting ascending-only KV scans list the latest entities first.
+// Apply FromDescending to recover the original ID.
+func (n Nano64) Descending() Nano64 {
+	return Nano64{value: ^n.value}
+}
+
+// FromDescending recovers the ID from its complemented key.
+func FromDescending(d Nano64) Nano64 {
+	return Nano64{value: ^d.value}
+}
+
+// ToDescendingHex returns the canonical hex form of the complemented key.
+func (n Nano64) ToDescendingHex() string {
+	return n.Descending().ToHex()
+}
+
+// ToDescendingBytes returns the 8-byte big-endian form of the complemented key.
+func (n Nano64) ToDescendingBytes() []byte {
+	return n.Descending().ToBytes()
+}
+
+// FromDescendingHex parses a key produced by ToDescendingHex and returns the original ID.
+func FromDescendingHex(s string) (Nano64, error) {
+	d, err := FromHex(s)
+	if err != nil {
+		return Nano64{}, err
+	}
+	return FromDescending(d), nil
+}
+
+// FromDescendingBytes parses a key produced by ToDescendingBytes and returns the original ID.
+func FromDescendingBytes(bytes []byte) (Nano64, error) {
+	d, err := FromBytes(bytes)
+	if err != nil {
+		return Nano64{}, err
+	}
+	return FromDescending(d), nil
+}
+
+// InterleaveWith returns a Z-order (Morton) key mixing the ID's timestamp with
+// the low bits of secondary (e.g. a geocell or tenant). Bits alternate from the
+// most significant end, timestamp first, until the shorter field runs out; the
+// rest of the longer field follows, then the 20-bit random field keeps keys
+// unique. The key is big-endian, zero-padded to whole bytes, and bits must be 1-32.
+// Range scans over the key stay local in both time and the secondary dimension.
+func (n Nano64) InterleaveWith(secondary uint32, bits int) []byte {
+	if bits < 1 || bits > 32 {
+		panic(fmt.Sprintf("nano64: interleave bits must be 1-32, got %d", bits))
+	}
+	w := bitWriter{buf: make([]byte, (TimestampBits+bits+RandomBits+7)/8)}
+	ts := uint64(n.rawTimestamp())
+	sec := uint64(secondary)
+	for i, j := TimestampBits-1, bits-1; i >= 0 || j >= 0; i, j = i-1, j-1 {
+		if i >= 0 {
+			w.write(ts >> i & 1)
+		}
+		if j >= 0 {
+			w.write(sec >> j & 1)
+		}
+	}
+	r := uint64(n.GetRandom())
+	for i := RandomBits - 1; i >= 0; i-- {
+		w.write(r >> i & 1)
+	}
+	return w.buf
+}
+
+// FromInterleaved decodes a key produced by InterleaveWith with the same bits.
+func FromInterleaved(key []byte, bits int) (Nano64, uint32, error) {
+	if bits < 1 || bits > 32 {
+		return Nano64{}, 0, fmt.Errorf("interleave bits must be 1-32, got %d", bits)
+	}
+	if want := (TimestampBits + bits + RandomBits + 7) / 8; len(key) != want {
+		return Nano64{}, 0, fmt.Errorf("interleaved key must be %d bytes, got %d", want, len(key))
+	}
+	r := bitReader{buf: key}
+	var ts, sec, random uint64
+	for i, j := TimestampBits-1, bits-1; i >= 0 || j >= 0; i, j = i-1, j-1 {
+		if i >= 0 {
+			ts = ts<<1 | r.read()
+		}
+		if j >= 0 {
+			sec = sec<<1 | r.read()
+		}
+	}
+	for i := 0; i < RandomBits; i++ {
+		random = random<<1 | r.read()
+	}
+	return Nano64{value: ts<<timestampShift | random}, uint32(sec), nil
+}
+
+// bitWriter appends bits MSB-first into a fixed buffer.
+type bitWriter struct {
+	buf []byte
+	pos int
+}
+
+func (w *bitWriter) write(bit uint64) {
+	if bit != 0 {
+		w.buf[w.pos/8] |= 0x80 >> (w.pos % 8)
+	}
+	w.pos++
+}
+
+// bitReader reads bits MSB-first from a buffer.
+type bitReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *bitReader) read() uint64 {
+	bit := uint64(r.buf[r.pos/8]>>(7-r.pos%8)) & 1
+	r.pos++
+	return bit
+}
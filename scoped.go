@@ -0,0 +1,141 @@
+package nano64
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// ScopedIDLength is the binary size of a ScopedID: 4-byte tenant + 8-byte ID.
+	ScopedIDLength = 12
+
+	// ScopedIDTextLength is the text size of a ScopedID: 8 hex tenant chars, ':' and a 17-char ID.
+	ScopedIDTextLength = 8 + 1 + 17
+)
+
+// ScopedID is a composite identifier for multi-tenant systems.
+// Both the binary and text encodings are fixed-width and big-endian, so they
+// sort by tenant first and then by ID time within a tenant.
+type ScopedID struct {
+	Tenant uint32
+	ID     Nano64
+}
+
+// Compare orders by tenant, then by ID. Returns -1, 0 or 1.
+func (s ScopedID) Compare(other ScopedID) int {
+	if s.Tenant < other.Tenant {
+		return -1
+	} else if s.Tenant > other.Tenant {
+		return 1
+	}
+	return Compare(s.ID, other.ID)
+}
+
+// Equals checks that tenant and ID both match.
+func (s ScopedID) Equals(other ScopedID) bool {
+	return s.Compare(other) == 0
+}
+
+// ToBytes returns the 12-byte big-endian encoding.
+func (s ScopedID) ToBytes() []byte {
+	b := make([]byte, ScopedIDLength)
+	binary.BigEndian.PutUint32(b[0:4], s.Tenant)
+	binary.BigEndian.PutUint64(b[4:], s.ID.value)
+	return b
+}
+
+// FromScopedBytes decodes a 12-byte big-endian ScopedID.
+func FromScopedBytes(bytes []byte) (ScopedID, error) {
+	if len(bytes) != ScopedIDLength {
+		return ScopedID{}, fmt.Errorf("scoped ID must be %d bytes, got %d", ScopedIDLength, len(bytes))
+	}
+	return ScopedID{
+		Tenant: binary.BigEndian.Uint32(bytes[0:4]),
+		ID:     Nano64{value: binary.BigEndian.Uint64(bytes[4:])},
+	}, nil
+}
+
+// ToText returns the fixed-width text form, e.g. "0000002A:199C01B6659-5861C".
+func (s ScopedID) ToText() string {
+	return fmt.Sprintf("%08X:%s", s.Tenant, s.ID.ToHex())
+}
+
+// ParseScopedID parses the text form produced by ToText.
+func ParseScopedID(text string) (ScopedID, error) {
+	if len(text) != ScopedIDTextLength || text[8] != ':' {
+		return ScopedID{}, fmt.Errorf("scoped ID must be TTTTTTTT:XXXXXXXXXXX-XXXXX, got %q", text)
+	}
+	tenant, err := strconv.ParseUint(text[:8], 16, 32)
+	if err != nil {
+		return ScopedID{}, fmt.Errorf("invalid tenant: %w", err)
+	}
+	id, err := FromHex(text[9:])
+	if err != nil {
+		return ScopedID{}, fmt.Errorf("invalid scoped ID: %w", err)
+	}
+	return ScopedID{Tenant: uint32(tenant), ID: id}, nil
+}
+
+// String returns the text form.
+func (s ScopedID) String() string {
+	return s.ToText()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s ScopedID) MarshalText() ([]byte, error) {
+	return []byte(s.ToText()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *ScopedID) UnmarshalText(text []byte) error {
+	parsed, err := ParseScopedID(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s ScopedID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToText())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *ScopedID) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("failed to unmarshal ScopedID: %w", err)
+	}
+	return s.UnmarshalText([]byte(text))
+}
+
+// Value implements the driver.Valuer interface, storing the 12-byte binary form.
+func (s ScopedID) Value() (driver.Value, error) {
+	return s.ToBytes(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Accepts the 12-byte binary form or the text form.
+func (s *ScopedID) Scan(value interface{}) error {
+	var (
+		parsed ScopedID
+		err    error
+	)
+	switch v := value.(type) {
+	case []byte:
+		parsed, err = FromScopedBytes(v)
+	case string:
+		parsed, err = ParseScopedID(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into ScopedID", value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan ScopedID: %w", err)
+	}
+	*s = parsed
+	return nil
+}
@@ -0,0 +1,23 @@
+package nano64
+
+import "fmt"
+
+// FromUnixSeconds generates an ID for a second-precision timestamp, placing it
+// at the first millisecond of that second. Use it instead of multiplying by
+// 1000 by hand: it rejects negative and out-of-range seconds rather than
+// overflowing.
+func FromUnixSeconds(sec int64, rng RNG) (Nano64, error) {
+	if sec < 0 {
+		return Nano64{}, fmt.Errorf("seconds cannot be negative: %d", sec)
+	}
+	if limit := (DefaultEpoch() + maxTimestamp) / 1000; sec > limit {
+		return Nano64{}, fmt.Errorf("seconds exceed 44-bit millisecond range: %d > %d", sec, limit)
+	}
+	return Generate(sec*1000, rng)
+}
+
+// GetTimestampSeconds returns the embedded timestamp in whole Unix seconds,
+// rounded down, so it round-trips through FromUnixSeconds.
+func (n Nano64) GetTimestampSeconds() int64 {
+	return n.GetTimestamp() / 1000
+}
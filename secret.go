@@ -0,0 +1,24 @@
+package nano64
+
+import "crypto/subtle"
+
+// EntropyBits returns the number of unpredictable bits in an ID generated by
+// Generate: the 20-bit random field. The timestamp is guessable to within a
+// few seconds, and monotonic IDs after the first in a millisecond are
+// predictable from their neighbours. An attacker therefore needs about 2^20
+// guesses, far too few for an ID to serve alone as an access token. Calling
+// EntropyBits where an ID guards access makes that limit visible; prefer
+// EncryptedNano64 or RandomToken for real secrets.
+func EntropyBits() int {
+	return RandomBits
+}
+
+// ConstantTimeEquals reports whether a and b are equal in time that does not
+// depend on where they differ. Use it when an ID acts as a secret handle and
+// is compared against attacker-supplied input, especially after converting
+// from strings, where an early-exit comparison leaks how many leading
+// characters matched. For ordinary lookups == and Equals are fine.
+func ConstantTimeEquals(a, b Nano64) bool {
+	x := a.value ^ b.value
+	return subtle.ConstantTimeEq(int32(uint32(x)|uint32(x>>32)), 0) == 1
+}
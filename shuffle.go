@@ -0,0 +1,40 @@
+package nano64
+
+import (
+	"hash/fnv"
+	"slices"
+)
+
+// ShuffleStable reorders ids in place into a pseudo-random order derived from
+// the IDs themselves: ascending Hash(seed), ties broken by ID. Feeds can use it
+// to spread out items created close together without storing a sort key.
+//
+// The relative order of any two IDs depends only on those IDs and seed, not
+// on the input order or the rest of the slice. The same set always comes out
+// the same way, and adding or removing items never reorders the others, so
+// pages stay consistent as the feed grows.
+func ShuffleStable(ids []Nano64, seed uint64) {
+	slices.SortFunc(ids, func(a, b Nano64) int {
+		ha, hb := a.Hash(seed), b.Hash(seed)
+		switch {
+		case ha < hb:
+			return -1
+		case ha > hb:
+			return 1
+		case a.value < b.value:
+			return -1
+		case a.value > b.value:
+			return 1
+		}
+		return 0
+	})
+}
+
+// OrderByHash is ShuffleStable with the seed derived from salt (64-bit
+// FNV-1a), for orders keyed by a string such as a viewer or feed name. Each
+// salt gives its own stable order.
+func OrderByHash(ids []Nano64, salt string) {
+	h := fnv.New64a()
+	h.Write([]byte(salt))
+	ShuffleStable(ids, h.Sum64())
+}
@@ -0,0 +1,210 @@
+// Package sim simulates multi-node Nano64 generation to check whether the
+// 20-bit random field is enough for a given topology.
+//
+// Each virtual node generates IDs at its own Poisson rate on a shared virtual
+// timeline, with its own clock skew. Every ID then travels to a single
+// consumer with a random network delay. Run reports how many IDs collided and
+// how often the consumer saw an ID older than one it had already received.
+// No wall-clock time passes; a simulated hour finishes in seconds.
+package sim
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"go.codycody31.dev/nano64"
+	"go.codycody31.dev/nano64/internal/randdist"
+)
+
+// Node describes one virtual generator.
+type Node struct {
+	// Rate is the mean number of IDs generated per second.
+	Rate float64
+
+	// Skew offsets the node's clock from true time (positive runs ahead).
+	Skew time.Duration
+
+	// Monotonic makes the node behave like GenerateMonotonic with its own
+	// state; otherwise it behaves like Generate.
+	Monotonic bool
+}
+
+// Config describes a simulation.
+type Config struct {
+	// Nodes are the generators taking part.
+	Nodes []Node
+
+	// Duration is the length of simulated time.
+	Duration time.Duration
+
+	// Reorder is the maximum network delay; each ID is delayed uniformly in
+	// [0, Reorder] before reaching the consumer.
+	Reorder time.Duration
+
+	// Start is the Unix millisecond the simulation begins at. Zero means 2025-01-01.
+	Start int64
+
+	// Seed makes runs reproducible.
+	Seed uint64
+}
+
+// Result summarizes a simulation.
+type Result struct {
+	// Generated is the total number of IDs produced.
+	Generated int
+
+	// Collisions counts IDs equal to an ID generated earlier by any node.
+	Collisions int
+
+	// OrderingViolations counts IDs that reached the consumer after a larger ID.
+	OrderingViolations int
+
+	// MaxInversion is the largest timestamp gap between an out-of-order ID and
+	// the largest ID received before it.
+	MaxInversion time.Duration
+}
+
+// CollisionRate returns Collisions / Generated.
+func (r Result) CollisionRate() float64 {
+	if r.Generated == 0 {
+		return 0
+	}
+	return float64(r.Collisions) / float64(r.Generated)
+}
+
+// ViolationRate returns OrderingViolations / Generated.
+func (r Result) ViolationRate() float64 {
+	if r.Generated == 0 {
+		return 0
+	}
+	return float64(r.OrderingViolations) / float64(r.Generated)
+}
+
+// String returns a one-line summary.
+func (r Result) String() string {
+	return fmt.Sprintf("generated=%d collisions=%d (%.2e) violations=%d (%.2e) max-inversion=%v",
+		r.Generated, r.Collisions, r.CollisionRate(), r.OrderingViolations, r.ViolationRate(), r.MaxInversion)
+}
+
+// defaultStart is 2025-01-01T00:00:00Z.
+const defaultStart = 1735689600000
+
+type arrival struct {
+	at int64 // consumer arrival time in microseconds of simulated time
+	id nano64.Nano64
+}
+
+// nodeState is the per-node generator state.
+type nodeState struct {
+	Node
+	rng        *rand.Rand
+	lastTs     int64
+	lastRandom uint64
+}
+
+// Run executes the simulation.
+func Run(cfg Config) (Result, error) {
+	if len(cfg.Nodes) == 0 {
+		return Result{}, fmt.Errorf("at least one node is required")
+	}
+	if cfg.Duration <= 0 {
+		return Result{}, fmt.Errorf("duration must be positive")
+	}
+	start := cfg.Start
+	if start == 0 {
+		start = defaultStart
+	}
+
+	net := rand.New(rand.NewPCG(cfg.Seed, 0))
+	nodes := make([]nodeState, len(cfg.Nodes))
+	for i, n := range cfg.Nodes {
+		if n.Rate < 0 {
+			return Result{}, fmt.Errorf("node %d: rate cannot be negative", i)
+		}
+		nodes[i] = nodeState{Node: n, rng: rand.New(rand.NewPCG(cfg.Seed, uint64(i)+1)), lastTs: -1}
+	}
+
+	var res Result
+	seen := make(map[uint64]struct{})
+	var arrivals []arrival
+	reorderMicros := cfg.Reorder.Microseconds()
+
+	millis := cfg.Duration.Milliseconds()
+	for ms := int64(0); ms < millis; ms++ {
+		for i := range nodes {
+			n := &nodes[i]
+			for k := randdist.Poisson(n.rng, n.Rate/1000); k > 0; k-- {
+				id, err := n.next(start + ms + n.Skew.Milliseconds())
+				if err != nil {
+					return Result{}, fmt.Errorf("node %d: %w", i, err)
+				}
+				res.Generated++
+				if _, dup := seen[id.Uint64Value()]; dup {
+					res.Collisions++
+				} else {
+					seen[id.Uint64Value()] = struct{}{}
+				}
+				// Stable sorting keeps generation order among equal arrival times.
+				at := ms * 1000
+				if reorderMicros > 0 {
+					at += net.Int64N(reorderMicros + 1)
+				}
+				arrivals = append(arrivals, arrival{at: at, id: id})
+			}
+		}
+	}
+
+	slices.SortStableFunc(arrivals, func(a, b arrival) int {
+		switch {
+		case a.at < b.at:
+			return -1
+		case a.at > b.at:
+			return 1
+		}
+		return 0
+	})
+	var newest nano64.Nano64
+	for i, a := range arrivals {
+		if i > 0 && nano64.Compare(a.id, newest) < 0 {
+			res.OrderingViolations++
+			gap := time.Duration(newest.GetTimestamp()-a.id.GetTimestamp()) * time.Millisecond
+			res.MaxInversion = max(res.MaxInversion, gap)
+			continue
+		}
+		newest = a.id
+	}
+	return res, nil
+}
+
+// next generates the node's next ID at its local clock reading ts.
+func (n *nodeState) next(ts int64) (nano64.Nano64, error) {
+	if ts < 0 {
+		ts = 0
+	}
+	rng := func(bits int) (uint32, error) {
+		return n.rng.Uint32() & (1<<bits - 1), nil
+	}
+	if !n.Monotonic {
+		return nano64.Generate(ts, rng)
+	}
+
+	const randomMask = 1<<nano64.RandomBits - 1
+	var random uint64
+	if ts <= n.lastTs {
+		ts = n.lastTs
+		random = (n.lastRandom + 1) & randomMask
+		if random == 0 {
+			ts++
+		}
+	} else {
+		r, _ := rng(nano64.RandomBits)
+		random = uint64(r)
+	}
+	n.lastTs, n.lastRandom = ts, random
+	if ts > 1<<nano64.TimestampBits-1 {
+		return nano64.Nil, fmt.Errorf("timestamp overflow")
+	}
+	return nano64.New(uint64(ts)<<nano64.RandomBits | random), nil
+}
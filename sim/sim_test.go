@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRun_SingleMonotonicNode(t *testing.T) {
+	res, err := Run(Config{
+		Nodes:    []Node{{Rate: 100_000, Monotonic: true}},
+		Duration: time.Second,
+		Seed:     1,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Generated < 95_000 || res.Generated > 105_000 {
+		t.Errorf("Generated = %d, want about 100000", res.Generated)
+	}
+	if res.Collisions != 0 {
+		t.Errorf("Collisions = %d, want 0 for one monotonic node", res.Collisions)
+	}
+	if res.OrderingViolations != 0 {
+		t.Errorf("OrderingViolations = %d, want 0 without reordering", res.OrderingViolations)
+	}
+}
+
+func TestRun_CollisionsMatchBirthdayEstimate(t *testing.T) {
+	// 145 IDs/ms across 10 random nodes: about 1% of milliseconds see a collision.
+	nodes := make([]Node, 10)
+	for i := range nodes {
+		nodes[i] = Node{Rate: 14_500}
+	}
+	res, err := Run(Config{Nodes: nodes, Duration: 10 * time.Second, Seed: 2})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// Expected about 10 per simulated second.
+	if res.Collisions < 50 || res.Collisions > 150 {
+		t.Errorf("Collisions = %d over 10s, want about 100", res.Collisions)
+	}
+}
+
+func TestRun_SkewAndReorder(t *testing.T) {
+	res, err := Run(Config{
+		Nodes: []Node{
+			{Rate: 5000, Monotonic: true},
+			{Rate: 5000, Monotonic: true, Skew: -50 * time.Millisecond},
+		},
+		Duration: time.Second,
+		Reorder:  5 * time.Millisecond,
+		Seed:     3,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.OrderingViolations == 0 {
+		t.Error("OrderingViolations = 0, want violations from a lagging clock")
+	}
+	if res.MaxInversion < 45*time.Millisecond || res.MaxInversion > 60*time.Millisecond {
+		t.Errorf("MaxInversion = %v, want about the 50ms skew", res.MaxInversion)
+	}
+	if res.ViolationRate() <= 0 || res.String() == "" {
+		t.Error("summary helpers returned empty values")
+	}
+}
+
+func TestRun_Reproducible(t *testing.T) {
+	cfg := Config{Nodes: []Node{{Rate: 50_000}, {Rate: 50_000}}, Duration: time.Second, Reorder: time.Millisecond, Seed: 4}
+	a, _ := Run(cfg)
+	b, _ := Run(cfg)
+	if a != b {
+		t.Errorf("Run() not reproducible: %v vs %v", a, b)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	if _, err := Run(Config{Duration: time.Second}); err == nil {
+		t.Error("Run() without nodes error = nil, want error")
+	}
+	if _, err := Run(Config{Nodes: []Node{{Rate: 1}}}); err == nil {
+		t.Error("Run() without duration error = nil, want error")
+	}
+	if _, err := Run(Config{Nodes: []Node{{Rate: -1}}, Duration: time.Second}); err == nil {
+		t.Error("Run() with negative rate error = nil, want error")
+	}
+}
@@ -0,0 +1,119 @@
+package nano64
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	// SnowflakeNodeBits is the width of the node field in compat IDs (0..255).
+	SnowflakeNodeBits = 8
+
+	// SnowflakeSequenceBits is the width of the per-ms sequence in compat IDs (0..4095).
+	SnowflakeSequenceBits = RandomBits - SnowflakeNodeBits
+
+	// snowflakeSequenceMask is the mask for the 12-bit sequence.
+	snowflakeSequenceMask = (1 << SnowflakeSequenceBits) - 1
+
+	// maxSnowflakeNode is the largest node ID a compat generator accepts.
+	maxSnowflakeNode = (1 << SnowflakeNodeBits) - 1
+)
+
+// CompatSnowflake generates IDs that are valid Nano64 values and also read as
+// approximately-ordered Snowflake IDs (1 sign bit, 41-bit timestamp, 10-bit
+// worker, 12-bit sequence) for legacy consumers during a migration.
+//
+// The 20-bit random field is split into an 8-bit node ID and a 12-bit
+// sequence, so the two layouts line up as follows:
+//
+//	bit 63        sign: Nano64 timestamp bit 43, so 0 until about 2248 (timestamps below 2^43 ms)
+//	bits 62..22   Snowflake timestamp: Nano64 ms >> 2, i.e. 4 ms ticks since the Unix (or default) epoch
+//	bits 21..20   Snowflake worker high bits: Nano64 ms & 3
+//	bits 19..12   Snowflake worker low bits: node ID
+//	bits 11..0    Snowflake sequence
+//
+// What is sacrificed:
+//   - All 20 bits of randomness. Uniqueness relies on unique node IDs, as with
+//     Snowflake, and IDs are guessable.
+//   - Snowflake timestamp precision and epoch. Legacy readers see 4 ms ticks
+//     since 1970 rather than ms since their custom epoch, so decoded times are
+//     wrong but ordering across IDs is preserved.
+//   - Snowflake worker IDs. The top two worker bits carry the ms phase, so only
+//     256 nodes are addressable and the worker a legacy reader decodes is not
+//     stable across IDs from the same node.
+//
+// IDs from one generator are strictly increasing. At most 4096 IDs are issued
+// per ms; further calls borrow the next ms. A clock that steps backwards is
+// clamped to the last issued timestamp. A CompatSnowflake is safe for
+// concurrent use.
+type CompatSnowflake struct {
+	node  uint64
+	clock Clock
+
+	mu     sync.Mutex
+	lastTs int64
+	seq    uint64
+}
+
+// CompatSnowflakeGenerator returns a generator for node IDs 0..255 using the package default clock.
+func CompatSnowflakeGenerator(nodeID int) (*CompatSnowflake, error) {
+	if nodeID < 0 || nodeID > maxSnowflakeNode {
+		return nil, fmt.Errorf("node ID must be between 0 and %d, got %d", maxSnowflakeNode, nodeID)
+	}
+	return &CompatSnowflake{node: uint64(nodeID), clock: now, lastTs: -1}, nil
+}
+
+// NodeID returns the generator's node ID.
+func (g *CompatSnowflake) NodeID() int {
+	return int(g.node)
+}
+
+// Generate returns the next ID at the current time.
+func (g *CompatSnowflake) Generate() (Nano64, error) {
+	return g.GenerateAt(g.clock())
+}
+
+// GenerateAt returns the next ID at timestamp (Unix ms).
+func (g *CompatSnowflake) GenerateAt(timestamp int64) (Nano64, error) {
+	timestamp, err := fromUnix(timestamp)
+	if err != nil {
+		return Nano64{}, err
+	}
+	if timestamp < 0 {
+		return Nano64{}, fmt.Errorf("timestamp cannot be negative: %d", timestamp)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t := max(timestamp, g.lastTs)
+	seq := uint64(0)
+	if t == g.lastTs {
+		seq = (g.seq + 1) & snowflakeSequenceMask
+		if seq == 0 {
+			t++
+		}
+	}
+	if t > maxTimestamp {
+		return Nano64{}, fmt.Errorf("timestamp exceeds 44-bit range: %d > %d", t, maxTimestamp)
+	}
+	g.lastTs, g.seq = t, seq
+
+	random := g.node<<SnowflakeSequenceBits | seq
+	return Nano64{value: uint64(t)<<timestampShift | random}, nil
+}
+
+// SnowflakeNode returns the node ID of an ID produced by a CompatSnowflake.
+func (n Nano64) SnowflakeNode() int {
+	return int(n.GetRandom() >> SnowflakeSequenceBits)
+}
+
+// SnowflakeSequence returns the per-ms sequence of an ID produced by a CompatSnowflake.
+func (n Nano64) SnowflakeSequence() int {
+	return int(n.GetRandom() & snowflakeSequenceMask)
+}
+
+// Snowflake returns the ID as a signed 64-bit Snowflake value for legacy consumers.
+func (n Nano64) Snowflake() int64 {
+	return int64(n.value)
+}
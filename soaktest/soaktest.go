@@ -0,0 +1,258 @@
+// Package soaktest runs a Generator hard for a long time and checks that its
+// monotonic guarantees hold throughout.
+//
+// Run builds the generator under test around a clock that follows a base
+// clock (the real one by default) but is stepped backwards and forwards at
+// random while goroutines call GenerateMonotonicNow. Each goroutine checks that
+// its own IDs strictly increase, and every ID is also checked against the
+// largest ID any goroutine had received before the call began, which is what
+// monotonicity promises across goroutines. Use it in CI, or to validate a
+// custom Clock and RNG combination before relying on it in production.
+package soaktest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// maxRecorded caps how many violations a Report keeps in detail.
+const maxRecorded = 100
+
+// Factory builds the generator under test. It must pass clock to
+// nano64.WithClock so the harness can step it; everything else (RNG, node
+// ID, overflow and drift policies) is up to the caller.
+type Factory func(clock nano64.Clock) *nano64.Generator
+
+// Option configures a run.
+type Option func(*config)
+
+type config struct {
+	base     nano64.Clock
+	interval time.Duration
+	maxStep  int64
+	seed     uint64
+	seeded   bool
+}
+
+// WithBaseClock sets the clock the stepped clock follows (default: nano64.DefaultClock).
+func WithBaseClock(clock nano64.Clock) Option {
+	return func(c *config) { c.base = clock }
+}
+
+// WithSteps injects a clock step every interval, each uniform in
+// [-max, +max]. The accumulated offset stays within ten times max. An
+// interval of zero disables stepping. The default is a step of up to 50ms
+// every 10ms.
+func WithSteps(interval, max time.Duration) Option {
+	return func(c *config) {
+		c.interval = interval
+		c.maxStep = max.Milliseconds()
+	}
+}
+
+// WithSeed makes the sequence of clock steps reproducible.
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+		c.seeded = true
+	}
+}
+
+// Violation is an ID that broke monotonic ordering.
+type Violation struct {
+	// Worker is the goroutine that received Got.
+	Worker int
+
+	// Prev is the ID Got should have exceeded.
+	Prev nano64.Nano64
+
+	// Got is the offending ID.
+	Got nano64.Nano64
+
+	// Global is true when Prev came from another goroutine.
+	Global bool
+}
+
+// String describes the violation.
+func (v Violation) String() string {
+	scope := "own"
+	if v.Global {
+		scope = "global"
+	}
+	return fmt.Sprintf("worker %d: %s <= %s previous %s", v.Worker, v.Got.ToHex(), scope, v.Prev.ToHex())
+}
+
+// Report summarizes a run.
+type Report struct {
+	// Generated is the number of IDs returned without error.
+	Generated int64
+
+	// Errors counts generation errors. Generators in strict or drift-error
+	// mode return these by design when the clock steps back.
+	Errors int64
+
+	// Steps is the number of clock steps injected.
+	Steps int64
+
+	// ViolationCount is the total number of ordering violations.
+	ViolationCount int64
+
+	// Violations holds the first violations found, up to 100.
+	Violations []Violation
+}
+
+// OK reports whether the run found no ordering violations.
+func (r Report) OK() bool {
+	return r.ViolationCount == 0
+}
+
+// String returns a one-line summary.
+func (r Report) String() string {
+	return fmt.Sprintf("generated=%d errors=%d steps=%d violations=%d",
+		r.Generated, r.Errors, r.Steps, r.ViolationCount)
+}
+
+// Clock is a clock following a base clock plus an adjustable offset.
+type Clock struct {
+	base   nano64.Clock
+	offset atomic.Int64
+}
+
+// NewClock returns a Clock following base, or nano64.DefaultClock if base is nil.
+func NewClock(base nano64.Clock) *Clock {
+	if base == nil {
+		base = nano64.DefaultClock
+	}
+	return &Clock{base: base}
+}
+
+// Now returns the base clock plus the current offset. Pass c.Now as a nano64.Clock.
+func (c *Clock) Now() int64 {
+	return c.base() + c.offset.Load()
+}
+
+// Step moves the clock by ms, which may be negative, and returns the new offset.
+func (c *Clock) Step(ms int64) int64 {
+	return c.offset.Add(ms)
+}
+
+// Run generates monotonic IDs on concurrency goroutines (GOMAXPROCS if not
+// positive) for duration, stepping the generator's clock meanwhile, and
+// reports any ordering violations.
+func Run(factory Factory, duration time.Duration, concurrency int, opts ...Option) Report {
+	cfg := config{interval: 10 * time.Millisecond, maxStep: 50}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	clock := NewClock(cfg.base)
+	gen := factory(clock.Now)
+
+	var (
+		c    checker
+		stop atomic.Bool
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var prev nano64.Nano64
+			for !stop.Load() {
+				floor := c.max.Load()
+				id, err := gen.GenerateMonotonicNow()
+				if err != nil {
+					c.errors.Add(1)
+					continue
+				}
+				c.observe(w, prev, floor, id)
+				prev = id
+			}
+		}()
+	}
+
+	var steps int64
+	deadline := time.After(duration)
+	if cfg.interval > 0 && cfg.maxStep > 0 {
+		rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		if cfg.seeded {
+			rng = rand.New(rand.NewPCG(cfg.seed, cfg.seed))
+		}
+		ticker := time.NewTicker(cfg.interval)
+	loop:
+		for {
+			select {
+			case <-deadline:
+				break loop
+			case <-ticker.C:
+				step := rng.Int64N(2*cfg.maxStep+1) - cfg.maxStep
+				if off := clock.Step(step); off > 10*cfg.maxStep || off < -10*cfg.maxStep {
+					clock.Step(-step)
+				}
+				steps++
+			}
+		}
+		ticker.Stop()
+	} else {
+		<-deadline
+	}
+	stop.Store(true)
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Report{
+		Generated:      c.generated.Load(),
+		Errors:         c.errors.Load(),
+		Steps:          steps,
+		ViolationCount: c.violations.Load(),
+		Violations:     c.recorded,
+	}
+}
+
+// checker accumulates results across goroutines.
+type checker struct {
+	max        atomic.Uint64 // largest ID returned so far
+	generated  atomic.Int64
+	errors     atomic.Int64
+	violations atomic.Int64
+
+	mu       sync.Mutex
+	recorded []Violation
+}
+
+// observe checks id, received by worker after prev, against floor, the
+// largest ID any worker had received before the call began.
+func (c *checker) observe(worker int, prev nano64.Nano64, floor uint64, id nano64.Nano64) {
+	c.generated.Add(1)
+	v := id.Uint64Value()
+	switch {
+	case !prev.IsNil() && v <= prev.Uint64Value():
+		c.violate(Violation{Worker: worker, Prev: prev, Got: id})
+	case floor != 0 && v <= floor:
+		c.violate(Violation{Worker: worker, Prev: nano64.FromUint64(floor), Got: id, Global: true})
+	}
+	for {
+		cur := c.max.Load()
+		if v <= cur || c.max.CompareAndSwap(cur, v) {
+			return
+		}
+	}
+}
+
+func (c *checker) violate(v Violation) {
+	c.violations.Add(1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.recorded) < maxRecorded {
+		c.recorded = append(c.recorded, v)
+	}
+}
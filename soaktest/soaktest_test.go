@@ -0,0 +1,81 @@
+package soaktest
+
+import (
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func TestRun_Default(t *testing.T) {
+	r := Run(func(clock nano64.Clock) *nano64.Generator {
+		return nano64.NewGenerator(nano64.WithClock(clock))
+	}, 300*time.Millisecond, 4, WithSeed(1))
+
+	if !r.OK() {
+		t.Fatalf("%v: %v", r, r.Violations)
+	}
+	if r.Generated == 0 || r.Steps == 0 {
+		t.Errorf("%v: want IDs generated and clock steps injected", r)
+	}
+	if r.Errors != 0 {
+		t.Errorf("%v: clamping generator should not fail", r)
+	}
+}
+
+func TestRun_StrictCountsErrors(t *testing.T) {
+	r := Run(func(clock nano64.Clock) *nano64.Generator {
+		return nano64.NewGenerator(nano64.WithClock(clock), nano64.WithFastRNG(), nano64.WithStrictMonotonic(0))
+	}, 300*time.Millisecond, 2, WithSteps(5*time.Millisecond, 100*time.Millisecond), WithSeed(2))
+
+	if !r.OK() {
+		t.Fatalf("%v: %v", r, r.Violations)
+	}
+	if r.Errors == 0 {
+		t.Errorf("%v: strict generator should reject backward steps", r)
+	}
+}
+
+func TestRun_NoSteps(t *testing.T) {
+	r := Run(func(clock nano64.Clock) *nano64.Generator {
+		return nano64.NewGenerator(nano64.WithClock(clock))
+	}, 50*time.Millisecond, 0, WithSteps(0, 0))
+	if !r.OK() || r.Steps != 0 || r.Generated == 0 {
+		t.Errorf("%v: want IDs and no steps", r)
+	}
+}
+
+func TestChecker_DetectsViolations(t *testing.T) {
+	var c checker
+	a, b := nano64.FromUint64(10), nano64.FromUint64(20)
+
+	c.observe(0, nano64.Nil, 0, b)
+	c.observe(0, b, 0, a) // own order broken
+	c.observe(1, nano64.Nil, b.Uint64Value(), a)
+	c.observe(1, a, b.Uint64Value(), nano64.FromUint64(30))
+
+	if got := c.violations.Load(); got != 2 {
+		t.Fatalf("violations = %d, want 2", got)
+	}
+	if v := c.recorded[0]; v.Global || v.Prev != b || v.Got != a {
+		t.Errorf("recorded[0] = %v", v)
+	}
+	if v := c.recorded[1]; !v.Global || v.Worker != 1 {
+		t.Errorf("recorded[1] = %v", v)
+	}
+	if c.max.Load() != 30 {
+		t.Errorf("max = %d, want 30", c.max.Load())
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock(func() int64 { return 1000 })
+	c.Step(-5)
+	if got := c.Now(); got != 995 {
+		t.Errorf("Now() = %d, want 995", got)
+	}
+	var clock nano64.Clock = c.Now
+	if clock() != 995 {
+		t.Error("Clock.Now should satisfy nano64.Clock")
+	}
+}
@@ -0,0 +1,54 @@
+package nano64
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"unsafe"
+)
+
+// SortIDs sorts ids in ascending (time) order in place.
+// It runs pdqsort directly over the unsigned values, avoiding the interface
+// and closure overhead of sort.Slice.
+func SortIDs(ids []Nano64) {
+	if len(ids) == 0 {
+		return
+	}
+	// Nano64 is a single uint64 field, so the slice has the layout of a []uint64.
+	slices.Sort(unsafe.Slice((*uint64)(unsafe.Pointer(&ids[0])), len(ids)))
+}
+
+// IDsSorted reports whether ids are in ascending order.
+func IDsSorted(ids []Nano64) bool {
+	for i := 1; i < len(ids); i++ {
+		if ids[i].value < ids[i-1].value {
+			return false
+		}
+	}
+	return true
+}
+
+// Median returns the lower median of ids, which must already be sorted (see SortIDs).
+func Median(ids []Nano64) (Nano64, error) {
+	if len(ids) == 0 {
+		return Nano64{}, fmt.Errorf("median of empty slice")
+	}
+	return ids[(len(ids)-1)/2], nil
+}
+
+// Percentile returns the nearest-rank p-th percentile (0-100) of ids, which must
+// already be sorted (see SortIDs). Percentile(ids, 0) is the oldest ID and
+// Percentile(ids, 100) the newest.
+func Percentile(ids []Nano64, p float64) (Nano64, error) {
+	if len(ids) == 0 {
+		return Nano64{}, fmt.Errorf("percentile of empty slice")
+	}
+	if p < 0 || p > 100 || math.IsNaN(p) {
+		return Nano64{}, fmt.Errorf("percentile must be 0-100, got %v", p)
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(ids))))
+	if rank < 1 {
+		rank = 1
+	}
+	return ids[rank-1], nil
+}
@@ -0,0 +1,122 @@
+package nano64
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// defaultOrderSample is the sample size VerifyStorageOrder uses when none is given.
+const defaultOrderSample = 1000
+
+// Querier runs a query. *sql.DB, *sql.Conn and *sql.Tx satisfy it.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// VerifyStorageOrder checks that the storage engine's ORDER BY over
+// table.column agrees with Nano64's unsigned order, so that range scans and
+// keyset pagination return IDs in time order. It reads up to sample rows (1000
+// if not positive) from each end of the ascending ordering and returns an
+// error wrapping ErrOrderMismatch at the first adjacent pair out of order.
+//
+// This catches the usual pitfalls: IDs stored in a signed BIGINT, where IDs
+// with the top bit set sort before all others, and hex stored as text in mixed
+// case under a case-sensitive collation, where "a" sorts after "B". It can only report what the
+// stored rows exhibit, so run it against a table holding representative data,
+// as a startup check or with "nano64 verify-order".
+//
+// The column may hold 8-byte binary, integers or any text form Parse accepts.
+// table and column are written into the query verbatim and must be plain,
+// optionally dot-qualified identifiers.
+func VerifyStorageOrder(ctx context.Context, q Querier, table, column string, sample int) error {
+	if !isSQLIdentifier(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	if !isSQLIdentifier(column) {
+		return fmt.Errorf("invalid column name %q", column)
+	}
+	if sample <= 0 {
+		sample = defaultOrderSample
+	}
+	for _, dir := range []string{"ASC", "DESC"} {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL ORDER BY %s %s LIMIT %d",
+			column, table, column, column, dir, sample)
+		ids, err := queryOrdered(ctx, q, query)
+		if err != nil {
+			return err
+		}
+		for i := 1; i < len(ids); i++ {
+			prev, cur := ids[i-1], ids[i]
+			if dir == "DESC" {
+				prev, cur = cur, prev
+			}
+			if prev.value > cur.value {
+				return fmt.Errorf("%w: ORDER BY %s %s returned %s before %s",
+					ErrOrderMismatch, column, dir, ids[i-1].ToHex(), ids[i].ToHex())
+			}
+		}
+	}
+	return nil
+}
+
+// queryOrdered runs query and decodes its single column into IDs.
+func queryOrdered(ctx context.Context, q Querier, query string) ([]Nano64, error) {
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query order sample: %w", err)
+	}
+	defer rows.Close()
+	var ids []Nano64
+	for rows.Next() {
+		var raw any
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan order sample: %w", err)
+		}
+		id, err := decodeStored(raw)
+		if err != nil {
+			return nil, fmt.Errorf("scan order sample: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query order sample: %w", err)
+	}
+	return ids, nil
+}
+
+// decodeStored decodes a column value in any of the forms IDs are commonly
+// stored in: what Scan accepts, plus text.
+func decodeStored(raw any) (Nano64, error) {
+	switch v := raw.(type) {
+	case string:
+		return Parse(v)
+	case []byte:
+		if len(v) != 8 {
+			return Parse(string(v))
+		}
+	}
+	var id Nano64
+	err := id.Scan(raw)
+	return id, err
+}
+
+// isSQLIdentifier reports whether s is a plain identifier such as "ids" or
+// "public.ids", safe to write into a query unquoted.
+func isSQLIdentifier(s string) bool {
+	start := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && !start:
+		case c == '.' && !start && i+1 < len(s):
+			start = true
+			continue
+		default:
+			return false
+		}
+		start = false
+	}
+	return s != ""
+}
@@ -0,0 +1,59 @@
+package nano64
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// maxHexRecord bounds the length of one record read by ReadHexIDs, which
+// keeps memory use constant however large the input is.
+const maxHexRecord = 256
+
+// ReadHexIDs returns an iterator over hex IDs in r separated by sep (typically
+// '\n' or ','). Surrounding whitespace is trimmed, empty records are skipped,
+// and records are parsed with Parse.
+//
+// A record that fails to parse yields its error, prefixed with its 1-based
+// position, and iteration continues. A read error, or a record longer than
+// 256 bytes, yields the error and ends iteration.
+func ReadHexIDs(r io.Reader, sep byte) iter.Seq2[Nano64, error] {
+	return func(yield func(Nano64, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64), maxHexRecord)
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			if i := bytes.IndexByte(data, sep); i >= 0 {
+				return i + 1, data[:i], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		})
+
+		record := 0
+		for scanner.Scan() {
+			record++
+			field := bytes.TrimSpace(scanner.Bytes())
+			if len(field) == 0 {
+				continue
+			}
+			id, err := Parse(string(field))
+			if err != nil {
+				err = fmt.Errorf("record %d: %w", record, err)
+			}
+			if !yield(id, err) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				err = fmt.Errorf("record %d: longer than %d bytes", record+1, maxHexRecord)
+			}
+			yield(Nano64{}, fmt.Errorf("failed to read IDs: %w", err))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package token mints compact session tokens keyed by Nano64 subjects.
+//
+// A token carries a subject ID, an expiry and up to MaxClaims bytes of
+// application claims, sealed with AES-GCM by a nano64.EncryptedIDConfig.
+// Tokens are opaque, URL-safe strings; only holders of the key can read or
+// forge them. They are a lightweight alternative to JWTs for internal auth,
+// not a replacement for standards-based tokens exchanged with third parties.
+package token
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+// MaxClaims is the maximum number of claim bytes a token can carry.
+const MaxClaims = 256
+
+// version is the plaintext format version.
+const version = 1
+
+// headerLength is version(1) + subject(8) + expiry in Unix ms(8).
+const headerLength = 1 + 8 + 8
+
+// additionalData binds sealed tokens to this format, so a token cannot be
+// replayed as any other structure sealed with the same key.
+var additionalData = []byte("nano64/token/v1")
+
+// ErrExpired is returned by Verify for an authentic token past its expiry.
+var ErrExpired = errors.New("token expired")
+
+// Token is the decoded content of a session token.
+type Token struct {
+	Subject nano64.Nano64
+	Expiry  time.Time
+	Claims  []byte
+}
+
+// Mint seals subject, expiry and claims into a URL-safe token string.
+// Expiry is stored with millisecond precision.
+func Mint(config *nano64.EncryptedIDConfig, subject nano64.Nano64, expiry time.Time, claims []byte) (string, error) {
+	if len(claims) > MaxClaims {
+		return "", fmt.Errorf("claims must be at most %d bytes, got %d", MaxClaims, len(claims))
+	}
+
+	plaintext := make([]byte, headerLength, headerLength+len(claims))
+	plaintext[0] = version
+	binary.BigEndian.PutUint64(plaintext[1:9], subject.Uint64Value())
+	binary.BigEndian.PutUint64(plaintext[9:17], uint64(expiry.UnixMilli()))
+	plaintext = append(plaintext, claims...)
+
+	sealed, err := config.Seal(nil, plaintext, additionalData)
+	if err != nil {
+		return "", fmt.Errorf("failed to seal token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Verify authenticates and decodes a token, rejecting it if it is not before
+// its expiry at now. Expired but authentic tokens return the decoded Token
+// alongside an error wrapping ErrExpired.
+func Verify(config *nano64.EncryptedIDConfig, token string, now time.Time) (Token, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Token{}, fmt.Errorf("invalid token encoding: %w", err)
+	}
+	plaintext, err := config.Open(sealed, additionalData)
+	if err != nil {
+		return Token{}, fmt.Errorf("invalid token: %w", err)
+	}
+	if len(plaintext) < headerLength || plaintext[0] != version {
+		return Token{}, fmt.Errorf("unsupported token format")
+	}
+
+	t := Token{
+		Subject: nano64.New(binary.BigEndian.Uint64(plaintext[1:9])),
+		Expiry:  time.UnixMilli(int64(binary.BigEndian.Uint64(plaintext[9:17]))),
+	}
+	if len(plaintext) > headerLength {
+		t.Claims = plaintext[headerLength:]
+	}
+	if !now.Before(t.Expiry) {
+		return t, fmt.Errorf("%w at %s", ErrExpired, t.Expiry.UTC().Format(time.RFC3339))
+	}
+	return t, nil
+}
+
+// VerifyNow is Verify using the current time.
+func VerifyNow(config *nano64.EncryptedIDConfig, token string) (Token, error) {
+	return Verify(config, token, time.Now())
+}
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/nano64"
+)
+
+func newConfig(t *testing.T, key byte) *nano64.EncryptedIDConfig {
+	t.Helper()
+	config, err := nano64.NewEncryptedIDConfig(bytes.Repeat([]byte{key}, 32), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptedIDConfig() error = %v", err)
+	}
+	return config
+}
+
+func TestMintVerify(t *testing.T) {
+	config := newConfig(t, 1)
+	subject := nano64.New(0x199C01B66595861C)
+	now := time.UnixMilli(1_700_000_000_000)
+	expiry := now.Add(time.Hour)
+
+	tok, err := Mint(config, subject, expiry, []byte(`{"role":"admin"}`))
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+	if strings.ContainsAny(tok, "+/=") {
+		t.Errorf("Mint() = %q, want URL-safe unpadded base64", tok)
+	}
+
+	got, err := Verify(config, tok, now)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !got.Subject.Equals(subject) {
+		t.Errorf("Subject = %s, want %s", got.Subject.ToHex(), subject.ToHex())
+	}
+	if !got.Expiry.Equal(expiry) {
+		t.Errorf("Expiry = %v, want %v", got.Expiry, expiry)
+	}
+	if string(got.Claims) != `{"role":"admin"}` {
+		t.Errorf("Claims = %q", got.Claims)
+	}
+
+	noClaims, _ := Mint(config, subject, expiry, nil)
+	if got, err := Verify(config, noClaims, now); err != nil || got.Claims != nil {
+		t.Errorf("Verify(no claims) = %v, %v, want nil claims", got.Claims, err)
+	}
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	config := newConfig(t, 1)
+	now := time.UnixMilli(1_700_000_000_000)
+	tok, _ := Mint(config, nano64.New(1), now.Add(time.Minute), []byte("c"))
+
+	if _, err := Verify(newConfig(t, 2), tok, now); err == nil {
+		t.Error("Verify() with wrong key error = nil, want error")
+	}
+
+	raw, _ := base64.RawURLEncoding.DecodeString(tok)
+	raw[len(raw)-1] ^= 1
+	if _, err := Verify(config, base64.RawURLEncoding.EncodeToString(raw), now); err == nil {
+		t.Error("Verify() of tampered token error = nil, want error")
+	}
+
+	if _, err := Verify(config, "not base64!", now); err == nil {
+		t.Error("Verify() of garbage error = nil, want error")
+	}
+
+	got, err := Verify(config, tok, now.Add(time.Minute))
+	if !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() at expiry error = %v, want ErrExpired", err)
+	}
+	if !got.Subject.Equals(nano64.New(1)) {
+		t.Error("Verify() of expired token did not return decoded subject")
+	}
+
+	// An ID payload sealed by the same config is not a token.
+	enc, _ := config.Encrypt(nano64.New(1))
+	if _, err := Verify(config, base64.RawURLEncoding.EncodeToString(enc.ToEncryptedBytes()), now); err == nil {
+		t.Error("Verify() of an encrypted ID payload error = nil, want error")
+	}
+
+	if _, err := Mint(config, nano64.New(1), now, make([]byte, MaxClaims+1)); err == nil {
+		t.Error("Mint() with oversized claims error = nil, want error")
+	}
+}
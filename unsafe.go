@@ -0,0 +1,31 @@
+package nano64
+
+import "encoding/binary"
+
+// FromBytesUnsafe decodes 8 big-endian bytes without validation.
+//
+// Only use it on trusted, pre-validated input such as values read back from
+// your own storage. It ignores bytes past the eighth and panics if b is
+// shorter than 8 bytes. Prefer FromBytes everywhere else.
+func FromBytesUnsafe(b []byte) Nano64 {
+	return Nano64{value: binary.BigEndian.Uint64(b)}
+}
+
+// FromHexUnsafe decodes canonical or undashed hex without validation.
+//
+// Dashes are skipped and every other byte is treated as a hex digit in either
+// case; anything else (including a 0x prefix) yields a meaningless ID rather
+// than an error. Only use it on trusted, pre-validated input. Prefer FromHex
+// or Parse everywhere else.
+func FromHexUnsafe(s string) Nano64 {
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '-' {
+			continue
+		}
+		// Maps '0'-'9' to 0-9 and 'A'-'F'/'a'-'f' to 10-15 without branching.
+		v = v<<4 | uint64((c&0xF)+9*(c>>6))
+	}
+	return Nano64{value: v}
+}
@@ -0,0 +1,322 @@
+package nano64
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UUIDLength is the length of a UUID in bytes.
+const UUIDLength = 16
+
+// UUIDv8 returns the RFC 9562 version 8 UUID derived from the ID.
+//
+// Layout: the ID's 64 bits are stored in order around the version and variant
+// fields (48 bits, version 8, 12 bits, variant 10, 4 bits) and the remaining
+// 58 bits are zero. The mapping is reversible with FromUUIDv8 and preserves
+// time order, so derived UUIDs also sort by creation time.
+func (n Nano64) UUIDv8() [UUIDLength]byte {
+	var u [UUIDLength]byte
+	b := n.ToBytes()
+	copy(u[:6], b[:6])
+	u[6] = 0x80 | b[6]>>4
+	u[7] = b[6]<<4 | b[7]>>4
+	u[8] = 0x80 | b[7]&0x0F
+	return u
+}
+
+// UUIDString returns the derived UUIDv8 in canonical 8-4-4-4-12 lowercase form.
+func (n Nano64) UUIDString() string {
+	return formatUUID(n.UUIDv8())
+}
+
+// FromUUIDv8 extracts the ID from a UUID produced by UUIDv8.
+// Returns an error if the UUID was not derived from a Nano64.
+func FromUUIDv8(u [UUIDLength]byte) (Nano64, error) {
+	if u[6]>>4 != 8 {
+		return Nano64{}, fmt.Errorf("UUID version must be 8, got %d", u[6]>>4)
+	}
+	if u[8]&0xC0 != 0x80 {
+		return Nano64{}, fmt.Errorf("UUID variant must be RFC 9562")
+	}
+	if u[8]&0x30 != 0 || !bytes.Equal(u[9:], make([]byte, 7)) {
+		return Nano64{}, fmt.Errorf("UUID was not derived from a Nano64")
+	}
+
+	b := make([]byte, 8)
+	copy(b, u[:6])
+	b[6] = u[6]<<4 | u[7]>>4
+	b[7] = u[7]<<4 | u[8]&0x0F
+	return FromBytes(b)
+}
+
+// FromUUIDString parses a derived UUIDv8 in canonical or undashed form.
+func FromUUIDString(s string) (Nano64, error) {
+	u, err := parseUUID(s)
+	if err != nil {
+		return Nano64{}, err
+	}
+	return FromUUIDv8(u)
+}
+
+// formatUUID renders a UUID in canonical 8-4-4-4-12 lowercase form.
+func formatUUID(u [UUIDLength]byte) string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// parseUUID parses a UUID in canonical 8-4-4-4-12 or undashed 32-char form, case-insensitive.
+func parseUUID(s string) ([UUIDLength]byte, error) {
+	var u [UUIDLength]byte
+	clean := s
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("invalid UUID format: %q", s)
+		}
+		clean = strings.ReplaceAll(s, "-", "")
+	}
+	if len(clean) != 32 {
+		return u, fmt.Errorf("UUID must be 32 hex chars, got %d", len(clean))
+	}
+	if _, err := hex.Decode(u[:], []byte(clean)); err != nil {
+		return u, fmt.Errorf("invalid UUID hex: %w", err)
+	}
+	return u, nil
+}
+
+// Primary selects which representation of a DualID is authoritative.
+type Primary int
+
+const (
+	// PrimaryNano64 makes the Nano64 the primary representation.
+	PrimaryNano64 Primary = iota
+
+	// PrimaryUUID makes the derived UUIDv8 the primary representation.
+	PrimaryUUID
+)
+
+// DualID carries a Nano64 together with its derived UUIDv8 for migrations
+// where some consumers still require UUIDs.
+//
+// JSON encodes both forms; the primary form goes under "id":
+//
+//	{"id":"199C01B6659-5861C","uuid":"199c01b6-6595-8861-8c00-000000000000"}  // PrimaryNano64
+//	{"id":"199c01b6-6595-8861-8c00-000000000000","nano64":"199C01B6659-5861C"}  // PrimaryUUID
+//
+// SQL stores the primary form: 8 bytes for PrimaryNano64, 16 bytes for PrimaryUUID.
+// Decoding accepts either shape regardless of Primary.
+type DualID struct {
+	ID      Nano64
+	Primary Primary
+}
+
+// NewDualID creates a DualID with the given primary representation.
+func NewDualID(id Nano64, primary Primary) DualID {
+	return DualID{ID: id, Primary: primary}
+}
+
+// WithPrimary returns a copy of d with a different primary representation.
+func (d DualID) WithPrimary(primary Primary) DualID {
+	d.Primary = primary
+	return d
+}
+
+// UUID returns the derived UUIDv8.
+func (d DualID) UUID() [UUIDLength]byte {
+	return d.ID.UUIDv8()
+}
+
+// String returns the primary text form.
+func (d DualID) String() string {
+	if d.Primary == PrimaryUUID {
+		return d.ID.UUIDString()
+	}
+	return d.ID.ToHex()
+}
+
+// dualJSON is the wire shape of DualID.
+type dualJSON struct {
+	ID     string `json:"id"`
+	UUID   string `json:"uuid,omitempty"`
+	Nano64 string `json:"nano64,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d DualID) MarshalJSON() ([]byte, error) {
+	if d.Primary == PrimaryUUID {
+		return json.Marshal(dualJSON{ID: d.ID.UUIDString(), Nano64: d.ID.ToHex()})
+	}
+	return json.Marshal(dualJSON{ID: d.ID.ToHex(), UUID: d.ID.UUIDString()})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Detects the primary form from the "id" value and verifies the secondary form matches it.
+func (d *DualID) UnmarshalJSON(data []byte) error {
+	var w dualJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return fmt.Errorf("failed to unmarshal DualID: %w", err)
+	}
+
+	var id Nano64
+	var primary Primary
+	var err error
+	if len(w.ID) == 36 {
+		primary = PrimaryUUID
+		id, err = FromUUIDString(w.ID)
+	} else {
+		primary = PrimaryNano64
+		id, err = FromHex(w.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid DualID id: %w", err)
+	}
+
+	if w.UUID != "" {
+		other, err := FromUUIDString(w.UUID)
+		if err != nil {
+			return fmt.Errorf("invalid DualID uuid: %w", err)
+		}
+		if !other.Equals(id) {
+			return fmt.Errorf("DualID uuid %s does not match id %s", w.UUID, w.ID)
+		}
+	}
+	if w.Nano64 != "" {
+		other, err := FromHex(w.Nano64)
+		if err != nil {
+			return fmt.Errorf("invalid DualID nano64: %w", err)
+		}
+		if !other.Equals(id) {
+			return fmt.Errorf("DualID nano64 %s does not match id %s", w.Nano64, w.ID)
+		}
+	}
+
+	d.ID = id
+	d.Primary = primary
+	return nil
+}
+
+// Value implements the driver.Valuer interface, storing the primary form.
+func (d DualID) Value() (driver.Value, error) {
+	if d.Primary == PrimaryUUID {
+		u := d.ID.UUIDv8()
+		return u[:], nil
+	}
+	return d.ID.Value()
+}
+
+// Scan implements the sql.Scanner interface.
+// Accepts 8-byte Nano64 values, 16-byte or string UUIDv8 values, and integers.
+// Primary is left unchanged.
+func (d *DualID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == UUIDLength {
+			var u [UUIDLength]byte
+			copy(u[:], v)
+			id, err := FromUUIDv8(u)
+			if err != nil {
+				return fmt.Errorf("failed to scan UUID: %w", err)
+			}
+			d.ID = id
+			return nil
+		}
+	case string:
+		id, err := FromUUIDString(v)
+		if err != nil {
+			return fmt.Errorf("failed to scan UUID: %w", err)
+		}
+		d.ID = id
+		return nil
+	}
+	return d.ID.Scan(value)
+}
+
+// flexibleScanner implements ScanFlexible.
+type flexibleScanner struct {
+	dest *Nano64
+}
+
+// ScanFlexible returns a sql.Scanner that stores into dest and accepts, in
+// addition to everything Nano64.Scan accepts, the UUIDv8 form of an ID during
+// a migration window:
+//
+//   - 16-byte binary UUIDs, [16]byte values, and UUID types such as
+//     github.com/google/uuid.UUID or github.com/gofrs/uuid.UUID (anything
+//     whose MarshalBinary returns 16 bytes)
+//   - canonical or undashed UUID text, as string or []byte
+//   - hex ID text, as string or []byte
+//
+// Services reading half-migrated tables can then use one code path:
+//
+//	err := rows.Scan(nano64.ScanFlexible(&id))
+//
+// UUIDs that were not derived from a Nano64 are rejected.
+func ScanFlexible(dest *Nano64) sql.Scanner {
+	return flexibleScanner{dest: dest}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s flexibleScanner) Scan(value interface{}) error {
+	var (
+		id  Nano64
+		err error
+	)
+	switch v := value.(type) {
+	case [UUIDLength]byte:
+		id, err = FromUUIDv8(v)
+	case []byte:
+		switch len(v) {
+		case 8:
+			return s.dest.Scan(v)
+		case UUIDLength:
+			if isHexText(v) {
+				id, err = parseFlexibleText(string(v))
+				break
+			}
+			id, err = FromUUIDv8([UUIDLength]byte(v))
+		default:
+			id, err = parseFlexibleText(string(v))
+		}
+	case string:
+		id, err = parseFlexibleText(v)
+	case encoding.BinaryMarshaler:
+		var b []byte
+		if b, err = v.MarshalBinary(); err == nil {
+			if len(b) != UUIDLength {
+				return fmt.Errorf("cannot scan %d-byte %T into Nano64", len(b), value)
+			}
+			id, err = FromUUIDv8([UUIDLength]byte(b))
+		}
+	default:
+		return s.dest.Scan(value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scan Nano64: %w", err)
+	}
+	*s.dest = id
+	return nil
+}
+
+// isHexText reports whether b is all ASCII hex digits, as undashed hex ID text
+// is. A binary UUIDv8 never is: its version byte is 0x8X.
+func isHexText(b []byte) bool {
+	for _, c := range b {
+		if _, ok := hexDigit(c, true); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFlexibleText parses UUID text (32 or 36 chars) or hex ID text.
+func parseFlexibleText(s string) (Nano64, error) {
+	if len(s) == 32 || len(s) == 36 {
+		return FromUUIDString(s)
+	}
+	return Parse(s)
+}
@@ -0,0 +1,51 @@
+package nano64
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Zero-copy serialization formats such as Cap'n Proto and FlatBuffers store
+// 64-bit scalars little-endian. Store a Nano64 through the schema's uint64
+// accessor using Uint64Value and FromUint64; never copy ToBytes (big-endian)
+// into the raw field bytes, as that silently byte-swaps the ID.
+//
+// Cap'n Proto:
+//
+//	struct User {
+//	  id @0 :UInt64;  # Nano64
+//	}
+//
+// FlatBuffers:
+//
+//	table User {
+//	  id: uint64;  // Nano64
+//	}
+//
+// When reading or writing the raw little-endian field bytes directly (e.g. a
+// segment or buffer slice), use ToBytesLE, PutBytesLE and FromBytesLE.
+
+// ToBytesLE returns 8-byte little-endian encoding of the u64, matching the
+// in-buffer layout of Cap'n Proto and FlatBuffers uint64 fields.
+// Little-endian bytes do not sort by time; use ToBytes for storage keys.
+func (n Nano64) ToBytesLE() []byte {
+	return BigIntHelpers.ToBytesLE(n.value)
+}
+
+// PutBytesLE writes the 8-byte little-endian encoding into dst, which must be at least 8 bytes.
+func (n Nano64) PutBytesLE(dst []byte) error {
+	if len(dst) < 8 {
+		return fmt.Errorf("destination must be at least 8 bytes, got %d", len(dst))
+	}
+	binary.LittleEndian.PutUint64(dst, n.value)
+	return nil
+}
+
+// FromBytesLE parses from 8 little-endian bytes.
+func FromBytesLE(bytes []byte) (Nano64, error) {
+	value, err := BigIntHelpers.FromBytesLE(bytes)
+	if err != nil {
+		return Nano64{}, fmt.Errorf("failed to parse bytes: %w", err)
+	}
+	return Nano64{value: value}, nil
+}